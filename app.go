@@ -8,20 +8,22 @@ import (
 	"io"
 	"net/http"
 	"time"
-)
 
-const serviceURL = "http://localhost:9100"
+	"printbridge/pkg/config"
+)
 
 // App struct
 type App struct {
-	ctx    context.Context
-	client *http.Client
+	ctx        context.Context
+	client     *http.Client
+	serviceURL string // Base URL of the service, from the configured host and port
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	return &App{
-		client: &http.Client{Timeout: 5 * time.Second},
+		client:     config.NewClient(5 * time.Second),
+		serviceURL: config.ServiceURL(),
 	}
 }
 
@@ -50,7 +52,7 @@ type StatusResponse struct {
 
 // CheckServiceStatus checks if the PrintBridge service is running
 func (a *App) CheckServiceStatus() (bool, error) {
-	resp, err := a.client.Get(serviceURL + "/health")
+	resp, err := a.client.Get(a.serviceURL + "/health")
 	if err != nil {
 		return false, nil // Service not running
 	}
@@ -60,7 +62,7 @@ func (a *App) CheckServiceStatus() (bool, error) {
 
 // GetPrinters retrieves the list of printers from the service
 func (a *App) GetPrinters() ([]PrinterInfo, error) {
-	resp, err := a.client.Get(serviceURL + "/status")
+	resp, err := a.client.Get(a.serviceURL + "/status")
 	if err != nil {
 		return nil, fmt.Errorf("service not reachable: %v", err)
 	}
@@ -76,7 +78,7 @@ func (a *App) GetPrinters() ([]PrinterInfo, error) {
 
 // GetConnectionStatus returns whether a printer is currently connected
 func (a *App) GetConnectionStatus() (bool, error) {
-	resp, err := a.client.Get(serviceURL + "/status")
+	resp, err := a.client.Get(a.serviceURL + "/status")
 	if err != nil {
 		return false, nil
 	}
@@ -105,10 +107,10 @@ func (a *App) PrintTest(testType string) error {
 
 	switch testType {
 	case "comprehensive":
-		endpoint = serviceURL + "/test"
+		endpoint = a.serviceURL + "/test"
 		method = "GET"
 	case "simple":
-		endpoint = serviceURL + "/print"
+		endpoint = a.serviceURL + "/print"
 		method = "POST"
 		payload := map[string]interface{}{
 			"header": "SIMPLE TEST",
@@ -140,7 +142,8 @@ func (a *App) PrintTest(testType string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	// /print answers 202 Accepted when the job was queued
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("print failed: %s", string(bodyBytes))
 	}
@@ -159,7 +162,7 @@ func (a *App) SendRaw(data string) error {
 	}
 	jsonData, _ := json.Marshal(payload)
 
-	resp, err := a.client.Post(serviceURL+"/raw", "application/json", bytes.NewReader(jsonData))
+	resp, err := a.client.Post(a.serviceURL+"/raw", "application/json", bytes.NewReader(jsonData))
 	if err != nil {
 		return fmt.Errorf("request failed: %v", err)
 	}
@@ -253,7 +256,7 @@ type ConfigResponse struct {
 func (a *App) GetConfig() (ConfigResponse, error) {
 	var result ConfigResponse
 	
-	resp, err := a.client.Get(serviceURL + "/config")
+	resp, err := a.client.Get(a.serviceURL + "/config")
 	if err != nil {
 		return result, fmt.Errorf("service not reachable: %v", err)
 	}
@@ -266,6 +269,26 @@ func (a *App) GetConfig() (ConfigResponse, error) {
 	return result, nil
 }
 
+// GetConfigSchema retrieves the description of the config settings from the
+// service, for rendering the config form
+func (a *App) GetConfigSchema() ([]config.Field, error) {
+	var result struct {
+		Fields []config.Field `json:"fields"`
+	}
+
+	resp, err := a.client.Get(a.serviceURL + "/config/schema")
+	if err != nil {
+		return nil, fmt.Errorf("service not reachable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return result.Fields, nil
+}
+
 // UpdateConfig updates a configuration value via the service
 func (a *App) UpdateConfig(key string, value interface{}) error {
 	payload := map[string]interface{}{
@@ -273,7 +296,7 @@ func (a *App) UpdateConfig(key string, value interface{}) error {
 	}
 	jsonData, _ := json.Marshal(payload)
 
-	resp, err := a.client.Post(serviceURL+"/config", "application/json", bytes.NewReader(jsonData))
+	resp, err := a.client.Post(a.serviceURL+"/config", "application/json", bytes.NewReader(jsonData))
 	if err != nil {
 		return fmt.Errorf("request failed: %v", err)
 	}
@@ -1,19 +1,100 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"mime"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Timezone database for Windows, which has none built in
 
 	"printbridge/handlers"
 	"printbridge/pkg/adapter"
+	"printbridge/pkg/audit"
 	"printbridge/pkg/config"
+	"printbridge/pkg/metrics"
+	"printbridge/pkg/printer"
+	"printbridge/pkg/service"
 )
 
+// AppVersion is the version reported by /health. It can be overridden at
+// build time with: go build -ldflags "-X main.AppVersion=1.2.3"
+var AppVersion = "1.1.0"
+
 func main() {
+	handlers.Version = AppVersion
+
+	install := flag.Bool("install", false, "install the service to start automatically, then exit")
+	uninstall := flag.Bool("uninstall", false, "remove the automatically started service, then exit")
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	configFlag := flag.String("config", "", "path to config.json (default: the PrintBridge config directory)")
+	runService := flag.Bool("service", false, "run under the Windows service control manager (used by the installed service)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options]\n\nRuns the PrintBridge print service.\n\nOptions:\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "Unexpected argument: %s\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *showVersion {
+		fmt.Printf("PrintBridge %s (%s/%s)\n", AppVersion, runtime.GOOS, runtime.GOARCH)
+		return
+	}
+	if *configFlag != "" {
+		// Read by config.GetConfigPath, and inherited by anything we start
+		path, err := filepath.Abs(*configFlag)
+		if err != nil {
+			log.Fatalf("Invalid config path: %v", err)
+		}
+		os.Setenv("PRINTBRIDGE_CONFIG", path)
+	}
+	switch {
+	case *install:
+		if err := service.Install(); err != nil {
+			log.Fatalf("Failed to install service: %v", err)
+		}
+		return
+	case *uninstall:
+		if err := service.Uninstall(); err != nil {
+			log.Fatalf("Failed to uninstall service: %v", err)
+		}
+		return
+	case *runService:
+		// The service control manager discards console output
+		if f, err := os.OpenFile(filepath.Join(config.GetConfigDir(), "service.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			log.SetOutput(f)
+		}
+		if err := service.Run(serve); err != nil {
+			log.Fatalf("Service failed: %v", err)
+		}
+		return
+	}
+
+	serve(context.Background())
+}
+
+// serve runs the print service until ctx is cancelled, the process is
+// interrupted or /shutdown is called, then shuts it down gracefully.
+func serve(ctx context.Context) {
 	// Load configuration from AppData or fallback locations
 	configPath := config.GetConfigPath()
 	log.Printf("Using config: %s", configPath)
@@ -23,9 +104,211 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Create adapter based on config
-	var adpt adapter.Adapter
-	adapterType := cfg.Adapter
+	// Claim the port before opening any printer, so a second instance
+	// (e.g. started by both the tray and autostart) exits without racing
+	// the first one for the printer
+	addr := net.JoinHostPort(strings.Trim(cfg.Host, "[]"), strconv.Itoa(cfg.Port))
+	listener, err := listenSingleInstance(addr, cfg)
+	if errors.Is(err, errAlreadyRunning) {
+		log.Printf("Not starting: %v", err)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", addr, err)
+	}
+
+	// The tray uses the PID file to find and stop this process
+	if err := config.WritePIDFile(); err != nil {
+		log.Printf("Warning: Failed to write PID file: %v", err)
+	}
+	defer config.RemovePIDFile()
+
+	if cfg.AutoStart.Enabled && cfg.AutoStart.InstallOnStartup && !service.Installed() {
+		if err := service.Install(); err != nil {
+			log.Printf("Warning: Failed to install autostart service: %v", err)
+		}
+	}
+
+	templatesDir := filepath.Join(config.GetConfigDir(), "templates")
+	if _, err := printer.LoadTemplates(templatesDir); err != nil {
+		log.Printf("Warning: Failed to load custom templates: %v", err)
+	}
+	adapter.SetDiscoveryCacheTTL(time.Duration(cfg.Discovery.CacheTTLSeconds) * time.Second)
+
+	var auditLog *audit.Log
+	if cfg.AuditLog.Enabled {
+		auditLog, err = audit.Open(filepath.Join(config.GetConfigDir(), "audit.log"), int64(cfg.AuditLog.MaxSizeKB)<<10)
+		if err != nil {
+			log.Printf("Warning: Audit log disabled: %v", err)
+		}
+	}
+
+	// The top-level adapter settings are the default printer; named
+	// printers from the "printers" section are served alongside it
+	printService, adapterType := newPrintService(config.DefaultPrinterName, cfg.DefaultPrinter(), cfg, templatesDir, auditLog)
+	printers := handlers.NewPrinters(printService)
+
+	names := make([]string, 0, len(cfg.Printers))
+	for name := range cfg.Printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pc := cfg.Printers[name]
+		if err := config.ValidatePrinter(name, pc); err != nil {
+			log.Printf("Warning: Skipping printer: %v", err)
+			continue
+		}
+		svc, _ := newPrintService(name, pc, cfg, templatesDir, auditLog)
+		printers.Add(name, svc)
+	}
+
+	setAccess(cfg.AllowedOrigins, cfg.AuthToken)
+	reloader := newConfigReloader(cfg, printers)
+
+	shutdownRequested := make(chan struct{})
+	mux := http.NewServeMux()
+	registerRoutes(mux, cfg, printers, reloader, shutdownRequested)
+
+	// Start HTTP server
+	log.Printf("PrintBridge %s service starting on %s (adapter: %s)", AppVersion, addr, adapterType)
+	if !config.IsLoopbackHost(cfg.Host) {
+		log.Printf("Warning: listening on non-loopback address %s, the API is reachable from the network", cfg.Host)
+		if cfg.AuthToken == "" {
+			log.Println("Warning: auth_token is not set, anyone on the network can print and change config")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Pick up edits to the config file without a restart
+	go reloader.Run(ctx)
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-shutdownRequested:
+	}
+	stop()
+	log.Println("Shutting down, waiting for active print jobs...")
+
+	// Stop accepting requests, let in-flight ones finish, then drain the queue
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: HTTP shutdown: %v", err)
+	}
+	if err := printers.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: Print service shutdown: %v", err)
+	}
+	if auditLog != nil {
+		auditLog.Close()
+	}
+	log.Println("PrintBridge service stopped")
+}
+
+// errAlreadyRunning is returned by listenSingleInstance when another
+// PrintBridge service is running.
+var errAlreadyRunning = errors.New("another instance is running")
+
+// listenSingleInstance listens on addr unless another service instance is
+// running, per the PID file or a service answering /health on the port.
+func listenSingleInstance(addr string, cfg *config.Config) (net.Listener, error) {
+	if pid := config.RunningServicePID(); pid != 0 && pid != os.Getpid() {
+		return nil, fmt.Errorf("%w (PID %d)", errAlreadyRunning, pid)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		// A port held by PrintBridge is fine; anything else is a real error
+		client := &http.Client{Timeout: 2 * time.Second}
+		if resp, healthErr := client.Get(cfg.ServiceURL() + "/health"); healthErr == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil, fmt.Errorf("%w on %s", errAlreadyRunning, addr)
+			}
+		}
+		return nil, err
+	}
+	return listener, nil
+}
+
+// newPrintService opens the printer described by pc and sets up its print
+// service with the shared settings from cfg. It returns the adapter type
+// used, with "auto" resolved.
+func newPrintService(name string, pc config.PrinterConfig, cfg *config.Config, templatesDir string, auditLog *audit.Log) (*handlers.PrintService, string) {
+	adpt, adapterType := newAdapter(pc)
+
+	// Open the adapter
+	if err := adpt.Open(); err != nil {
+		log.Printf("Warning: Failed to open adapter for printer %s: %v", name, err)
+		// Continue anyway - some endpoints don't require printer
+	}
+
+	paperWidthMM := pc.PaperWidthMM
+	if paperWidthMM == 0 {
+		paperWidthMM = cfg.PaperWidthMM
+	}
+	printService := handlers.NewPrintServiceWithTemplates(adpt, templatesDir, paperWidthMM)
+	printService.AdapterType = adapterType
+	if console, ok := adpt.(*adapter.ConsoleAdapter); ok {
+		console.SetRenderer(printService.Printer.RenderText)
+	}
+	printService.Printer.SetImageThreshold(cfg.Image.Threshold)
+	if err := printService.Printer.SetTimezone(cfg.Timezone); err != nil {
+		log.Printf("Warning: Invalid timezone %q, using local time: %v", cfg.Timezone, err)
+	}
+	if err := printService.Printer.SetLanguage(cfg.Language); err != nil {
+		log.Printf("Warning: %v, using %q labels", err, printer.DefaultLanguage)
+	}
+	if err := printService.Printer.SetMoney(cfg.Currency, cfg.Locale); err != nil {
+		log.Printf("Warning: %v, using the default money format", err)
+	}
+	if err := printService.Printer.SetBeepVariant(cfg.Beep.Variant); err != nil {
+		log.Printf("Warning: %v, using %q", err, printer.BeepESCB)
+	}
+	if err := printService.Printer.SetDefaultFont(cfg.Text.Font); err != nil {
+		log.Printf("Warning: %v, using the printer's font", err)
+	}
+	if err := printService.Printer.SetDefaultLineSpacing(cfg.Text.LineSpacing); err != nil {
+		log.Printf("Warning: %v, using the printer's line spacing", err)
+	}
+	if err := printService.Printer.SetDensityCommand(cfg.Density.Command); err != nil {
+		log.Printf("Warning: %v, using %q", err, printer.DensityGSK)
+	}
+	printService.Printer.SetDefaultDensity(cfg.Density.Level)
+	printService.Printer.SetDefaultPrintSpeed(cfg.Density.Speed)
+	printService.Receipt = handlers.ReceiptDefaults{
+		Header: cfg.Receipt.Header,
+		Footer: cfg.Receipt.Footer,
+		Logo:   cfg.Receipt.Logo,
+		NVLogo: cfg.Receipt.NVLogo,
+	}
+
+	// Each printer has its own queue so a jammed printer doesn't hold up the others
+	queueFile := "queue.json"
+	if name != config.DefaultPrinterName {
+		queueFile = "queue-" + name + ".json"
+	}
+	printService.EnableQueue(filepath.Join(config.GetConfigDir(), queueFile), cfg.Queue.MaxAttempts)
+	printService.Audit = auditLog
+
+	log.Printf("Printer %s: adapter %s", name, adapterType)
+	return printService, adapterType
+}
+
+// newAdapter creates the adapter selected by pc, resolving "auto" to the
+// platform's default.
+func newAdapter(pc config.PrinterConfig) (adapter.Adapter, string) {
+	adapterType := pc.Adapter
 
 	// Auto-detect Windows if adapter not specified or is "auto"
 	if adapterType == "" || adapterType == "auto" {
@@ -38,70 +321,119 @@ func main() {
 
 	switch adapterType {
 	case "windows":
-		printerName := cfg.Windows.PrinterName
+		printerName := pc.Windows.PrinterName
 		if printerName == "" {
-			// Try to find the first available Windows printer
+			// Use the default Windows printer, or else the first one
 			printers, err := adapter.FindWindowsPrinters()
 			if err == nil && len(printers) > 0 {
 				printerName = printers[0].Product
+				for _, p := range printers {
+					if p.IsDefault {
+						printerName = p.Product
+						break
+					}
+				}
 				log.Printf("Auto-selected Windows printer: %s", printerName)
 			}
 		}
 		if printerName == "" {
 			log.Println("Warning: No Windows printer configured or found. Using console adapter.")
-			adpt = adapter.NewConsoleAdapter()
-		} else {
-			adpt = adapter.NewWindowsPrinter(printerName)
+			return adapter.NewConsoleAdapter(), adapterType
 		}
+		return adapter.NewWindowsPrinter(printerName), adapterType
 
 	case "usb":
-		adpt = adapter.NewUSBAdapter(cfg.USB.VendorID, cfg.USB.ProductID)
+		return adapter.NewUSBAdapter(pc.USB.VendorID, pc.USB.ProductID), adapterType
 
-	case "console":
-		adpt = adapter.NewConsoleAdapter()
+	case "network":
+		return adapter.NewNetworkAdapter(pc.Network.Address, pc.Network.Port), adapterType
 
-	default:
-		log.Printf("Unknown adapter type '%s', using console", cfg.Adapter)
-		adpt = adapter.NewConsoleAdapter()
+	case "bluetooth":
+		return adapter.NewBluetoothAdapter(pc.Bluetooth.Address, pc.Bluetooth.Channel), adapterType
+
+	case "console":
+		return adapter.NewConsoleAdapter(), adapterType
 	}
 
-	// Open the adapter
-	if err := adpt.Open(); err != nil {
-		log.Printf("Warning: Failed to open adapter: %v", err)
-		// Continue anyway - some endpoints don't require printer
+	log.Printf("Unknown adapter type '%s', using console", pc.Adapter)
+	return adapter.NewConsoleAdapter(), adapterType
+}
+
+// registerRoutes registers the HTTP handlers on mux with CORS support; all
+// but /health require auth. Printer endpoints take ?printer=<name> or a
+// "printer" JSON field.
+func registerRoutes(mux *http.ServeMux, cfg *config.Config, printers *handlers.Printers, reloader *configReloader, shutdownRequested chan struct{}) {
+	printService := printers.Default()
+	route := func(handler func(*handlers.PrintService, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+		return cors(authMiddleware(printers.Route(handler)))
+	}
+	mux.HandleFunc("/health", cors(printService.HealthHandler))
+	mux.HandleFunc("/status", route((*handlers.PrintService).StatusHandler))
+	mux.HandleFunc("/print", route((*handlers.PrintService).PrintHandler))
+	mux.HandleFunc("/print/text", route((*handlers.PrintService).TextPrintHandler))
+	mux.HandleFunc("/print/custom", route((*handlers.PrintService).CustomPrintHandler))
+	mux.HandleFunc("/print/template", route((*handlers.PrintService).TemplatePrintHandler))
+	mux.HandleFunc("/raw", route((*handlers.PrintService).RawPrintHandler))
+	mux.HandleFunc("/drawer", route((*handlers.PrintService).DrawerHandler))
+	mux.HandleFunc("/cut", route((*handlers.PrintService).CutHandler))
+	mux.HandleFunc("/beep", route((*handlers.PrintService).BeepHandler))
+	mux.HandleFunc("/logo", route((*handlers.PrintService).LogoHandler))
+	mux.HandleFunc("/logo/nv", route((*handlers.PrintService).NVLogoHandler))
+	mux.HandleFunc("/test", route((*handlers.PrintService).TestPrintHandler))
+	mux.HandleFunc("/diag", route((*handlers.PrintService).DiagHandler))
+	mux.HandleFunc("/jobs", cors(authMiddleware(printService.JobsHandler)))
+	mux.HandleFunc("/queue", route((*handlers.PrintService).QueueHandler))
+	mux.HandleFunc("/queue/", route((*handlers.PrintService).QueueHandler))
+	mux.HandleFunc("/printers", cors(authMiddleware(printers.PrintersHandler)))
+	mux.HandleFunc("/discover/network", cors(authMiddleware(printService.DiscoverNetworkHandler)))
+	mux.HandleFunc("/templates", cors(authMiddleware(printService.TemplatesHandler)))
+	mux.HandleFunc("/logos", cors(authMiddleware(printService.LogosHandler)))
+	mux.HandleFunc("/capabilities", route((*handlers.PrintService).CapabilitiesHandler))
+	if cfg.Metrics.Enabled {
+		mux.HandleFunc("/metrics", cors(authMiddleware(metrics.Handler(printers.MetricsStates))))
 	}
-	defer adpt.Close()
 
-	// Create print service with templates directory from AppData
-	templatesDir := filepath.Join(config.GetConfigDir(), "templates")
-	printService := handlers.NewPrintServiceWithTemplates(adpt, templatesDir)
-
-	// Register HTTP handlers with CORS support
-	http.HandleFunc("/health", cors(printService.HealthHandler))
-	http.HandleFunc("/status", cors(printService.StatusHandler))
-	http.HandleFunc("/print", cors(printService.PrintHandler))
-	http.HandleFunc("/print/template", cors(printService.TemplatePrintHandler))
-	http.HandleFunc("/raw", cors(printService.RawPrintHandler))
-	http.HandleFunc("/test", cors(printService.TestPrintHandler))
-	
 	// Config endpoints
-	http.HandleFunc("/config", cors(handleConfig))
+	mux.HandleFunc("/config", cors(authMiddleware(handleConfig(reloader))))
+	mux.HandleFunc("/config/schema", cors(authMiddleware(handleConfigSchema)))
+	mux.HandleFunc("/printers/windows", cors(authMiddleware(handleWindowsPrinters(reloader))))
+	mux.HandleFunc("/shutdown", cors(authMiddleware(handleShutdown(shutdownRequested))))
+}
 
-	// Start HTTP server
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	log.Printf("PrintBridge service starting on %s (adapter: %s)", addr, adapterType)
+// shutdownTimeout bounds how long shutdown waits for requests and queued jobs.
+const shutdownTimeout = 15 * time.Second
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Server failed: %v", err)
-	}
+var (
+	accessMu       sync.RWMutex
+	allowedOrigins []string // CORS origins from config; "*" allows any
+	authToken      string   // Required bearer token, empty disables auth
+)
+
+// setAccess sets the CORS origins and the bearer token requests need.
+func setAccess(origins []string, token string) {
+	accessMu.Lock()
+	defer accessMu.Unlock()
+	allowedOrigins = origins
+	authToken = token
 }
 
-// cors wraps an HTTP handler with CORS headers
+// cors wraps an HTTP handler with CORS headers. Browser requests from
+// origins not in allowed_origins are rejected with 403 Forbidden before the
+// handler runs, since CORS alone only hides the response from the page and
+// doesn't stop e.g. a form post from printing.
 func cors(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Add("Vary", "Origin")
+		if origin := r.Header.Get("Origin"); origin != "" {
+			allowed := allowedOrigin(origin)
+			if allowed == "" {
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		// Handle preflight requests
@@ -114,45 +446,275 @@ func cors(handler http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// handleConfig handles GET/POST requests for config
-func handleConfig(w http.ResponseWriter, r *http.Request) {
+// allowedOrigin returns the Access-Control-Allow-Origin value for a
+// request from origin, or "" if the origin is not allowed.
+func allowedOrigin(origin string) string {
+	accessMu.RLock()
+	defer accessMu.RUnlock()
+	for _, o := range allowedOrigins {
+		if o == "*" {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// originListed reports whether origin is named in allowed_origins, not
+// just allowed by "*".
+func originListed(origin string) bool {
+	accessMu.RLock()
+	defer accessMu.RUnlock()
+	for _, o := range allowedOrigins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// authMiddleware rejects requests without the configured bearer token
+func authMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accessMu.RLock()
+		required := authToken
+		accessMu.RUnlock()
+
+		if required != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(required)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="printbridge"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		handler(w, r)
+	}
+}
+
+// handleConfig handles GET/POST requests for config. Both reload the
+// config first, so the service runs with what they return.
+func handleConfig(reloader *configReloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			reloader.Poll()
+			cfg, err := config.Load()
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "%v"}`, err), http.StatusInternalServerError)
+				return
+			}
+
+			// Whether autostart is actually installed, which the
+			// autostart settings don't guarantee
+			installed, running, err := service.ServiceStatus()
+			autostart := map[string]interface{}{
+				"installed": installed,
+				"running":   running,
+			}
+			if err != nil {
+				autostart["error"] = err.Error()
+			}
+
+			response := map[string]interface{}{
+				"config":      cfg,
+				"config_path": config.GetConfigPath(),
+				"config_dir":  config.GetConfigDir(),
+				"autostart":   autostart,
+				// Settings the file can't change, set by environment variables
+				"env_overrides": config.EnvOverrides(),
+			}
+
+			data, _ := json.Marshal(response)
+			w.Write(data)
+
+		case http.MethodPost:
+			var updates map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "Invalid JSON: %v"}`, err), http.StatusBadRequest)
+				return
+			}
+
+			// Validate every key before saving anything
+			keys := make([]string, 0, len(updates))
+			for key := range updates {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			_, err := config.Modify(func(cfg *config.Config) error {
+				for _, key := range keys {
+					if err := config.Set(cfg, key, updates[key]); err != nil {
+						return err
+					}
+					if key == "windows.printer_name" && cfg.Windows.PrinterName != "" {
+						if err := findWindowsPrinter(cfg.Windows.PrinterName); err != nil {
+							return &config.KeyError{Key: key, Err: err}
+						}
+					}
+				}
+				return nil
+			})
+			var keyErr *config.KeyError
+			if errors.As(err, &keyErr) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error": err.Error(),
+					"key":   keyErr.Key,
+				})
+				return
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "%v"}`, err), http.StatusInternalServerError)
+				return
+			}
+
+			cfg, restart, err := reloader.Reload()
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "%v"}`, err), http.StatusInternalServerError)
+				return
+			}
+			message := "Config updated and applied."
+			if restart {
+				message = "Config updated. Restart service to apply changes."
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "ok",
+				"message": message,
+				"config":  cfg,
+			})
+
+		default:
+			http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// handleConfigSchema describes the config settings (GET), for building
+// config forms.
+func handleConfigSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	
-	switch r.Method {
-	case http.MethodGet:
-		cfg, err := config.Load()
-		if err != nil {
-			http.Error(w, fmt.Sprintf(`{"error": "%v"}`, err), http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config_version": config.CurrentVersion,
+		"fields":         config.Schema(),
+	})
+}
+
+// findWindowsPrinter checks that the spooler has a printer called name.
+func findWindowsPrinter(name string) error {
+	printers, err := adapter.FindWindowsPrinters()
+	if err != nil {
+		return fmt.Errorf("failed to list Windows printers: %v", err)
+	}
+	for _, p := range printers {
+		if p.Product == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("Windows printer %q not found", name)
+}
+
+// handleShutdown stops the service gracefully (POST), as on SIGTERM: queued
+// jobs are printed and the adapters closed. requested is closed once.
+func handleShutdown(requested chan struct{}) http.HandlerFunc {
+	var once sync.Once
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// A cross-site page must not be able to stop the service: browser
+		// requests need an explicitly allowed origin, as "*" isn't enough,
+		// and a JSON content type, which a plain form post can't send
+		if origin := r.Header.Get("Origin"); origin != "" && !originListed(origin) {
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
 			return
 		}
-		
-		response := map[string]interface{}{
-			"config":      cfg,
-			"config_path": config.GetConfigPath(),
-			"config_dir":  config.GetConfigDir(),
-		}
-		
-		data, _ := json.Marshal(response)
-		w.Write(data)
-		
-	case http.MethodPost:
-		var updates map[string]interface{}
-		if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
-			http.Error(w, fmt.Sprintf(`{"error": "Invalid JSON: %v"}`, err), http.StatusBadRequest)
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
 			return
 		}
-		
-		for key, value := range updates {
-			if err := config.Update(key, value); err != nil {
-				http.Error(w, fmt.Sprintf(`{"error": "Failed to update %s: %v"}`, key, err), http.StatusInternalServerError)
+
+		log.Printf("Shutdown requested by %s", r.RemoteAddr)
+		once.Do(func() { close(requested) })
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "ok",
+			"message": "Shutting down",
+		})
+	}
+}
+
+// handleWindowsPrinters lists the Windows spooler printers (GET), or
+// selects one by name (POST {"name": "..."}), saving it to the config and
+// switching the service to it without a restart.
+func handleWindowsPrinters(reloader *configReloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			printers, err := adapter.FindWindowsPrinters()
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "Failed to list printers: %v"}`, err), http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"printers": printers,
+			})
+
+		case http.MethodPost:
+			var req struct {
+				Name string `json:"name"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+				http.Error(w, `{"error": "Request must be {\"name\": \"<printer name>\"}"}`, http.StatusBadRequest)
 				return
 			}
+			if err := findWindowsPrinter(req.Name); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+
+			_, err := config.Modify(func(cfg *config.Config) error {
+				cfg.Adapter = "windows"
+				cfg.Windows.PrinterName = req.Name
+				return nil
+			})
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "%v"}`, err), http.StatusInternalServerError)
+				return
+			}
+
+			// Reloading switches the default printer to the new adapter
+			if _, _, err := reloader.Reload(); err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "%v"}`, err), http.StatusInternalServerError)
+				return
+			}
+			log.Printf("Switched to Windows printer: %s", req.Name)
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "ok",
+				"message": fmt.Sprintf("Printing to %s", req.Name),
+			})
+
+		default:
+			http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
 		}
-		
-		w.Write([]byte(`{"status": "ok", "message": "Config updated. Restart service to apply changes."}`))
-		
-	default:
-		http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
 	}
 }
-
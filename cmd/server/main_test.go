@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"printbridge/handlers"
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/config"
+)
+
+// testMux returns the service's routes, with the given CORS origins and
+// token.
+func testMux(t *testing.T, origins []string, token string) *http.ServeMux {
+	t.Helper()
+	t.Setenv("PRINTBRIDGE_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	setAccess(origins, token)
+	t.Cleanup(func() { setAccess(nil, "") })
+
+	cfg := config.DefaultConfig()
+	printers := handlers.NewPrinters(handlers.NewPrintService(adapter.NewMemoryAdapter()))
+	mux := http.NewServeMux()
+	registerRoutes(mux, cfg, printers, newConfigReloader(cfg, printers), make(chan struct{}))
+	return mux
+}
+
+func TestAuth(t *testing.T) {
+	mux := testMux(t, nil, "secret")
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+	}{
+		{"no token", "/config/schema", "", http.StatusUnauthorized},
+		{"wrong token", "/config/schema", "Bearer nope", http.StatusUnauthorized},
+		{"token without scheme", "/config/schema", "secret", http.StatusUnauthorized},
+		{"other scheme", "/config/schema", "Basic secret", http.StatusUnauthorized},
+		{"bearer token", "/config/schema", "Bearer secret", http.StatusOK},
+		{"printer endpoint", "/capabilities", "", http.StatusUnauthorized},
+		{"health needs no token", "/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	mux := testMux(t, []string{"https://pos.example.com"}, "")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		origin string
+		want   int
+		allow  string
+	}{
+		{"listed origin", http.MethodGet, "/config/schema", "https://pos.example.com", http.StatusOK, "https://pos.example.com"},
+		{"listed origin, other case", http.MethodGet, "/config/schema", "https://POS.example.com", http.StatusOK, "https://POS.example.com"},
+		{"no origin", http.MethodGet, "/config/schema", "", http.StatusOK, ""},
+		{"unlisted origin", http.MethodGet, "/config/schema", "https://evil.example.com", http.StatusForbidden, ""},
+		{"unlisted origin on health", http.MethodGet, "/health", "https://evil.example.com", http.StatusForbidden, ""},
+		{"unlisted origin printing", http.MethodPost, "/print/text", "https://evil.example.com", http.StatusForbidden, ""},
+		{"unlisted origin preflight", http.MethodOptions, "/print/text", "https://evil.example.com", http.StatusForbidden, ""},
+		{"listed origin preflight", http.MethodOptions, "/print/text", "https://pos.example.com", http.StatusOK, "https://pos.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allow {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allow)
+			}
+		})
+	}
+}
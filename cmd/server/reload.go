@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"printbridge/handlers"
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/config"
+)
+
+// configPollInterval is how often the config file is checked for changes.
+const configPollInterval = 2 * time.Second
+
+// configReloader applies config changes to the running service, both
+// edits to the file from outside, e.g. through the tray's Open Config, and
+// changes the service saves itself. CORS origins, the auth token and the
+// printers' adapter settings apply immediately; other settings need a
+// restart.
+type configReloader struct {
+	mu       sync.Mutex
+	watcher  *config.Watcher
+	printers *handlers.Printers
+	current  *config.Config // Config the service is running with
+}
+
+func newConfigReloader(cfg *config.Config, printers *handlers.Printers) *configReloader {
+	return &configReloader{
+		watcher:  config.NewWatcher(config.GetConfigPath()),
+		printers: printers,
+		current:  cfg,
+	}
+}
+
+// Run polls the config file for changes until ctx is cancelled.
+func (r *configReloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Poll()
+		}
+	}
+}
+
+// Poll reloads the config if the file changed since it was last read.
+func (r *configReloader) Poll() {
+	if !r.watcher.Changed() {
+		return
+	}
+	log.Printf("[Config] %s changed, reloading", config.GetConfigPath())
+	if _, restart, err := r.Reload(); err != nil {
+		log.Printf("Warning: Failed to reload config, keeping the current one: %v", err)
+	} else if restart {
+		log.Println("[Config] Some changes take effect after the service is restarted")
+	}
+}
+
+// Reload loads the config and applies what can change without a restart.
+// restart reports whether other settings changed, which only take effect
+// after the service is restarted.
+func (r *configReloader) Reload() (cfg *config.Config, restart bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Mark the file as seen before reading it, so a write in between is
+	// picked up by the next poll
+	r.watcher.Changed()
+	cfg, err = config.Load()
+	if err != nil {
+		return nil, false, err
+	}
+
+	setAccess(cfg.AllowedOrigins, cfg.AuthToken)
+	r.applyAdapter(config.DefaultPrinterName, r.current.DefaultPrinter(), cfg.DefaultPrinter())
+	for name, pc := range cfg.Printers {
+		if old, ok := r.current.Printers[name]; ok {
+			r.applyAdapter(name, old, pc)
+		}
+	}
+
+	restart = !reflect.DeepEqual(restartSettings(*r.current), restartSettings(*cfg))
+	r.current = cfg
+	return cfg, restart, nil
+}
+
+// applyAdapter switches the named printer to a new adapter if its adapter
+// settings changed from old to pc.
+func (r *configReloader) applyAdapter(name string, old, pc config.PrinterConfig) {
+	old.PaperWidthMM, pc.PaperWidthMM = 0, 0
+	if old == pc {
+		return
+	}
+	svc, ok := r.printers.Get(name)
+	if !ok {
+		return
+	}
+	if name != config.DefaultPrinterName {
+		if err := config.ValidatePrinter(name, pc); err != nil {
+			log.Printf("Warning: Not switching printer: %v", err)
+			return
+		}
+	}
+
+	adpt, adapterType := newAdapter(pc)
+	if err := adpt.Open(); err != nil {
+		log.Printf("Warning: Failed to open adapter for printer %s: %v", name, err)
+	}
+	if console, ok := adpt.(*adapter.ConsoleAdapter); ok {
+		console.SetRenderer(svc.Printer.RenderText)
+	}
+	svc.SetAdapter(adpt)
+	log.Printf("[Config] Printer %s: switched to adapter %s", name, adapterType)
+}
+
+// restartSettings returns cfg without the settings Reload applies, for
+// telling whether a change needs a restart.
+func restartSettings(cfg config.Config) config.Config {
+	cfg.AllowedOrigins = nil
+	cfg.AuthToken = ""
+	cfg.Adapter = ""
+	cfg.USB = config.USBConfig{}
+	cfg.Windows = config.WindowsConfig{}
+	cfg.Network = config.NetworkConfig{}
+	cfg.Serial = config.SerialConfig{}
+	cfg.Bluetooth = config.BluetoothConfig{}
+
+	printers := make(map[string]config.PrinterConfig, len(cfg.Printers))
+	for name, pc := range cfg.Printers {
+		printers[name] = config.PrinterConfig{PaperWidthMM: pc.PaperWidthMM}
+	}
+	cfg.Printers = printers
+	return cfg
+}
@@ -2,19 +2,23 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 	"unsafe"
 
 	"fyne.io/systray"
 	"printbridge/pkg/config"
+	"printbridge/pkg/service"
 	"printbridge/pkg/update"
 	"printbridge/tray"
 )
@@ -25,7 +29,7 @@ import (
 var AppVersion = "1.1.0"
 
 var (
-	serviceURL  = "http://localhost:9100"
+	serviceURL  string // Base URL of the service, from the configured host and port
 	servicePath string
 	configPath  string
 )
@@ -50,6 +54,7 @@ func main() {
 		// Fallback to local directory for portable mode
 		configPath = "config.json"
 	}
+	serviceURL = config.ServiceURL()
 
 	// Run systray
 	systray.Run(onReady, onExit)
@@ -57,8 +62,17 @@ func main() {
 
 var (
 	mStatus    *systray.MenuItem
+	mAutostart *systray.MenuItem
 	mStartStop *systray.MenuItem
 	mUpdate    *systray.MenuItem
+
+	// cancelDownload aborts the update download in progress, if any
+	downloadMu     sync.Mutex
+	cancelDownload context.CancelFunc
+
+	// Submenu entries from the last scan, replaced by the next one
+	usbDeviceItems  tray.ScanItems
+	winPrinterItems tray.ScanItems
 )
 
 func onReady() {
@@ -69,18 +83,31 @@ func onReady() {
 	// Status display (disabled, just for info)
 	mStatus = systray.AddMenuItem("Checking...", "Service status")
 	mStatus.Disable()
+	mAutostart = systray.AddMenuItem("Start on login: checking...", "Whether the service is installed to start automatically")
+	mAutostart.Disable()
 
 	systray.AddSeparator()
 
 	// Start/Stop toggle
 	mStartStop = systray.AddMenuItem("Start Service", "Start or stop the service")
 	mTestPrint := systray.AddMenuItem("Test Print", "Send a test receipt")
+	mCheckPrinter := systray.AddMenuItem("Check Printer", "Check the printer connection without printing")
 	
 	systray.AddSeparator()
 
 	// USB Devices submenu
 	mUSBDevices := systray.AddMenuItem("USB Devices", "Select USB printer")
 	mScanDevices := mUSBDevices.AddSubMenuItem("Scan for Devices...", "Scan for connected USB printers")
+	mShowAllDevices := mUSBDevices.AddSubMenuItemCheckbox("Show All Devices", "Include USB devices that aren't printers", false)
+
+	// Windows spooler printers submenu. The nil channel never fires on
+	// other platforms.
+	var mWinPrinters *systray.MenuItem
+	var scanWinPrintersCh chan struct{}
+	if runtime.GOOS == "windows" {
+		mWinPrinters = systray.AddMenuItem("Windows Printers", "Select Windows printer")
+		scanWinPrintersCh = mWinPrinters.AddSubMenuItem("Refresh List...", "List printers installed in Windows").ClickedCh
+	}
 
 	systray.AddSeparator()
 	
@@ -132,12 +159,25 @@ func onReady() {
 				toggleService()
 			case <-mTestPrint.ClickedCh:
 				testPrint()
+			case <-mCheckPrinter.ClickedCh:
+				checkPrinter()
 			case <-mScanDevices.ClickedCh:
-				scanAndShowDevices(mUSBDevices)
+				scanAndShowDevices(mUSBDevices, mShowAllDevices.Checked())
+			case <-mShowAllDevices.ClickedCh:
+				if mShowAllDevices.Checked() {
+					mShowAllDevices.Uncheck()
+				} else {
+					mShowAllDevices.Check()
+				}
+				scanAndShowDevices(mUSBDevices, mShowAllDevices.Checked())
+			case <-scanWinPrintersCh:
+				showWindowsPrinters(mWinPrinters)
 			case <-mOpenConfig.ClickedCh:
 				openConfig()
 			case <-mUpdate.ClickedCh:
-				checkForUpdates(true) // Show notification even if no update
+				if !cancelUpdateDownload() {
+					go checkForUpdates(true) // Show notification even if no update
+				}
 			case <-mQuit.ClickedCh:
 				systray.Quit()
 			}
@@ -171,10 +211,23 @@ func updateStatus() {
 	}
 
 	mStatus.SetTitle(statusText)
+
+	// Reflects what is installed rather than the autostart settings
+	installed, _, err := service.ServiceStatus()
+	mAutostart.SetTooltip("Whether the service is installed to start automatically")
+	switch {
+	case err != nil:
+		mAutostart.SetTitle("Start on login: unknown")
+		mAutostart.SetTooltip(err.Error())
+	case installed:
+		mAutostart.SetTitle("Start on login: enabled")
+	default:
+		mAutostart.SetTitle("Start on login: disabled")
+	}
 }
 
 func isServiceRunning() bool {
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := config.NewClient(2 * time.Second)
 	resp, err := client.Get(serviceURL + "/health")
 	if err != nil {
 		return false
@@ -183,8 +236,26 @@ func isServiceRunning() bool {
 	return resp.StatusCode == http.StatusOK
 }
 
+// servicePID returns the process ID reported by the service's /health, or
+// 0 if the service isn't answering. Comparing it with the PID file guards
+// against killing an unrelated process that reused a stale ID.
+func servicePID() int {
+	client := config.NewClient(2 * time.Second)
+	resp, err := client.Get(serviceURL + "/health")
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+
+	var health struct {
+		PID int `json:"pid"`
+	}
+	json.NewDecoder(resp.Body).Decode(&health)
+	return health.PID
+}
+
 func isPrinterConnected() bool {
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := config.NewClient(2 * time.Second)
 	resp, err := client.Get(serviceURL + "/status")
 	if err != nil {
 		return false
@@ -211,6 +282,11 @@ func toggleService() {
 }
 
 func startService() {
+	if pid := config.RunningServicePID(); pid != 0 {
+		showNotification("PrintBridge", fmt.Sprintf("Service is already running (PID %d)", pid))
+		return
+	}
+
 	// Check if service binary exists
 	if _, err := os.Stat(servicePath); os.IsNotExist(err) {
 		showNotification("PrintBridge", fmt.Sprintf("Service binary not found: %s", servicePath))
@@ -229,12 +305,61 @@ func startService() {
 		showNotification("PrintBridge Error", err.Error())
 		return
 	}
+	go cmd.Wait() // Reap the process when it exits
 
 	showNotification("PrintBridge", "Service started")
 }
 
+// serviceStopTimeout bounds how long stopService waits for a graceful
+// shutdown, which lets queued print jobs finish, before killing the service.
+const serviceStopTimeout = 20 * time.Second
+
+// stopService asks the service to shut down via POST /shutdown and waits
+// for it to exit. If the request fails or the service doesn't exit in
+// time, the process is killed by name instead.
 func stopService() {
-	// Kill process by name
+	if !requestShutdown() || !waitForServiceStop(serviceStopTimeout) {
+		killService()
+	}
+
+	showNotification("PrintBridge", "Service stopped")
+}
+
+// requestShutdown reports whether the service accepted a shutdown request.
+func requestShutdown() bool {
+	client := config.NewClient(2 * time.Second)
+	resp, err := client.Post(serviceURL+"/shutdown", "application/json", nil)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusAccepted
+}
+
+// waitForServiceStop polls /health until the service stops answering.
+func waitForServiceStop(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !isServiceRunning() {
+			return true
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return false
+}
+
+// killService kills the service process recorded in its PID file, or by
+// name if there is none.
+func killService() {
+	if pid := config.RunningServicePID(); pid != 0 {
+		// A service that still answers must report the same PID
+		if reported := servicePID(); reported == 0 || reported == pid {
+			if proc, err := os.FindProcess(pid); err == nil && proc.Kill() == nil {
+				return
+			}
+		}
+	}
+
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin", "linux":
@@ -246,8 +371,38 @@ func stopService() {
 	if cmd != nil {
 		cmd.Run()
 	}
-	
-	showNotification("PrintBridge", "Service stopped")
+}
+
+// checkPrinter shows whether the printer can be reached, using the
+// service's /diag check, which prints nothing.
+func checkPrinter() {
+	if !isServiceRunning() {
+		showNotification("PrintBridge", "Service is not running")
+		return
+	}
+
+	client := config.NewClient(10 * time.Second)
+	resp, err := client.Get(serviceURL + "/diag")
+	if err != nil {
+		showNotification("PrintBridge Error", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var diag struct {
+		OK      bool   `json:"ok"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&diag); err != nil {
+		showNotification("PrintBridge Error", fmt.Sprintf("Status: %d", resp.StatusCode))
+		return
+	}
+
+	if diag.OK {
+		showNotification("PrintBridge", diag.Message)
+	} else {
+		showNotification("PrintBridge Error", diag.Message)
+	}
 }
 
 func testPrint() {
@@ -265,7 +420,7 @@ func testPrint() {
 	}
 
 	data, _ := json.Marshal(payload)
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := config.NewClient(5 * time.Second)
 	resp, err := client.Post(serviceURL+"/print", "application/json", bytes.NewReader(data))
 	if err != nil {
 		showNotification("PrintBridge Error", err.Error())
@@ -273,7 +428,8 @@ func testPrint() {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
+	// /print answers 202 Accepted when the job was queued
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted {
 		showNotification("PrintBridge", "Test print sent!")
 	} else {
 		showNotification("PrintBridge Error", fmt.Sprintf("Status: %d", resp.StatusCode))
@@ -304,7 +460,12 @@ func showNotification(title, message string) {
 	case "linux":
 		exec.Command("notify-send", title, message).Run()
 	case "windows":
-		showWindowsMessageBox(title, message)
+		// Non-blocking toast, or a message box if toasts are unavailable
+		go func() {
+			if err := tray.ShowToast(title, message); err != nil {
+				showWindowsMessageBox(title, message)
+			}
+		}()
 	default:
 		fmt.Printf("[%s] %s\n", title, message)
 	}
@@ -325,16 +486,22 @@ type PrinterInfo struct {
 	IsPrinter    bool   `json:"is_printer"`
 }
 
-// scanAndShowDevices scans for USB printers and displays them
-func scanAndShowDevices(parent *systray.MenuItem) {
+// scanAndShowDevices scans for USB printers and displays them. Other USB
+// devices are only listed when showAll is set.
+func scanAndShowDevices(parent *systray.MenuItem, showAll bool) {
 	if !isServiceRunning() {
 		showNotification("PrintBridge", "Service must be running to scan devices")
 		return
 	}
 
 	// Get printers from service /status endpoint
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(serviceURL + "/status")
+	client := config.NewClient(5 * time.Second)
+	// An explicit scan bypasses the service's discovery cache
+	url := serviceURL + "/status?refresh=1"
+	if showAll {
+		url += "&all=1"
+	}
+	resp, err := client.Get(url)
 	if err != nil {
 		showNotification("PrintBridge Error", fmt.Sprintf("Failed to scan: %v", err))
 		return
@@ -349,6 +516,7 @@ func scanAndShowDevices(parent *systray.MenuItem) {
 		showNotification("PrintBridge Error", "Failed to parse device list")
 		return
 	}
+	usbDeviceItems.Clear()
 
 	if len(status.Printers) == 0 {
 		showNotification("PrintBridge", "No USB printers found")
@@ -360,7 +528,14 @@ func scanAndShowDevices(parent *systray.MenuItem) {
 
 	// Show notification with found devices
 	var msg string
-	for i, p := range status.Printers {
+	seen := make(map[[2]uint16]bool)
+	for _, p := range status.Printers {
+		id := [2]uint16{p.VendorID, p.ProductID}
+		if seen[id] {
+			continue // Devices are selected by VID/PID, so list each pair once
+		}
+		seen[id] = true
+
 		name := p.Product
 		if name == "" {
 			name = fmt.Sprintf("Device %04X:%04X", p.VendorID, p.ProductID)
@@ -379,30 +554,114 @@ func scanAndShowDevices(parent *systray.MenuItem) {
 			name = name + " [Not a printer]"
 		}
 
-		msg += fmt.Sprintf("%d. %s\n", i+1, name)
+		msg += fmt.Sprintf("%d. %s\n", len(seen), name)
 
 		// Add submenu item for each device
-		item := parent.AddSubMenuItem(name, fmt.Sprintf("Select %s", name))
+		vid, pid, isPrinter := p.VendorID, p.ProductID, p.IsPrinter
+		item := usbDeviceItems.Add(parent, name, fmt.Sprintf("Select %s", name), func() {
+			if isPrinter {
+				selectDevice(vid, pid)
+			}
+		})
 
 		// Disable non-printer devices
 		if !p.IsPrinter {
 			item.Disable()
 		}
-
-		// Capture values for closure
-		vid, pid, isPrinter := p.VendorID, p.ProductID, p.IsPrinter
-		go func() {
-			for range item.ClickedCh {
-				if isPrinter {
-					selectDevice(vid, pid)
-				}
-			}
-		}()
 	}
 
+	// Handlers of earlier scans have exited, so this stays flat across rescans
+	log.Printf("[Tray] Listed %d USB devices, %d goroutines running", usbDeviceItems.Len(), runtime.NumGoroutine())
 	showNotification("PrintBridge - USB Devices Found", msg)
 }
 
+// WindowsPrinterInfo is a spooler printer as listed by /printers/windows.
+type WindowsPrinterInfo struct {
+	Product   string `json:"product"`
+	IsDefault bool   `json:"is_default"`
+	Offline   bool   `json:"offline"`
+}
+
+// showWindowsPrinters lists the Windows spooler printers in the submenu
+func showWindowsPrinters(parent *systray.MenuItem) {
+	if !isServiceRunning() {
+		showNotification("PrintBridge", "Service must be running to list printers")
+		return
+	}
+
+	client := config.NewClient(5 * time.Second)
+	resp, err := client.Get(serviceURL + "/printers/windows")
+	if err != nil {
+		showNotification("PrintBridge Error", fmt.Sprintf("Failed to list printers: %v", err))
+		return
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Printers []WindowsPrinterInfo `json:"printers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		showNotification("PrintBridge Error", "Failed to parse printer list")
+		return
+	}
+	winPrinterItems.Clear()
+
+	if len(list.Printers) == 0 {
+		showNotification("PrintBridge", "No Windows printers found")
+		return
+	}
+
+	current := ""
+	if cfg, err := config.Load(); err == nil {
+		current = cfg.Windows.PrinterName
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range list.Printers {
+		if seen[p.Product] {
+			continue
+		}
+		seen[p.Product] = true
+
+		name := p.Product
+		if p.IsDefault {
+			name += " (default)"
+		}
+		if p.Offline {
+			name += " [Offline]"
+		}
+		if p.Product == current {
+			name = "✓ " + name
+		}
+
+		printerName := p.Product
+		item := winPrinterItems.Add(parent, name, fmt.Sprintf("Print to %s", p.Product), func() {
+			selectWindowsPrinter(printerName)
+		})
+		if p.Offline {
+			item.Disable()
+		}
+	}
+}
+
+// selectWindowsPrinter makes the service print to the named spooler printer
+func selectWindowsPrinter(name string) {
+	data, _ := json.Marshal(map[string]string{"name": name})
+	client := config.NewClient(5 * time.Second)
+	resp, err := client.Post(serviceURL+"/printers/windows", "application/json", bytes.NewReader(data))
+	if err != nil {
+		showNotification("PrintBridge Error", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		showNotification("PrintBridge Error", fmt.Sprintf("Failed to select printer (status %d)", resp.StatusCode))
+		return
+	}
+	showNotification("PrintBridge", fmt.Sprintf("Now printing to %s", name))
+}
+
 // loadCurrentDevice loads the current VID/PID from config
 func loadCurrentDevice() {
 	data, err := os.ReadFile(configPath)
@@ -458,7 +717,7 @@ func selectDevice(vendorID, productID uint16) {
 		return
 	}
 
-	if err := os.WriteFile(configPath, newData, 0644); err != nil {
+	if err := config.WriteFile(configPath, newData); err != nil {
 		showNotification("PrintBridge Error", fmt.Sprintf("Failed to save config: %v", err))
 		return
 	}
@@ -523,7 +782,11 @@ func showWindowsYesNoBox(title, message string) bool {
 func checkForUpdates(showIfNoUpdate bool) {
 	mUpdate.SetTitle("Checking for Updates...")
 
-	info, err := update.CheckForUpdates(AppVersion)
+	channel := update.ChannelStable
+	if cfg, err := config.Load(); err == nil && cfg.Update.Channel != "" {
+		channel = cfg.Update.Channel
+	}
+	info, err := update.CheckForUpdatesChannel(AppVersion, channel)
 	
 	mUpdate.SetTitle("Check for Updates")
 
@@ -545,10 +808,16 @@ func checkForUpdates(showIfNoUpdate bool) {
 	msg := fmt.Sprintf("New version available: v%s\n\nYou have: v%s\n\nWould you like to update now?", 
 		info.LatestVersion, info.CurrentVersion)
 
-	if runtime.GOOS == "windows" {
+	if runtime.GOOS == "windows" && !showIfNoUpdate {
+		// Background check: don't interrupt with a dialog
+		showNotification("PrintBridge Update Available",
+			fmt.Sprintf("Version %s is available. Use \"Check for Updates\" in the tray menu to install it.", info.LatestVersion))
+	} else if runtime.GOOS == "windows" {
 		if showWindowsYesNoBox("PrintBridge Update Available", msg) {
 			installUpdate(info)
 		}
+	} else if info.AssetName != "" {
+		showNotification("PrintBridge Update", fmt.Sprintf("Version %s available! Download %s from: %s", info.LatestVersion, info.AssetName, info.ReleaseURL))
 	} else {
 		showNotification("PrintBridge Update", fmt.Sprintf("Version %s available! Visit: %s", info.LatestVersion, info.ReleaseURL))
 	}
@@ -566,12 +835,36 @@ func installUpdate(info *update.UpdateInfo) {
 	}
 
 	showNotification("PrintBridge", "Downloading update...")
-	mUpdate.SetTitle("Downloading update...")
+	mUpdate.SetTitle("Downloading update... (click to cancel)")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	downloadMu.Lock()
+	cancelDownload = cancel
+	downloadMu.Unlock()
+	defer func() {
+		downloadMu.Lock()
+		cancelDownload = nil
+		downloadMu.Unlock()
+		cancel()
+	}()
+
+	// Show progress in the menu
+	progressCh := make(chan update.DownloadProgress)
+	go func() {
+		for p := range progressCh {
+			mUpdate.SetTitle(fmt.Sprintf("Downloading update... %.0f%% (click to cancel)", p.Percent))
+		}
+	}()
 
 	// Download the installer
-	installerPath, err := update.DownloadInstaller(info.DownloadURL)
+	installerPath, err := update.DownloadUpdate(ctx, info, progressCh)
+	close(progressCh)
 	if err != nil {
-		showNotification("PrintBridge Update Error", fmt.Sprintf("Download failed: %v", err))
+		if ctx.Err() != nil {
+			showNotification("PrintBridge", "Update download cancelled.")
+		} else {
+			showNotification("PrintBridge Update Error", fmt.Sprintf("Download failed: %v", err))
+		}
 		mUpdate.SetTitle("Check for Updates")
 		return
 	}
@@ -606,6 +899,20 @@ func installUpdate(info *update.UpdateInfo) {
 	systray.Quit()
 }
 
+// cancelUpdateDownload aborts a running update download and reports
+// whether there was one.
+func cancelUpdateDownload() bool {
+	downloadMu.Lock()
+	defer downloadMu.Unlock()
+
+	if cancelDownload == nil {
+		return false
+	}
+	cancelDownload()
+	cancelDownload = nil
+	return true
+}
+
 // shellExecuteRunAs launches a program with admin privileges using ShellExecuteW
 func shellExecuteRunAs(path string, args string) error {
 	shell32 := syscall.NewLazyDLL("shell32.dll")
@@ -7,7 +7,9 @@ require (
 	github.com/google/gousb v1.1.3
 	github.com/wailsapp/wails/v2 v2.11.0
 	golang.org/x/image v0.35.0
+	golang.org/x/net v0.35.0
 	golang.org/x/sys v0.40.0
+	golang.org/x/text v0.33.0
 )
 
 require (
@@ -35,8 +37,6 @@ require (
 	github.com/wailsapp/go-webview2 v1.0.22 // indirect
 	github.com/wailsapp/mimetype v1.4.1 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
-	golang.org/x/net v0.35.0 // indirect
-	golang.org/x/text v0.33.0 // indirect
 )
 
 // replace github.com/wailsapp/wails/v2 v2.11.0 => C:\Users\zeixna\go\pkg\mod
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/printer"
+)
+
+// Capabilities describes what this build of the service can print, so a
+// frontend can hide options the service doesn't support.
+type Capabilities struct {
+	Adapters     []string  `json:"adapters"`      // Adapter types compiled into this build
+	Barcodes     []string  `json:"barcodes"`      // 1D symbologies for barcode types
+	Codes2D      []string  `json:"codes_2d"`      // 2D symbologies
+	QRModels     []int     `json:"qr_models"`     // Supported QR code models
+	Fonts        []string  `json:"fonts"`         // Printer fonts
+	Paper        PaperInfo `json:"paper"`         // Configured paper
+	Images       bool      `json:"images"`        // Raster image printing
+	ImageFormats []string  `json:"image_formats"` // Accepted image uploads
+}
+
+// PaperInfo describes the configured paper width.
+type PaperInfo struct {
+	WidthMM int `json:"width_mm"` // 58 or 80
+	Chars   int `json:"chars"`    // Font A characters per line
+	Dots    int `json:"dots"`     // Printable width in dots
+}
+
+// CapabilitiesHandler responds with the adapters, symbologies, fonts and
+// paper width supported by this build and printer.
+func (s *PrintService) CapabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	chars, dots := s.Printer.PaperWidth(), s.Printer.PaperDots()
+	s.mu.Unlock()
+
+	widthMM := 80
+	if chars <= printer.PaperWidthChars(58) {
+		widthMM = 58
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Capabilities{
+		Adapters: adapter.Compiled(),
+		Barcodes: printer.BarcodeTypes,
+		Codes2D:  []string{"QR", "PDF417", "DataMatrix"},
+		QRModels: []int{1, 2},
+		Fonts:    []string{"a", "b", "c"},
+		Paper: PaperInfo{
+			WidthMM: widthMM,
+			Chars:   chars,
+			Dots:    dots,
+		},
+		Images:       true,
+		ImageFormats: []string{"png", "jpeg", "gif", "bmp"},
+	})
+}
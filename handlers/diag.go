@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"printbridge/pkg/printer"
+)
+
+// Diagnostics reports whether the printer can be reached, as a lighter
+// check than the full test receipt of /test.
+type Diagnostics struct {
+	OK          bool            `json:"ok"`
+	Message     string          `json:"message"`
+	Printer     string          `json:"printer,omitempty"`
+	Adapter     string          `json:"adapter"`              // Configured adapter type
+	Open        bool            `json:"open"`                 // Adapter connected, after trying to open it
+	OpenError   string          `json:"open_error,omitempty"` // Why the adapter couldn't be opened
+	Status      *printer.Status `json:"status"`               // Real-time status; null if the adapter can't report it
+	Printed     bool            `json:"printed"`              // POST printed the diagnostic line
+	LastError   string          `json:"last_error,omitempty"` // Error of the latest failed print job
+	LastErrorAt *time.Time      `json:"last_error_at,omitempty"`
+	LastPrintAt *time.Time      `json:"last_print_at,omitempty"` // Latest successful print job
+}
+
+// DiagHandler checks the connection to the printer without printing (GET),
+// or prints a single line and cuts (POST). It answers 503 Service
+// Unavailable when the check fails.
+func (s *PrintService) DiagHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var printErr error
+	if r.Method == http.MethodPost {
+		printErr = s.doPrint("/diag", "", func(p *printer.Printer) error {
+			return p.Init().
+				Println("PrintBridge diagnostic " + time.Now().Format("2006-01-02 15:04:05")).
+				Cut(true).
+				Flush()
+		})
+	}
+
+	s.mu.Lock()
+	diag := Diagnostics{
+		Printer: s.Name,
+		Adapter: s.AdapterType,
+		Open:    s.Adapter.IsOpen(),
+		Printed: r.Method == http.MethodPost && printErr == nil,
+	}
+	if !diag.Open {
+		if err := s.Adapter.Open(); err != nil {
+			diag.OpenError = err.Error()
+		} else {
+			diag.Open = true
+		}
+	}
+	var statusErr error
+	if diag.Open {
+		ctx, cancel := context.WithTimeout(r.Context(), statusQueryTimeout)
+		st, err := s.Printer.QueryStatusContext(ctx)
+		cancel()
+		if err == nil {
+			diag.Status = &st
+		} else if !errors.Is(err, printer.ErrStatusUnsupported) {
+			statusErr = err
+		}
+	}
+	if s.lastErr != nil {
+		lastErrAt := s.lastErrAt
+		diag.LastError, diag.LastErrorAt = s.lastErr.Error(), &lastErrAt
+	}
+	if !s.lastOKAt.IsZero() {
+		lastOKAt := s.lastOKAt
+		diag.LastPrintAt = &lastOKAt
+	}
+	s.mu.Unlock()
+
+	switch {
+	case !diag.Open:
+		diag.Message = "Printer not connected: " + diag.OpenError
+	case statusErr != nil:
+		diag.Message = fmt.Sprintf("Printer not responding: %v", statusErr)
+	case diag.Status != nil && !diag.Status.Online:
+		diag.Message = "Printer is offline"
+	case printErr != nil:
+		diag.Message = fmt.Sprintf("Print failed: %v", printErr)
+	default:
+		diag.OK = true
+		diag.Message = "Connectivity OK"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !diag.OK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(diag)
+}
@@ -1,21 +1,55 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"printbridge/pkg/adapter"
+	"printbridge/pkg/audit"
+	"printbridge/pkg/metrics"
 	"printbridge/pkg/printer"
+	"printbridge/pkg/queue"
 )
 
 // PrintService holds the printer and adapter for HTTP handlers.
 type PrintService struct {
+	Name         string // Printer name when routed through Printers
 	Adapter      adapter.Adapter
+	AdapterType  string // Configured adapter type, e.g. "usb"; reported by /health
 	Printer      *printer.Printer
 	TemplatesDir string
+
+	// Queue, when set, makes /print and /print/template asynchronous.
+	Queue *queue.Queue
+
+	// Audit, when set, records every print job.
+	Audit *audit.Log
+
+	// Receipt supplies the store branding used by /print when a request
+	// has no header or footer of its own.
+	Receipt ReceiptDefaults
+
+	mu       sync.Mutex // Serializes access to Printer
+	printers *Printers  // Other printers, for orders routed by section
+
+	// Outcome of the latest print jobs, reported by /diag; guarded by mu
+	lastErr   error
+	lastErrAt time.Time
+	lastOKAt  time.Time
 }
 
 // NewPrintService creates a new print service.
@@ -27,41 +61,382 @@ func NewPrintService(a adapter.Adapter) *PrintService {
 	}
 }
 
-// NewPrintServiceWithTemplates creates a print service with custom templates path.
-func NewPrintServiceWithTemplates(a adapter.Adapter, templatesDir string) *PrintService {
+// NewPrintServiceWithTemplates creates a print service with custom templates path
+// and paper width in millimeters (58 or 80; 0 uses the 80mm default).
+func NewPrintServiceWithTemplates(a adapter.Adapter, templatesDir string, paperWidthMM int) *PrintService {
 	return &PrintService{
 		Adapter:      a,
-		Printer:      printer.New(a),
+		Printer:      printer.NewWithWidth(a, printer.PaperWidthChars(paperWidthMM)),
 		TemplatesDir: templatesDir,
 	}
 }
 
-// HealthHandler responds with service health status.
-func (s *PrintService) HealthHandler(w http.ResponseWriter, r *http.Request) {
+// EnableQueue starts an asynchronous job queue that persists pending jobs
+// to path. Once enabled, /print and /print/template return 202 with a job ID.
+// Failed jobs are retried with backoff up to maxAttempts times.
+func (s *PrintService) EnableQueue(path string, maxAttempts int) {
+	s.Queue = queue.New(path, s.runJob)
+	s.Queue.SetMaxAttempts(maxAttempts)
+	s.Queue.Start()
+}
+
+// SetAdapter switches printing to a, closing the previous adapter. Printer
+// settings such as paper width, encoding and language are kept.
+func (s *PrintService) SetAdapter(a adapter.Adapter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Adapter.Close()
+	s.Adapter = a
+	s.Printer = s.Printer.WithAdapter(a)
+}
+
+// Shutdown stops the job queue, letting due jobs finish printing, and
+// closes the adapter. Jobs still waiting stay persisted for the next start.
+// The audit log may be shared between printers and is left to the caller.
+func (s *PrintService) Shutdown(ctx context.Context) error {
+	var err error
+	if s.Queue != nil {
+		err = s.Queue.Stop(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closeErr := s.Adapter.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Job kinds
+const (
+	jobReceipt  = "receipt"
+	jobTemplate = "template"
+	jobText     = "text"
+	jobCustom   = "custom"
+
+	jobTemplateSections = "template_sections" // Part of a template order routed to this printer
+)
+
+// jobEndpoints maps job kinds to the endpoint they were submitted to.
+var jobEndpoints = map[string]string{
+	jobReceipt:  "/print",
+	jobTemplate: "/print/template",
+	jobText:     "/print/text",
+	jobCustom:   "/print/custom",
+
+	jobTemplateSections: "/print/template",
+}
+
+// runJob prints a queued job.
+func (s *PrintService) runJob(job *queue.Job) error {
+	switch job.Kind {
+	case jobReceipt:
+		var req PrintRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return queue.Permanent(fmt.Errorf("invalid receipt payload: %w", err))
+		}
+		return s.doPrint(jobEndpoints[job.Kind], job.ID, func(p *printer.Printer) error {
+			return s.printReceipt(p, req)
+		})
+	case jobText:
+		var req TextPrintRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return queue.Permanent(fmt.Errorf("invalid text payload: %w", err))
+		}
+		return s.doPrint(jobEndpoints[job.Kind], job.ID, func(p *printer.Printer) error {
+			return s.printText(p, req)
+		})
+	case jobCustom:
+		var req CustomPrintRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return queue.Permanent(fmt.Errorf("invalid custom payload: %w", err))
+		}
+		tmpl, err := printer.LoadReceiptTemplate(s.TemplatesDir, req.Template)
+		if err != nil {
+			return queue.Permanent(err)
+		}
+		return s.doPrint(jobEndpoints[job.Kind], job.ID, func(p *printer.Printer) error {
+			return s.printCustom(p, tmpl, req.Data)
+		})
+	case jobTemplate:
+		order, err := printer.ParseTemplateOrder(job.Payload)
+		if err != nil {
+			return queue.Permanent(err)
+		}
+		return s.doPrint(jobEndpoints[job.Kind], job.ID, func(p *printer.Printer) error {
+			return p.PrintTemplateOrder(*order, s.TemplatesDir)
+		})
+	case jobTemplateSections:
+		var req sectionsJob
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return queue.Permanent(fmt.Errorf("invalid template sections payload: %w", err))
+		}
+		order, err := printer.ParseTemplateOrder(req.Order)
+		if err != nil {
+			return queue.Permanent(err)
+		}
+		return s.doPrint(jobEndpoints[job.Kind], job.ID, func(p *printer.Printer) error {
+			return p.PrintOrderSections(*order, req.Title, req.Sections)
+		})
+	}
+	return queue.Permanent(fmt.Errorf("unknown job kind: %s", job.Kind))
+}
+
+// doPrint runs fn with exclusive access to the printer and records the
+// outcome in the audit log, if enabled.
+func (s *PrintService) doPrint(endpoint, jobID string, fn func(p *printer.Printer) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := s.Printer.BytesWritten()
+	err := fn(s.Printer)
+	s.recordJob(endpoint, jobID, s.Printer.BytesWritten()-start, err)
+	return err
+}
+
+// recordJob counts the job in the metrics and writes an audit log entry.
+// Logging failures don't fail the job. s.mu must be held.
+func (s *PrintService) recordJob(endpoint, jobID string, bytes int, err error) {
+	metrics.RecordPrint(endpoint, err)
+	if err != nil {
+		s.lastErr, s.lastErrAt = err, time.Now()
+	} else {
+		s.lastOKAt = time.Now()
+	}
+	if s.Audit == nil {
+		return
+	}
+
+	entry := audit.Entry{
+		Printer:  s.Name,
+		Endpoint: endpoint,
+		JobID:    jobID,
+		Bytes:    bytes,
+		Success:  err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if logErr := s.Audit.Record(entry); logErr != nil {
+		log.Printf("[Audit] Failed to record job: %v", logErr)
+	}
+}
+
+// JobsHandler returns the latest audit log entries (GET /jobs?limit=50).
+func (s *PrintService) JobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Audit == nil {
+		http.Error(w, "Audit log not enabled", http.StatusNotFound)
+		return
+	}
+
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	entries, err := s.Audit.Recent(limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read audit log: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"jobs": entries,
+	})
+}
+
+// enqueue adds a job to the queue and responds with 202 Accepted.
+func (s *PrintService) enqueue(w http.ResponseWriter, kind string, payload []byte) {
+	job, err := s.Queue.Enqueue(kind, payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to queue job: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]string{
-		"status": "ok",
+		"status": job.Status,
+		"job_id": job.ID,
+	})
+}
+
+// QueueHandler lists jobs (GET /queue) or returns a single job (GET /queue/{id}).
+func (s *PrintService) QueueHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Queue == nil {
+		http.Error(w, "Job queue not enabled", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/queue"), "/")
+	if id == "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jobs": s.Queue.List(),
+		})
+		return
+	}
+
+	job, ok := s.Queue.Get(id)
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(job)
+}
+
+// DiscoverNetworkHandler scans a subnet for printers listening on port 9100.
+// The subnet is given by the "cidr" query parameter and defaults to the
+// machine's local networks; "timeout_ms" sets the per-host dial timeout.
+func (s *PrintService) DiscoverNetworkHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	timeout := 500 * time.Millisecond
+	if v := r.URL.Query().Get("timeout_ms"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 || ms > 10000 {
+			http.Error(w, "timeout_ms must be between 1 and 10000", http.StatusBadRequest)
+			return
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	subnets := adapter.LocalSubnets()
+	if cidr := r.URL.Query().Get("cidr"); cidr != "" {
+		subnets = []string{cidr}
+	}
+	if len(subnets) == 0 {
+		http.Error(w, "No local network found, specify cidr", http.StatusBadRequest)
+		return
+	}
+
+	printers := []adapter.PrinterInfo{}
+	for _, cidr := range subnets {
+		found, err := adapter.ScanNetworkPrinters(cidr, timeout)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		printers = append(printers, found...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subnets":  subnets,
+		"printers": printers,
 	})
 }
 
+// Version is the service version reported by /health; main sets it.
+var Version = "dev"
+
+// startTime is when the service started, for the uptime in /health.
+var startTime = time.Now()
+
+// HealthResponse is returned by /health.
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+	Adapter string `json:"adapter"` // Adapter type of the default printer
+	PID     int    `json:"pid"`     // Process ID of the service
+	Go      string `json:"go"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	Uptime  int64  `json:"uptime_seconds"`
+}
+
+// HealthHandler responds with service health status, the build and the
+// adapter in use.
+func (s *PrintService) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HealthResponse{
+		Status:  "ok",
+		Version: Version,
+		Adapter: s.AdapterType,
+		PID:     os.Getpid(),
+		Go:      runtime.Version(),
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		Uptime:  int64(time.Since(startTime).Seconds()),
+	})
+}
+
+// statusDiscoveryTimeout bounds the printer scan done for /status.
+const statusDiscoveryTimeout = 5 * time.Second
+
+// statusQueryTimeout bounds the real-time status query done for /status
+// and /diag.
+const statusQueryTimeout = 2 * time.Second
+
 // StatusHandler responds with printer connection status.
 func (s *PrintService) StatusHandler(w http.ResponseWriter, r *http.Request) {
-	connected := s.Adapter.IsOpen()
+	// Real-time status is only available on adapters that can read back;
+	// report null for the others
+	status := map[string]interface{}{
+		"service":    "running",
+		"online":     nil,
+		"paper_out":  nil,
+		"cover_open": nil,
+		"error":      nil,
+	}
 
-	// Try to connect if not already connected
+	// The adapter is opened and queried under the lock, so it can't be
+	// swapped out by SetAdapter in between
+	queryCtx, cancelQuery := context.WithTimeout(r.Context(), statusQueryTimeout)
+	s.mu.Lock()
+	connected := s.Adapter.IsOpen()
 	if !connected {
+		// Try to connect if not already connected
 		if err := s.Adapter.Open(); err == nil {
 			connected = true
 		}
 	}
-
-	status := map[string]interface{}{
-		"connected": connected,
-		"service":   "running",
+	if connected {
+		if st, err := s.Printer.QueryStatusContext(queryCtx); err == nil {
+			status["online"] = st.Online
+			status["paper_out"] = !st.PaperPresent
+			status["cover_open"] = st.CoverOpen
+			status["error"] = st.Error
+		}
 	}
-
-	// Add USB printer info if available
-	if printers, err := adapter.FindPrinters(); err == nil && len(printers) > 0 {
+	s.mu.Unlock()
+	cancelQuery()
+	status["connected"] = connected
+
+	// Add printer info if available; ?all=1 includes non-printer USB devices
+	// and ?refresh=1 rescans instead of using recent results. A slow scan is
+	// cut short and whatever was found is reported as partial.
+	all, _ := strconv.ParseBool(r.URL.Query().Get("all"))
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+	ctx, cancel := context.WithTimeout(r.Context(), statusDiscoveryTimeout)
+	defer cancel()
+	printers, err := adapter.CachedPrinters(ctx, !all, refresh)
+	if errors.Is(err, adapter.ErrDiscoveryIncomplete) {
+		status["printers_partial"] = true
+	}
+	if (err == nil || errors.Is(err, adapter.ErrDiscoveryIncomplete)) && len(printers) > 0 {
 		status["printers"] = printers
 	}
 
@@ -76,12 +451,21 @@ type ReceiptItem struct {
 	Price    float64 `json:"price"`
 }
 
+// ReceiptDefaults holds the configured receipt header, footer and logo.
+type ReceiptDefaults struct {
+	Header []string // Lines printed centered at the top, the first in bold
+	Footer []string // Lines printed centered at the bottom
+	Logo   string   // Image path, absolute or relative to the templates directory
+	NVLogo int      // Index of a logo in the printer's NV memory, used instead of Logo; 0 disables
+}
+
 // PrintRequest represents a print job request.
 type PrintRequest struct {
 	Header string        `json:"header"`
 	Items  []ReceiptItem `json:"items"`
 	Total  float64       `json:"total"`
 	Footer string        `json:"footer"`
+	Cut    string        `json:"cut"` // full (default), partial or none
 }
 
 // PrintHandler handles receipt printing.
@@ -91,35 +475,93 @@ func (s *PrintService) PrintHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	var req PrintRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
+	if !printer.ValidCutMode(req.Cut) {
+		http.Error(w, "cut must be full, partial or none", http.StatusBadRequest)
+		return
+	}
 
-	p := s.Printer
+	if isPreview(r) {
+		s.preview(w, func(p *printer.Printer) error {
+			return s.printReceipt(p, req)
+		})
+		return
+	}
+
+	if s.Queue != nil {
+		s.enqueue(w, jobReceipt, body)
+		return
+	}
+
+	err = s.doPrint("/print", "", func(p *printer.Printer) error {
+		return s.printReceipt(p, req)
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Print failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Receipt printed",
+	})
+}
+
+// printReceipt builds and prints a simple receipt.
+func (s *PrintService) printReceipt(p *printer.Printer, req PrintRequest) error {
 
 	// Build receipt
 	p.Init().
-		Align("center").
-		Bold(true).
-		Println(req.Header).
-		Bold(false).
-		NewLine().
+		Align("center")
+
+	if s.Receipt.NVLogo > 0 {
+		p.PrintNVLogo(s.Receipt.NVLogo, printer.RASTER_NORMAL).NewLine()
+	} else if s.Receipt.Logo != "" {
+		s.printLogo(p, s.Receipt.Logo)
+	}
+
+	if req.Header != "" {
+		p.Bold(true).
+			Println(req.Header).
+			Bold(false)
+	} else {
+		for i, line := range s.Receipt.Header {
+			p.Bold(i == 0).
+				PrintlnWrapped(line)
+		}
+		p.Bold(false)
+	}
+
+	p.NewLine().
 		Align("left").
 		DrawLine("-")
 
 	// Print items
+	money := p.Money(printer.DefaultReceiptMoney)
 	for _, item := range req.Items {
-		line := fmt.Sprintf("%-20s x%d  $%.2f", truncate(item.Name, 20), item.Quantity, item.Price)
-		p.Println(line)
+		p.Columns(
+			printer.Column{Text: item.Name},
+			printer.Column{Text: fmt.Sprintf("x%d", item.Quantity), Width: 5, Align: "right"},
+			printer.Column{Text: money.Format(item.Price), Width: 11, Align: "right"},
+		)
 	}
 
 	// Print total
 	p.DrawLine("-").
 		Align("right").
 		Bold(true).
-		Println(fmt.Sprintf("TOTAL: $%.2f", req.Total)).
+		Println("TOTAL: " + money.Format(req.Total)).
 		Bold(false).
 		NewLine()
 
@@ -127,12 +569,73 @@ func (s *PrintService) PrintHandler(w http.ResponseWriter, r *http.Request) {
 	if req.Footer != "" {
 		p.Align("center").
 			Println(req.Footer)
+	} else if len(s.Receipt.Footer) > 0 {
+		p.Align("center")
+		for _, line := range s.Receipt.Footer {
+			p.PrintlnWrapped(line)
+		}
 	}
 
-	p.Feed(2).Cut(false)
+	p.Feed(2).CutMode(req.Cut)
 
 	// Send to printer
-	if err := p.Flush(); err != nil {
+	return p.Flush()
+}
+
+// TextPrintRequest represents a plain text print request.
+type TextPrintRequest struct {
+	Text  string `json:"text"`
+	Align string `json:"align"` // left (default), center or right
+	Cut   bool   `json:"cut"`
+	Feed  *int   `json:"feed"` // Lines to feed after the text (default 3)
+}
+
+// TextPrintHandler prints a block of plain text line by line.
+func (s *PrintService) TextPrintHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var req TextPrintRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	switch req.Align {
+	case "", "left", "center", "right":
+	default:
+		http.Error(w, "align must be left, center or right", http.StatusBadRequest)
+		return
+	}
+	if req.Feed != nil && (*req.Feed < 0 || *req.Feed > 255) {
+		http.Error(w, "feed must be between 0 and 255", http.StatusBadRequest)
+		return
+	}
+
+	if isPreview(r) {
+		s.preview(w, func(p *printer.Printer) error {
+			return s.printText(p, req)
+		})
+		return
+	}
+
+	if s.Queue != nil {
+		s.enqueue(w, jobText, body)
+		return
+	}
+
+	err = s.doPrint("/print/text", "", func(p *printer.Printer) error {
+		return s.printText(p, req)
+	})
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Print failed: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -140,10 +643,132 @@ func (s *PrintService) PrintHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
-		"message": "Receipt printed",
+		"message": "Text printed",
 	})
 }
 
+// printText prints req.Text, keeping lines that fit as-is and word-wrapping
+// the rest to the paper width.
+func (s *PrintService) printText(p *printer.Printer, req TextPrintRequest) error {
+	p.Init()
+	if req.Align != "" {
+		p.Align(req.Align)
+	}
+
+	text := strings.ReplaceAll(req.Text, "\r\n", "\n")
+	for _, line := range strings.Split(text, "\n") {
+		if printer.DisplayWidth(line) <= p.LineWidth() {
+			p.Println(line)
+		} else {
+			p.PrintlnWrapped(line)
+		}
+	}
+
+	feed := 3
+	if req.Feed != nil {
+		feed = *req.Feed
+	}
+	p.Feed(feed)
+	if req.Cut {
+		p.Cut(false)
+	}
+
+	return p.Flush()
+}
+
+// printLogo loads and prints a logo image centered, scaled to the paper.
+func (s *PrintService) printLogo(p *printer.Printer, path string) {
+	dir := s.TemplatesDir
+	if filepath.IsAbs(path) {
+		dir = ""
+	}
+
+	img, err := printer.LoadLogo(dir, path)
+	if err != nil {
+		log.Printf("[Receipt] Skipping logo: %v", err)
+		return
+	}
+
+	img = printer.ScaleToFit(img, p.PaperDots())
+	data, widthBytes, height := printer.ImageToRasterThreshold(img, p.ImageThreshold())
+	p.Align("center").
+		RasterImage(0, widthBytes, height, data).
+		NewLine()
+}
+
+// CustomPrintRequest represents a request to print a text/template layout.
+type CustomPrintRequest struct {
+	Template string      `json:"template"` // Name of a .tmpl file in the templates directory
+	Data     interface{} `json:"data"`     // Arbitrary JSON passed to the template
+}
+
+// CustomPrintHandler renders a user-defined receipt template with the
+// request data and prints it.
+func (s *PrintService) CustomPrintHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var req CustomPrintRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	tmpl, err := printer.LoadReceiptTemplate(s.TemplatesDir, req.Template)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, fmt.Sprintf("Template not found: %s", req.Template), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if isPreview(r) {
+		s.preview(w, func(p *printer.Printer) error {
+			return s.printCustom(p, tmpl, req.Data)
+		})
+		return
+	}
+
+	if s.Queue != nil {
+		s.enqueue(w, jobCustom, body)
+		return
+	}
+
+	err = s.doPrint("/print/custom", "", func(p *printer.Printer) error {
+		return s.printCustom(p, tmpl, req.Data)
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Print failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Custom template printed",
+	})
+}
+
+// printCustom renders tmpl with data and prints the result.
+func (s *PrintService) printCustom(p *printer.Printer, tmpl *template.Template, data interface{}) error {
+	p.Init()
+	if err := p.RenderTemplate(tmpl, data); err != nil {
+		p.Clear()
+		return queue.Permanent(err)
+	}
+	return p.Flush()
+}
+
 // RawPrintRequest represents a raw print request.
 type RawPrintRequest struct {
 	Data []byte `json:"data"`
@@ -162,8 +787,10 @@ func (s *PrintService) RawPrintHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.Printer.Raw(req.Data)
-	if err := s.Printer.Flush(); err != nil {
+	err := s.doPrint("/raw", "", func(p *printer.Printer) error {
+		return p.Raw(req.Data).Flush()
+	})
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Print failed: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -175,6 +802,206 @@ func (s *PrintService) RawPrintHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// DrawerRequest represents a cash drawer kick request.
+type DrawerRequest struct {
+	Pin int `json:"pin"` // Drawer kick connector pin, 2 (default) or 5
+}
+
+// DrawerHandler opens the cash drawer without printing.
+func (s *PrintService) DrawerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The body is optional
+	var req DrawerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Pin == 0 {
+		req.Pin = 2
+	}
+	if req.Pin != 2 && req.Pin != 5 {
+		http.Error(w, "pin must be 2 or 5", http.StatusBadRequest)
+		return
+	}
+
+	err := s.doPrint("/drawer", "", func(p *printer.Printer) error {
+		return p.CashDraw(req.Pin).Flush()
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Drawer kick failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Cash drawer opened",
+	})
+}
+
+// CutRequest represents a feed-and-cut request.
+type CutRequest struct {
+	Feed    int  `json:"feed"`    // Lines to feed before cutting, 0-20
+	Partial bool `json:"partial"` // Partial instead of full cut
+}
+
+// CutHandler feeds and cuts the paper without printing.
+func (s *PrintService) CutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The body is optional
+	var req CutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Feed < 0 || req.Feed > 20 {
+		http.Error(w, "feed must be between 0 and 20", http.StatusBadRequest)
+		return
+	}
+
+	err := s.doPrint("/cut", "", func(p *printer.Printer) error {
+		// Feed only the requested lines; Cut would add its own
+		return p.Feed(req.Feed).CutNoFeed(req.Partial).Flush()
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cut failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Paper cut",
+	})
+}
+
+// BeepRequest represents a buzzer request.
+type BeepRequest struct {
+	Times    int `json:"times"`    // Number of beeps (default 1)
+	Duration int `json:"duration"` // Length of each beep in the variant's units (default 1)
+}
+
+// BeepHandler sounds the printer's buzzer.
+func (s *PrintService) BeepHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The body is optional
+	req := BeepRequest{Times: 1, Duration: 1}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	variant := s.Printer.BeepVariant()
+	s.mu.Unlock()
+	maxTimes, maxDuration, _ := printer.BeepLimits(variant)
+	if req.Times < 1 || req.Times > maxTimes {
+		http.Error(w, fmt.Sprintf("times must be between 1 and %d", maxTimes), http.StatusBadRequest)
+		return
+	}
+	if req.Duration < 1 || req.Duration > maxDuration {
+		http.Error(w, fmt.Sprintf("duration must be between 1 and %d", maxDuration), http.StatusBadRequest)
+		return
+	}
+
+	err := s.doPrint("/beep", "", func(p *printer.Printer) error {
+		return p.Beep(req.Times, req.Duration).Flush()
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Beep failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Beep sent",
+		"variant": variant,
+	})
+}
+
+// NVLogoRequest represents a request to store a logo in NV memory.
+type NVLogoRequest struct {
+	Index int    `json:"index"` // NV logo index, 1-99 (default 1)
+	Image []byte `json:"image"` // PNG, JPEG, GIF or BMP file, base64 encoded
+}
+
+// NVLogoHandler stores a logo in the printer's NV memory, where it stays
+// across restarts and can be printed without sending the image again
+// (POST /logo/nv).
+func (s *PrintService) NVLogoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxLogoBody)
+	req := NVLogoRequest{Index: 1}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body is larger than %d KB", maxLogoBody>>10), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Index < printer.MinNVLogo || req.Index > printer.MaxNVLogo {
+		http.Error(w, fmt.Sprintf("index must be between %d and %d", printer.MinNVLogo, printer.MaxNVLogo), http.StatusBadRequest)
+		return
+	}
+	img, err := printer.DecodeLogo(req.Image)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.doPrint("/logo/nv", "", func(p *printer.Printer) error {
+		if err := p.StoreNVLogo(req.Index, img); err != nil {
+			return err
+		}
+		return p.Flush()
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Storing logo failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[Logo] Stored NV logo %d on printer %s", req.Index, s.printerName())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Logo stored in printer memory",
+		"index":   req.Index,
+	})
+}
+
+// TemplatesHandler lists the available order templates with their
+// accepted platform names (GET /templates).
+func (s *PrintService) TemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": printer.ListTemplates(s.TemplatesDir),
+	})
+}
+
 // TemplatePrintHandler handles template-based receipt printing for food delivery platforms.
 func (s *PrintService) TemplatePrintHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -191,13 +1018,67 @@ func (s *PrintService) TemplatePrintHandler(w http.ResponseWriter, r *http.Reque
 
 	// Parse the order
 	order, err := printer.ParseTemplateOrder(body)
+	var orderErr *printer.OrderError
+	if errors.As(err, &orderErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			*printer.OrderError
+		}{"error", orderErr.Error(), orderErr})
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Invalid order JSON: %v", err), http.StatusBadRequest)
 		return
 	}
 
+	// A misspelled platform would silently print with the generic header,
+	// so that has to be asked for
+	templateID := "fallback"
+	if tmpl, ok := printer.GetTemplate(order.Platform); ok {
+		templateID = printer.NormalizePlatform(tmpl.ID)
+	} else {
+		normalized := printer.NormalizePlatform(order.Platform)
+		log.Printf("[Template] Unknown platform %q (normalized %q), fallback %v", order.Platform, normalized, order.Fallback)
+		if !order.Fallback {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]string{
+				"status":     "error",
+				"message":    fmt.Sprintf("Unknown platform %q; see GET /templates, or set \"fallback\": true to print with a generic header", order.Platform),
+				"platform":   order.Platform,
+				"normalized": normalized,
+			})
+			return
+		}
+	}
+
+	if isPreview(r) {
+		s.preview(w, func(p *printer.Printer) error {
+			return p.PrintTemplateOrder(*order, s.TemplatesDir)
+		})
+		return
+	}
+
+	// Templates may send sections to other printers, e.g. items to the kitchen
+	if title, routes := orderRoutes(order); len(routes) > 0 {
+		results := s.printRouted(body, order, title, routes)
+		writeRoutedResponse(w, order, results)
+		return
+	}
+
+	if s.Queue != nil {
+		s.enqueue(w, jobTemplate, body)
+		return
+	}
+
 	// Print the order using template
-	if err := s.Printer.PrintTemplateOrder(*order, s.TemplatesDir); err != nil {
+	err = s.doPrint("/print/template", "", func(p *printer.Printer) error {
+		return p.PrintTemplateOrder(*order, s.TemplatesDir)
+	})
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Print failed: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -207,20 +1088,33 @@ func (s *PrintService) TemplatePrintHandler(w http.ResponseWriter, r *http.Reque
 		"status":   "success",
 		"message":  "Order printed",
 		"platform": order.Platform,
+		"template": templateID,
 	})
 }
 
+// truncate shortens s to maxLen printer columns, marking the cut with "...".
+// It never splits multi-byte characters.
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if printer.DisplayWidth(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return printer.TruncateWidth(s, maxLen-3) + "..."
 }
 
 // TestPrintHandler prints a comprehensive test receipt to verify all features.
 func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	p := s.Printer
 
+	// The test prints in several flushes; audit them as one job
+	var printErr error
+	start := p.BytesWritten()
+	defer func() {
+		s.recordJob("/test", "", p.BytesWritten()-start, printErr)
+	}()
+
 	// Initialize and build comprehensive test receipt
 	p.Init()
 
@@ -237,8 +1131,8 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 		NewLine()
 	
 	// Flush header immediately
-	if err := p.Flush(); err != nil {
-		http.Error(w, fmt.Sprintf("Print header failed: %v", err), http.StatusInternalServerError)
+	if printErr = p.Flush(); printErr != nil {
+		http.Error(w, fmt.Sprintf("Print header failed: %v", printErr), http.StatusInternalServerError)
 		return
 	}
 
@@ -278,13 +1172,14 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 		{"Bottled Water 500ml", 1, 1.50},
 	}
 
+	money := p.Money(printer.DefaultReceiptMoney)
 	subtotal := 0.0
 	for _, item := range items {
 		total := float64(item.qty) * item.price
 		subtotal += total
 		line := fmt.Sprintf("%-20s", truncate(item.name, 20))
 		p.Println(line)
-		qtyLine := fmt.Sprintf("  %d x $%.2f = $%.2f", item.qty, item.price, total)
+		qtyLine := fmt.Sprintf("  %d x %s = %s", item.qty, money.Format(item.price), money.Format(total))
 		p.Println(qtyLine)
 	}
 
@@ -295,12 +1190,12 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 	total := subtotal + tax
 
 	p.Align("right").
-		Println(fmt.Sprintf("Subtotal: $%.2f", subtotal)).
-		Println(fmt.Sprintf("Tax (8%%): $%.2f", tax)).
+		Println("Subtotal: " + money.Format(subtotal)).
+		Println("Tax (8%): " + money.Format(tax)).
 		NewLine().
 		Bold(true).
 		Size(1, 2).
-		Println(fmt.Sprintf("TOTAL: $%.2f", total)).
+		Println("TOTAL: " + money.Format(total)).
 		Size(1, 1).
 		Bold(false)
 
@@ -308,13 +1203,13 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 	p.Align("left").
 		DrawLine("-").
 		Println("Payment Method: CASH").
-		Println(fmt.Sprintf("Amount Tendered: $%.2f", 50.00)).
-		Println(fmt.Sprintf("Change: $%.2f", 50.00-total)).
+		Println("Amount Tendered: " + money.Format(50.00)).
+		Println("Change: " + money.Format(50.00-total)).
 		DrawLine("-")
 
 	// Flush receipt body
-	if err := p.Flush(); err != nil {
-		http.Error(w, fmt.Sprintf("Print body failed: %v", err), http.StatusInternalServerError)
+	if printErr = p.Flush(); printErr != nil {
+		http.Error(w, fmt.Sprintf("Print body failed: %v", printErr), http.StatusInternalServerError)
 		return
 	}
 
@@ -378,8 +1273,8 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 		NewLine()
 
 	// Flush features section 1
-	if err := p.Flush(); err != nil {
-		http.Error(w, fmt.Sprintf("Print features 1 failed: %v", err), http.StatusInternalServerError)
+	if printErr = p.Flush(); printErr != nil {
+		http.Error(w, fmt.Sprintf("Print features 1 failed: %v", printErr), http.StatusInternalServerError)
 		return
 	}
 
@@ -448,8 +1343,8 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 		NewLine()
 
 	// Flush features section 2
-	if err := p.Flush(); err != nil {
-		http.Error(w, fmt.Sprintf("Print features 2 failed: %v", err), http.StatusInternalServerError)
+	if printErr = p.Flush(); printErr != nil {
+		http.Error(w, fmt.Sprintf("Print features 2 failed: %v", printErr), http.StatusInternalServerError)
 		return
 	}
 
@@ -485,8 +1380,8 @@ func (s *PrintService) TestPrintHandler(w http.ResponseWriter, r *http.Request)
 	p.Feed(3).Cut(false)
 
 	// Send final chunk
-	if err := p.Flush(); err != nil {
-		http.Error(w, fmt.Sprintf("Print footer failed: %v", err), http.StatusInternalServerError)
+	if printErr = p.Flush(); printErr != nil {
+		http.Error(w, fmt.Sprintf("Print footer failed: %v", printErr), http.StatusInternalServerError)
 		return
 	}
 
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"printbridge/pkg/adapter"
+)
+
+func TestCutHandlerFeedsOnlyRequestedLines(t *testing.T) {
+	mem := adapter.NewMemoryAdapter()
+	s := NewPrintService(mem)
+
+	w := httptest.NewRecorder()
+	s.CutHandler(w, httptest.NewRequest(http.MethodPost, "/cut", strings.NewReader(`{"feed": 2, "partial": true}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	want := []byte{'\n', '\n', 0x1d, 0x56, 0x01}
+	if got := mem.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("sent % x, want % x", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"printbridge/pkg/printer"
+)
+
+// maxLogoSize is the largest logo file accepted, in bytes.
+const maxLogoSize = 1 << 20
+
+// maxLogoBody limits the body of logo uploads: the logo base64 encoded,
+// plus room for the other fields.
+const maxLogoBody = maxLogoSize*4/3 + 64<<10
+
+// LogoUploadRequest represents a request to store a platform's logo.
+type LogoUploadRequest struct {
+	Platform string `json:"platform"` // Platform or template key, e.g. "getir"
+	Image    []byte `json:"image"`    // PNG, JPEG, GIF or BMP file, base64 encoded
+}
+
+// LogoHandler stores the logo printed on a platform's template receipts
+// (POST /logo), or removes it (DELETE /logo?platform=...). Uploads are
+// either JSON or multipart/form-data with "platform" and "image" fields.
+// Logos are converted to BMP and must fit the width of the paper.
+func (s *PrintService) LogoHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.uploadLogo(w, r)
+	case http.MethodDelete:
+		s.deleteLogo(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *PrintService) uploadLogo(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLogoBody)
+
+	req, status, err := readLogoUpload(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if strings.TrimSpace(req.Platform) == "" {
+		http.Error(w, "platform is required", http.StatusBadRequest)
+		return
+	}
+	if _, ok := printer.GetTemplate(req.Platform); !ok {
+		http.Error(w, fmt.Sprintf("No template for platform %q, see GET /templates", req.Platform), http.StatusBadRequest)
+		return
+	}
+	if len(req.Image) > maxLogoSize {
+		http.Error(w, fmt.Sprintf("Logo is larger than %d KB", maxLogoSize>>10), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	s.mu.Lock()
+	maxDots := s.Printer.PaperDots()
+	s.mu.Unlock()
+	// Check the declared size first, decoding allocates every pixel
+	if err := printer.ValidateLogoData(req.Image, maxDots); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	img, err := printer.DecodeLogo(req.Image)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := printer.ValidateLogo(img, maxDots); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	path, err := printer.SaveLogo(s.TemplatesDir, req.Platform, img)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[Logo] Stored logo for %s at %s", printer.NormalizePlatform(req.Platform), path)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"message":  "Logo stored",
+		"platform": printer.NormalizePlatform(req.Platform),
+		"path":     path,
+		"width":    img.Bounds().Dx(),
+		"height":   img.Bounds().Dy(),
+	})
+}
+
+// readLogoUpload reads a JSON or multipart logo upload. On failure it
+// returns the HTTP status to answer with.
+func readLogoUpload(r *http.Request) (LogoUploadRequest, int, error) {
+	var req LogoUploadRequest
+	var tooLarge *http.MaxBytesError
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if errors.As(err, &tooLarge) {
+				return req, http.StatusRequestEntityTooLarge, fmt.Errorf("Request body is larger than %d KB", maxLogoBody>>10)
+			}
+			return req, http.StatusBadRequest, fmt.Errorf("Invalid JSON: %v", err)
+		}
+		return req, 0, nil
+	}
+
+	if err := r.ParseMultipartForm(maxLogoBody); err != nil {
+		if errors.As(err, &tooLarge) {
+			return req, http.StatusRequestEntityTooLarge, fmt.Errorf("Request body is larger than %d KB", maxLogoBody>>10)
+		}
+		return req, http.StatusBadRequest, fmt.Errorf("Invalid form: %v", err)
+	}
+	req.Platform = r.FormValue("platform")
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		return req, http.StatusBadRequest, fmt.Errorf("image file is required: %v", err)
+	}
+	defer file.Close()
+	// Read one byte past the limit, so oversized files are noticed
+	req.Image, err = io.ReadAll(io.LimitReader(file, maxLogoSize+1))
+	if err != nil {
+		return req, http.StatusBadRequest, fmt.Errorf("Failed to read image: %v", err)
+	}
+	return req, 0, nil
+}
+
+func (s *PrintService) deleteLogo(w http.ResponseWriter, r *http.Request) {
+	platform := r.URL.Query().Get("platform")
+	if strings.TrimSpace(platform) == "" {
+		http.Error(w, "platform is required", http.StatusBadRequest)
+		return
+	}
+	if _, ok := printer.GetTemplate(platform); !ok {
+		http.Error(w, fmt.Sprintf("No template for platform %q, see GET /templates", platform), http.StatusBadRequest)
+		return
+	}
+
+	if err := printer.DeleteLogo(s.TemplatesDir, platform); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, fmt.Sprintf("No logo for platform %q", platform), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[Logo] Deleted logo for %s", printer.NormalizePlatform(platform))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":   "success",
+		"message":  "Logo deleted",
+		"platform": printer.NormalizePlatform(platform),
+	})
+}
+
+// LogosHandler lists the logos in the templates directory (GET /logos).
+func (s *PrintService) LogosHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logos, err := printer.ListLogos(s.TemplatesDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"logos": logos,
+	})
+}
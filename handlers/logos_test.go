@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/printer"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLogoUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		image    string
+		want     int
+	}{
+		{"fits 58mm paper", "Getir", base64.StdEncoding.EncodeToString(testPNG(t, 384, 100)), http.StatusOK},
+		{"wider than paper", "getir", base64.StdEncoding.EncodeToString(testPNG(t, 385, 100)), http.StatusBadRequest},
+		{"unknown platform", "nowhere", base64.StdEncoding.EncodeToString(testPNG(t, 100, 100)), http.StatusBadRequest},
+		{"not an image", "getir", base64.StdEncoding.EncodeToString([]byte("hello")), http.StatusBadRequest},
+		{"oversized file", "getir", strings.Repeat("A", 2*maxLogoBody), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPrintServiceWithTemplates(adapter.NewMemoryAdapter(), t.TempDir(), 58)
+			body := `{"platform": "` + tt.platform + `", "image": "` + tt.image + `"}`
+			w := httptest.NewRecorder()
+			s.LogoHandler(w, httptest.NewRequest(http.MethodPost, "/logo", strings.NewReader(body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLogoUploadListDelete(t *testing.T) {
+	dir := t.TempDir()
+	s := NewPrintServiceWithTemplates(adapter.NewMemoryAdapter(), dir, 80)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("platform", "Trendyol")
+	fw, _ := mw.CreateFormFile("image", "logo.png")
+	fw.Write(testPNG(t, 576, 120))
+	mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/logo", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	s.LogoHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: status = %d: %s", w.Code, w.Body.String())
+	}
+
+	tmpl, _ := printer.GetTemplate("trendyol")
+	if got := printer.TemplateLogoPath(dir, tmpl); got != "logos/trendyol_go.bmp" {
+		t.Errorf("TemplateLogoPath = %q, want logos/trendyol_go.bmp", got)
+	}
+	logos, err := printer.ListLogos(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logos) != 1 || logos[0].Platform != "trendyol_go" || logos[0].Width != 576 || logos[0].Height != 120 {
+		t.Errorf("ListLogos = %+v, want trendyol_go 576x120", logos)
+	}
+
+	w = httptest.NewRecorder()
+	s.LogoHandler(w, httptest.NewRequest(http.MethodDelete, "/logo?platform=trendyol_go", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: status = %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	s.LogoHandler(w, httptest.NewRequest(http.MethodDelete, "/logo?platform=trendyol_go", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: status = %d, want 404", w.Code)
+	}
+
+	// Platforms must not reach outside the logos directory
+	outside := filepath.Join(dir, "x.bmp")
+	if err := os.WriteFile(outside, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, platform := range []string{"../x", `..\x`, "../../../x"} {
+		w = httptest.NewRecorder()
+		s.LogoHandler(w, httptest.NewRequest(http.MethodDelete, "/logo?platform="+url.QueryEscape(platform), nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("delete %q: status = %d, want 400", platform, w.Code)
+		}
+		if err := printer.DeleteLogo(dir, platform); err == nil || errors.Is(err, os.ErrNotExist) {
+			t.Errorf("DeleteLogo(%q) = %v, want it rejected", platform, err)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the logos directory was removed: %v", err)
+	}
+}
+
+func TestNVLogoHandlerRejectsOversizedBody(t *testing.T) {
+	s := NewPrintService(adapter.NewMemoryAdapter())
+	body := `{"index": 1, "image": "` + strings.Repeat("A", 2*maxLogoBody) + `"}`
+	w := httptest.NewRecorder()
+	s.NVLogoHandler(w, httptest.NewRequest(http.MethodPost, "/logo/nv", strings.NewReader(body)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
+
+// declaredPNG returns a small PNG whose header declares width x height
+// pixels, more than its image data holds.
+func declaredPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	data := testPNG(t, 1, 1)
+	// The IHDR chunk follows the 8 byte signature: length, type, then
+	// width and height, with its CRC after the 13 bytes of data
+	binary.BigEndian.PutUint32(data[16:], uint32(width))
+	binary.BigEndian.PutUint32(data[20:], uint32(height))
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestLogoUploadChecksSizeBeforeDecoding(t *testing.T) {
+	s := NewPrintServiceWithTemplates(adapter.NewMemoryAdapter(), t.TempDir(), 80)
+	body := `{"platform": "getir", "image": "` + base64.StdEncoding.EncodeToString(declaredPNG(t, 40000, 40000)) + `"}`
+	w := httptest.NewRecorder()
+	s.LogoHandler(w, httptest.NewRequest(http.MethodPost, "/logo", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "40000x40000") {
+		t.Errorf("body = %q, want the declared size rejected", w.Body.String())
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/printer"
+)
+
+// PreviewResponse is returned instead of printing when a print endpoint is
+// called with ?preview=1.
+type PreviewResponse struct {
+	Bytes   int    `json:"bytes"`   // Length of the rendered job
+	Data    []byte `json:"data"`    // Raw ESC/POS bytes, base64 encoded
+	Escaped string `json:"escaped"` // Bytes as a quoted string with escapes
+	Text    string `json:"text"`    // Approximate plain-text layout
+}
+
+// isPreview reports whether the request asks for a dry run.
+func isPreview(r *http.Request) bool {
+	v, err := strconv.ParseBool(r.URL.Query().Get("preview"))
+	return err == nil && v
+}
+
+// preview renders a job with fn on a copy of the printer that captures
+// its output, and responds with the bytes instead of printing them.
+func (s *PrintService) preview(w http.ResponseWriter, fn func(p *printer.Printer) error) {
+	capture := adapter.NewMemoryAdapter()
+	capture.Open()
+
+	s.mu.Lock()
+	p := s.Printer.WithAdapter(capture)
+	s.mu.Unlock()
+
+	if err := fn(p); err != nil {
+		http.Error(w, fmt.Sprintf("Preview failed: %v", err), http.StatusBadRequest)
+		return
+	}
+	data := append(capture.Bytes(), p.Buffer()...) // Include anything not flushed
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PreviewResponse{
+		Bytes:   len(data),
+		Data:    data,
+		Escaped: strconv.Quote(string(data)),
+		Text:    p.RenderText(data),
+	})
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"sync"
+
+	"printbridge/pkg/metrics"
+)
+
+// DefaultPrinter is the name requests without a printer are routed to.
+const DefaultPrinter = "default"
+
+// maxRouteBody is the largest JSON body Route reads to find the printer
+// field. Larger requests are rejected before they reach the handler.
+const maxRouteBody = 8 << 20
+
+// Printers routes requests to one of several named print services, e.g. a
+// kitchen printer and a front-counter receipt printer.
+type Printers struct {
+	mu       sync.RWMutex
+	services map[string]*PrintService
+}
+
+// NewPrinters creates a router with def as the default printer.
+func NewPrinters(def *PrintService) *Printers {
+	p := &Printers{services: map[string]*PrintService{}}
+	p.Add(DefaultPrinter, def)
+	return p
+}
+
+// Add registers s under name, replacing any service with that name.
+func (p *Printers) Add(name string, s *PrintService) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.Name = name
+	s.printers = p
+	p.services[name] = s
+}
+
+// Get returns the service named name; "" returns the default printer.
+func (p *Printers) Get(name string) (*PrintService, bool) {
+	if name == "" {
+		name = DefaultPrinter
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s, ok := p.services[name]
+	return s, ok
+}
+
+// Default returns the default printer's service.
+func (p *Printers) Default() *PrintService {
+	s, _ := p.Get(DefaultPrinter)
+	return s
+}
+
+// Names returns the printer names in sorted order.
+func (p *Printers) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.services))
+	for name := range p.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Route returns a handler that calls handler on the printer named by the
+// "printer" query parameter or, for application/json bodies, a top-level
+// "printer" field. Requests naming neither go to the default printer.
+//
+//	http.HandleFunc("/print", printers.Route((*PrintService).PrintHandler))
+func (p *Printers) Route(handler func(*PrintService, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := requestedPrinter(w, r)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body is larger than %d MB", maxRouteBody>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to read request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		s, ok := p.Get(name)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown printer %q", name), http.StatusNotFound)
+			return
+		}
+		handler(s, w, r)
+	}
+}
+
+// requestedPrinter returns the printer named by r, restoring the body
+// after looking into it. Only JSON bodies are read, at most maxRouteBody
+// bytes of them; others, such as multipart uploads, are left to the
+// handler to read.
+func requestedPrinter(w http.ResponseWriter, r *http.Request) (string, error) {
+	if name := r.URL.Query().Get("printer"); name != "" {
+		return name, nil
+	}
+	if r.Body == nil || r.Method != http.MethodPost {
+		return "", nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRouteBody))
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Bodies that aren't JSON objects simply have no printer field
+	var req struct {
+		Printer string `json:"printer"`
+	}
+	json.Unmarshal(body, &req)
+	return req.Printer, nil
+}
+
+// PrintersHandler lists the configured printers (GET /printers).
+func (p *Printers) PrintersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	type printerStatus struct {
+		Name      string `json:"name"`
+		Connected bool   `json:"connected"`
+	}
+	list := []printerStatus{}
+	for _, name := range p.Names() {
+		s, _ := p.Get(name)
+		s.mu.Lock()
+		connected := s.Adapter.IsOpen()
+		s.mu.Unlock()
+		list = append(list, printerStatus{Name: name, Connected: connected})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"printers": list,
+	})
+}
+
+// MetricsStates reports each printer's connection and queue depth for
+// /metrics.
+func (p *Printers) MetricsStates() []metrics.PrinterState {
+	var states []metrics.PrinterState
+	for _, name := range p.Names() {
+		s, _ := p.Get(name)
+		s.mu.Lock()
+		state := metrics.PrinterState{Name: name, Connected: s.Adapter.IsOpen()}
+		s.mu.Unlock()
+		if s.Queue != nil {
+			state.Queued = s.Queue.Pending()
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// Shutdown shuts down every printer's service, returning the first error.
+func (p *Printers) Shutdown(ctx context.Context) error {
+	var err error
+	for _, name := range p.Names() {
+		s, _ := p.Get(name)
+		if shutdownErr := s.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = fmt.Errorf("printer %s: %w", name, shutdownErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"printbridge/pkg/adapter"
+)
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func testPrinters() *Printers {
+	p := NewPrinters(NewPrintService(adapter.NewMemoryAdapter()))
+	p.Add("kitchen", NewPrintService(adapter.NewMemoryAdapter()))
+	return p
+}
+
+func TestRouteSelectsPrinter(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json field", "/print", "application/json", `{"printer": "kitchen", "text": "x"}`, "kitchen"},
+		{"json with charset", "/print", "application/json; charset=utf-8", `{"printer": "kitchen"}`, "kitchen"},
+		{"query parameter", "/print?printer=kitchen", "text/plain", "x", "kitchen"},
+		{"no printer", "/print", "application/json", `{"text": "x"}`, DefaultPrinter},
+		{"not json", "/print", "text/plain", `{"printer": "kitchen"}`, DefaultPrinter},
+		{"no content type", "/print", "", `{"printer": "kitchen"}`, DefaultPrinter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, gotBody string
+			handler := testPrinters().Route(func(s *PrintService, w http.ResponseWriter, r *http.Request) {
+				got = s.Name
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+			}
+			if got != tt.want {
+				t.Errorf("routed to %q, want %q", got, tt.want)
+			}
+			if gotBody != tt.body {
+				t.Errorf("handler read body %q, want %q", gotBody, tt.body)
+			}
+		})
+	}
+}
+
+func TestRouteUnknownPrinter(t *testing.T) {
+	handler := testPrinters().Route(func(s *PrintService, w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called for an unknown printer")
+	})
+	r := httptest.NewRequest(http.MethodPost, "/print", strings.NewReader(`{"printer": "bar"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestRouteRejectsOversizedJSON(t *testing.T) {
+	handler := testPrinters().Route(func(s *PrintService, w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called for an oversized body")
+	})
+	r := httptest.NewRequest(http.MethodPost, "/print", strings.NewReader(`{"text": "`+strings.Repeat("x", maxRouteBody)+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
+
+func TestRouteLeavesMultipartBodyUnread(t *testing.T) {
+	body := &countingReader{r: strings.NewReader(strings.Repeat("x", 1<<20))}
+	handler := testPrinters().Route(func(s *PrintService, w http.ResponseWriter, r *http.Request) {})
+	r := httptest.NewRequest(http.MethodPost, "/logo", body)
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	handler(httptest.NewRecorder(), r)
+	if body.n != 0 {
+		t.Errorf("Route read %d bytes of a multipart body, want 0", body.n)
+	}
+}
+
+// The handlers' own body limits must apply to requests going through Route.
+func TestRouteKeepsHandlerBodyLimits(t *testing.T) {
+	p := testPrinters()
+	oversized := strings.Repeat("A", 2*maxLogoBody)
+
+	tests := []struct {
+		name    string
+		handler func(*PrintService, http.ResponseWriter, *http.Request)
+		url     string
+		body    string
+	}{
+		{"logo", (*PrintService).LogoHandler, "/logo", `{"platform": "getir", "image": "` + oversized + `"}`},
+		{"nv logo", (*PrintService).NVLogoHandler, "/logo/nv", `{"index": 1, "image": "` + oversized + `"}`},
+	}
+	for _, tt := range tests {
+		body := &countingReader{r: strings.NewReader(tt.body)}
+		r := httptest.NewRequest(http.MethodPost, tt.url, body)
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		p.Route(tt.handler)(w, r)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status = %d, want 413", tt.name, w.Code)
+		}
+		if body.n > maxRouteBody+512 {
+			t.Errorf("%s: read %d bytes, want at most %d", tt.name, body.n, maxRouteBody)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"printbridge/pkg/printer"
+)
+
+// PrinterResult reports what happened to an order on one printer.
+type PrinterResult struct {
+	Status string `json:"status"` // success, queued or error
+	JobID  string `json:"job_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// sectionsJob is the queued payload for the part of a template order
+// routed to another printer.
+type sectionsJob struct {
+	Order    json.RawMessage           `json:"order"`
+	Title    string                    `json:"title"`
+	Sections []printer.TemplateSection `json:"sections"`
+}
+
+// orderRoutes returns the sections the order's template sends to other
+// printers, and the title printed above them.
+func orderRoutes(order *printer.TemplateOrder) (string, map[string][]printer.TemplateSection) {
+	tmpl, ok := printer.GetTemplate(order.Platform)
+	if !ok {
+		return "", nil
+	}
+	return tmpl.Name, tmpl.Printers
+}
+
+// printerName returns the name s is routed under.
+func (s *PrintService) printerName() string {
+	if s.Name == "" {
+		return DefaultPrinter
+	}
+	return s.Name
+}
+
+// routedResults reports a template order split across printers: the full
+// receipt on the printer the order was sent to, and a ticket on each
+// printer the template routes sections to. Tickets are kept apart from the
+// receipt, as a template may also route sections to the receipt printer.
+type routedResults struct {
+	Printer string                   // Printer the receipt was sent to
+	Receipt PrinterResult            // Full receipt
+	Tickets map[string]PrinterResult // Routed sections, by printer
+}
+
+// printRouted prints the full order on s and the routed sections on their
+// printers, all at once so an offline printer doesn't hold up the others.
+// With a queue, each part is queued on its printer instead. body is the
+// order as received.
+func (s *PrintService) printRouted(body []byte, order *printer.TemplateOrder, title string, routes map[string][]printer.TemplateSection) routedResults {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = routedResults{Printer: s.printerName(), Tickets: map[string]PrinterResult{}}
+	)
+	setTicket := func(name string, result PrinterResult) {
+		mu.Lock()
+		results.Tickets[name] = result
+		mu.Unlock()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result := s.submit(jobTemplate, body, func(p *printer.Printer) error {
+			return p.PrintTemplateOrder(*order, s.TemplatesDir)
+		})
+		mu.Lock()
+		results.Receipt = result
+		mu.Unlock()
+	}()
+
+	for name, sections := range routes {
+		var target *PrintService
+		if s.printers != nil {
+			target, _ = s.printers.Get(name)
+		}
+		if target == nil {
+			setTicket(name, PrinterResult{Status: "error", Error: fmt.Sprintf("unknown printer %q", name)})
+			continue
+		}
+
+		payload, err := json.Marshal(sectionsJob{Order: body, Title: title, Sections: sections})
+		if err != nil {
+			setTicket(name, PrinterResult{Status: "error", Error: err.Error()})
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, sections []printer.TemplateSection) {
+			defer wg.Done()
+			setTicket(name, target.submit(jobTemplateSections, payload, func(p *printer.Printer) error {
+				return p.PrintOrderSections(*order, title, sections)
+			}))
+		}(name, sections)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// submit queues a job of kind, or prints it right away with fn if the
+// queue is disabled.
+func (s *PrintService) submit(kind string, payload []byte, fn func(p *printer.Printer) error) PrinterResult {
+	if s.Queue != nil {
+		job, err := s.Queue.Enqueue(kind, payload)
+		if err != nil {
+			return PrinterResult{Status: "error", Error: fmt.Sprintf("failed to queue job: %v", err)}
+		}
+		return PrinterResult{Status: job.Status, JobID: job.ID}
+	}
+
+	if err := s.doPrint(jobEndpoints[kind], "", fn); err != nil {
+		return PrinterResult{Status: "error", Error: err.Error()}
+	}
+	return PrinterResult{Status: "success"}
+}
+
+// writeRoutedResponse reports the results of a routed order. The request
+// fails only if the receipt and every ticket failed.
+func writeRoutedResponse(w http.ResponseWriter, order *printer.TemplateOrder, results routedResults) {
+	failed, queued := 0, false
+	count := func(result PrinterResult) {
+		switch result.Status {
+		case "error":
+			failed++
+		case "success":
+		default:
+			queued = true
+		}
+	}
+	count(results.Receipt)
+	for _, result := range results.Tickets {
+		count(result)
+	}
+	parts := 1 + len(results.Tickets)
+
+	response := map[string]interface{}{
+		"platform": order.Platform,
+		"printer":  results.Printer,
+		"receipt":  results.Receipt,
+		"tickets":  results.Tickets,
+	}
+	code := http.StatusOK
+	switch {
+	case failed == parts:
+		code = http.StatusInternalServerError
+		response["status"] = "error"
+		response["message"] = "Order failed on all printers"
+	case failed > 0:
+		response["status"] = "partial"
+		response["message"] = fmt.Sprintf("Order failed on %d of %d printers", failed, parts)
+	case queued:
+		code = http.StatusAccepted
+		response["status"] = results.Receipt.Status
+		response["message"] = "Order queued"
+	default:
+		response["status"] = "success"
+		response["message"] = "Order printed"
+	}
+	if jobID := results.Receipt.JobID; jobID != "" {
+		response["job_id"] = jobID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/printer"
+)
+
+const testRoutedOrder = `{"platform": "getir", "merchant": {"name": "Cafe"}, "items": [{"name": "Tea", "quantity": 2}], "totals": {"total_try": 5}}`
+
+// routedService returns the default printer's service, with a working
+// kitchen printer and an offline bar printer next to it.
+func routedService(t *testing.T) (*PrintService, map[string]*adapter.MemoryAdapter) {
+	t.Helper()
+	mems := map[string]*adapter.MemoryAdapter{
+		DefaultPrinter: adapter.NewMemoryAdapter(),
+		"kitchen":      adapter.NewMemoryAdapter(),
+		"bar":          {OpenErr: errors.New("printer offline")},
+	}
+	p := NewPrinters(NewPrintServiceWithTemplates(mems[DefaultPrinter], t.TempDir(), 80))
+	p.Add("kitchen", NewPrintService(mems["kitchen"]))
+	p.Add("bar", NewPrintService(mems["bar"]))
+	return p.Default(), mems
+}
+
+func printTestRouted(t *testing.T, s *PrintService, routes map[string][]printer.TemplateSection) routedResults {
+	t.Helper()
+	order, err := printer.ParseTemplateOrder([]byte(testRoutedOrder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.printRouted([]byte(testRoutedOrder), order, "Acme", routes)
+}
+
+func TestPrintRoutedTicketOnReceiptPrinter(t *testing.T) {
+	s, mems := routedService(t)
+	items := []printer.TemplateSection{{Type: "items"}}
+	results := printTestRouted(t, s, map[string][]printer.TemplateSection{DefaultPrinter: items, "kitchen": items})
+
+	if results.Printer != DefaultPrinter || results.Receipt.Status != "success" {
+		t.Errorf("receipt = %s %+v, want success on %s", results.Printer, results.Receipt, DefaultPrinter)
+	}
+	for _, name := range []string{DefaultPrinter, "kitchen"} {
+		if got := results.Tickets[name]; got.Status != "success" {
+			t.Errorf("ticket on %s = %+v, want success", name, got)
+		}
+	}
+	// The default printer prints the receipt and its own ticket
+	if got := bytes.Count(mems[DefaultPrinter].Bytes(), []byte("Acme")); got != 1 {
+		t.Errorf("default printer got %d tickets, want 1", got)
+	}
+	if !bytes.Contains(mems[DefaultPrinter].Bytes(), []byte("Cafe")) {
+		t.Error("default printer didn't print the receipt")
+	}
+}
+
+func TestPrintRoutedFailures(t *testing.T) {
+	s, _ := routedService(t)
+	items := []printer.TemplateSection{{Type: "items"}}
+	results := printTestRouted(t, s, map[string][]printer.TemplateSection{"kitchen": items, "bar": items, "patio": items})
+
+	if results.Receipt.Status != "success" {
+		t.Errorf("receipt = %+v, want success", results.Receipt)
+	}
+	if got := results.Tickets["kitchen"]; got.Status != "success" {
+		t.Errorf("kitchen ticket = %+v, want success", got)
+	}
+	if got := results.Tickets["bar"]; got.Status != "error" || got.Error == "" {
+		t.Errorf("bar ticket = %+v, want an error", got)
+	}
+	if got := results.Tickets["patio"]; got.Status != "error" || got.Error != `unknown printer "patio"` {
+		t.Errorf("patio ticket = %+v, want an unknown printer error", got)
+	}
+}
+
+func TestWriteRoutedResponse(t *testing.T) {
+	ok := PrinterResult{Status: "success"}
+	failed := PrinterResult{Status: "error", Error: "printer offline"}
+	queued := PrinterResult{Status: "queued", JobID: "abc"}
+
+	tests := []struct {
+		name    string
+		receipt PrinterResult
+		tickets map[string]PrinterResult
+		code    int
+		status  string
+		message string
+	}{
+		{"all printed", ok, map[string]PrinterResult{"kitchen": ok}, http.StatusOK, "success", "Order printed"},
+		{"ticket failed", ok, map[string]PrinterResult{"kitchen": failed}, http.StatusOK, "partial", "Order failed on 1 of 2 printers"},
+		{"receipt failed", failed, map[string]PrinterResult{"kitchen": ok}, http.StatusOK, "partial", "Order failed on 1 of 2 printers"},
+		{"receipt failed, ticket on same printer printed", failed, map[string]PrinterResult{DefaultPrinter: ok}, http.StatusOK, "partial", "Order failed on 1 of 2 printers"},
+		{"all failed", failed, map[string]PrinterResult{"kitchen": failed, "bar": failed}, http.StatusInternalServerError, "error", "Order failed on all printers"},
+		{"queued", queued, map[string]PrinterResult{"kitchen": queued}, http.StatusAccepted, "queued", "Order queued"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			order := &printer.TemplateOrder{Platform: "getir"}
+			writeRoutedResponse(w, order, routedResults{Printer: DefaultPrinter, Receipt: tt.receipt, Tickets: tt.tickets})
+			if w.Code != tt.code {
+				t.Errorf("code = %d, want %d", w.Code, tt.code)
+			}
+
+			var resp struct {
+				Status  string                   `json:"status"`
+				Message string                   `json:"message"`
+				Printer string                   `json:"printer"`
+				JobID   string                   `json:"job_id"`
+				Receipt PrinterResult            `json:"receipt"`
+				Tickets map[string]PrinterResult `json:"tickets"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != tt.status || resp.Message != tt.message {
+				t.Errorf("status %q, message %q, want %q, %q", resp.Status, resp.Message, tt.status, tt.message)
+			}
+			if resp.Printer != DefaultPrinter || resp.Receipt != tt.receipt || len(resp.Tickets) != len(tt.tickets) {
+				t.Errorf("response = %+v, want the receipt on %s and %d tickets", resp, DefaultPrinter, len(tt.tickets))
+			}
+			if resp.JobID != tt.receipt.JobID {
+				t.Errorf("job_id = %q, want %q", resp.JobID, tt.receipt.JobID)
+			}
+		})
+	}
+}
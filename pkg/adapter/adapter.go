@@ -1,5 +1,14 @@
 package adapter
 
+import (
+	"errors"
+	"runtime"
+)
+
+// ErrReadTimeout is returned by Read when the printer sent nothing back in
+// time, e.g. because it doesn't answer status requests.
+var ErrReadTimeout = errors.New("read timed out")
+
 // Adapter interface defines the contract for all printer adapters.
 // This follows the adapter pattern from node-escpos for extensibility.
 type Adapter interface {
@@ -19,6 +28,28 @@ type Adapter interface {
 	IsOpen() bool
 }
 
+// Bidirectional is implemented by adapters that can read responses back
+// from the printer. Adapters that don't implement it, or return false from
+// CanRead, are write-only and can't report real-time status.
+type Bidirectional interface {
+	CanRead() bool
+}
+
+// SpoolerStatus is the printer state reported by the operating system's
+// print spooler, for adapters that can't query the printer directly.
+type SpoolerStatus struct {
+	Online    bool
+	PaperOut  bool
+	CoverOpen bool
+	Error     bool // Jam, paper problem, needs user intervention, ...
+}
+
+// StatusReporter is implemented by adapters that get printer status from
+// a spooler instead of reading DLE EOT responses.
+type StatusReporter interface {
+	SpoolerStatus() (SpoolerStatus, error)
+}
+
 // PrinterInfo contains device details for discovery.
 type PrinterInfo struct {
 	VendorID     uint16 `json:"vendor_id"`
@@ -26,5 +57,25 @@ type PrinterInfo struct {
 	Manufacturer string `json:"manufacturer"`
 	Product      string `json:"product"`
 	IsPrinter    bool   `json:"is_printer"`
-	DeviceType   string `json:"device_type"` // "USB" or "Windows"
+	DeviceType   string `json:"device_type"`          // "USB", "Windows" or "Network"
+	IsDefault    bool   `json:"is_default,omitempty"` // System default printer (Windows)
+	Offline      bool   `json:"offline,omitempty"`    // Reported offline by the spooler (Windows)
+}
+
+// Compiled returns the adapter types this build can open, named as in the
+// "adapter" config setting. USB needs a native cgo build, the Windows
+// spooler needs Windows, and Bluetooth needs Linux or Windows.
+func Compiled() []string {
+	var types []string
+	if usbSupported {
+		types = append(types, "usb")
+	}
+	if runtime.GOOS == "windows" {
+		types = append(types, "windows")
+	}
+	types = append(types, "network")
+	if bluetoothSupported {
+		types = append(types, "bluetooth")
+	}
+	return append(types, "console")
 }
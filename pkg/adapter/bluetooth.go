@@ -0,0 +1,135 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BluetoothAdapter communicates with Bluetooth Classic receipt printers
+// over RFCOMM (Serial Port Profile).
+type BluetoothAdapter struct {
+	mu      sync.Mutex
+	address string
+	channel int
+	conn    io.ReadWriteCloser
+	open    bool
+}
+
+// NewBluetoothAdapter creates a new Bluetooth adapter for the printer with
+// the given MAC address (e.g. "00:11:22:33:44:55").
+func NewBluetoothAdapter(address string, channel int) *BluetoothAdapter {
+	if channel == 0 {
+		channel = 1 // SPP is almost always on channel 1
+	}
+	return &BluetoothAdapter{
+		address: address,
+		channel: channel,
+	}
+}
+
+// Open connects to the printer.
+func (b *BluetoothAdapter) Open() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open {
+		return nil
+	}
+
+	addr, err := parseBluetoothAddress(b.address)
+	if err != nil {
+		return err
+	}
+
+	conn, err := dialRFCOMM(addr, b.channel)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s channel %d: %v", b.address, b.channel, err)
+	}
+
+	b.conn = conn
+	b.open = true
+	return nil
+}
+
+// Write sends data to the printer.
+func (b *BluetoothAdapter) Write(data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return fmt.Errorf("adapter not open")
+	}
+
+	for len(data) > 0 {
+		n, err := b.conn.Write(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// Read reads data from the printer.
+func (b *BluetoothAdapter) Read() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil, fmt.Errorf("adapter not open")
+	}
+
+	if d, ok := b.conn.(interface{ SetReadDeadline(time.Time) error }); ok {
+		d.SetReadDeadline(time.Now().Add(5 * time.Second))
+	}
+
+	buf := make([]byte, 1024)
+	n, err := b.conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// Close closes the connection.
+func (b *BluetoothAdapter) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	err := b.conn.Close()
+	b.open = false
+	return err
+}
+
+// IsOpen returns true if connected.
+func (b *BluetoothAdapter) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// parseBluetoothAddress parses a MAC address like "00:11:22:33:44:55"
+// (or with dashes) into bytes, most significant first.
+func parseBluetoothAddress(s string) ([6]byte, error) {
+	var addr [6]byte
+
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == ':' || r == '-' })
+	if len(parts) != 6 {
+		return addr, fmt.Errorf("invalid Bluetooth address %q", s)
+	}
+	for i, part := range parts {
+		v, err := strconv.ParseUint(part, 16, 8)
+		if err != nil {
+			return addr, fmt.Errorf("invalid Bluetooth address %q", s)
+		}
+		addr[i] = byte(v)
+	}
+	return addr, nil
+}
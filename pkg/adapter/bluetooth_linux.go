@@ -0,0 +1,42 @@
+//go:build linux
+// +build linux
+
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// bluetoothSupported reports whether this platform can dial RFCOMM.
+const bluetoothSupported = true
+
+// dialRFCOMM opens an RFCOMM socket to the given address and channel.
+func dialRFCOMM(addr [6]byte, channel int) (io.ReadWriteCloser, error) {
+	fd, err := unix.Socket(unix.AF_BLUETOOTH, unix.SOCK_STREAM, unix.BTPROTO_RFCOMM)
+	if err != nil {
+		return nil, fmt.Errorf("Bluetooth not available: %v", err)
+	}
+
+	// The kernel expects the address in little-endian order
+	sa := &unix.SockaddrRFCOMM{Channel: uint8(channel)}
+	for i := range addr {
+		sa.Addr[i] = addr[len(addr)-1-i]
+	}
+
+	if err := unix.Connect(fd, sa); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	// Non-blocking lets the runtime poller handle reads with deadlines
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), "rfcomm"), nil
+}
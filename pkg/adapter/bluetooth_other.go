@@ -0,0 +1,17 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package adapter
+
+import (
+	"fmt"
+	"io"
+)
+
+// bluetoothSupported reports whether this platform can dial RFCOMM.
+const bluetoothSupported = false
+
+// dialRFCOMM is not available on this platform.
+func dialRFCOMM(addr [6]byte, channel int) (io.ReadWriteCloser, error) {
+	return nil, fmt.Errorf("Bluetooth printers are not supported on this platform")
+}
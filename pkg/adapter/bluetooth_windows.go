@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/sys/windows"
+)
+
+// bluetoothSupported reports whether this platform can dial RFCOMM.
+const bluetoothSupported = true
+
+// dialRFCOMM opens a Winsock AF_BTH RFCOMM socket to the given address and channel.
+func dialRFCOMM(addr [6]byte, channel int) (io.ReadWriteCloser, error) {
+	var wsaData windows.WSAData
+	if err := windows.WSAStartup(uint32(0x202), &wsaData); err != nil {
+		return nil, fmt.Errorf("WSAStartup failed: %v", err)
+	}
+
+	s, err := windows.Socket(windows.AF_BTH, windows.SOCK_STREAM, windows.BTHPROTO_RFCOMM)
+	if err != nil {
+		windows.WSACleanup()
+		return nil, fmt.Errorf("Bluetooth not available: %v", err)
+	}
+
+	var btAddr uint64
+	for _, b := range addr {
+		btAddr = btAddr<<8 | uint64(b)
+	}
+
+	if err := windows.Connect(s, &windows.SockaddrBth{BtAddr: btAddr, Port: uint32(channel)}); err != nil {
+		windows.Closesocket(s)
+		windows.WSACleanup()
+		return nil, err
+	}
+
+	return &btSocket{handle: s}, nil
+}
+
+// btSocket wraps a Winsock Bluetooth socket as an io.ReadWriteCloser.
+type btSocket struct {
+	handle windows.Handle
+}
+
+func (b *btSocket) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var sent uint32
+	buf := windows.WSABuf{Len: uint32(len(p)), Buf: &p[0]}
+	err := windows.WSASend(b.handle, &buf, 1, &sent, 0, nil, nil)
+	return int(sent), err
+}
+
+func (b *btSocket) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var recvd, flags uint32
+	buf := windows.WSABuf{Len: uint32(len(p)), Buf: &p[0]}
+	if err := windows.WSARecv(b.handle, &buf, 1, &recvd, &flags, nil, nil); err != nil {
+		return 0, err
+	}
+	if recvd == 0 {
+		return 0, io.EOF
+	}
+	return int(recvd), nil
+}
+
+func (b *btSocket) Close() error {
+	err := windows.Closesocket(b.handle)
+	windows.WSACleanup()
+	return err
+}
@@ -8,7 +8,8 @@ import (
 // ConsoleAdapter is a testing adapter that prints to stdout.
 // Useful for development and debugging without a physical printer.
 type ConsoleAdapter struct {
-	open bool
+	open   bool
+	render func([]byte) string
 }
 
 // NewConsoleAdapter creates a new console adapter.
@@ -16,6 +17,12 @@ func NewConsoleAdapter() *ConsoleAdapter {
 	return &ConsoleAdapter{}
 }
 
+// SetRenderer makes Write print the output of render (such as
+// printer.RenderText) instead of the raw bytes.
+func (c *ConsoleAdapter) SetRenderer(render func([]byte) string) {
+	c.render = render
+}
+
 // Open simulates opening a connection.
 func (c *ConsoleAdapter) Open() error {
 	c.open = true
@@ -29,6 +36,11 @@ func (c *ConsoleAdapter) Write(data []byte) error {
 		return fmt.Errorf("adapter not open")
 	}
 
+	if c.render != nil {
+		fmt.Fprintf(os.Stdout, "[PRINT]\n%s", c.render(data))
+		return nil
+	}
+
 	// Print raw bytes in hex for ESC/POS commands, text otherwise
 	fmt.Fprintf(os.Stdout, "[PRINT] %s", string(data))
 	return nil
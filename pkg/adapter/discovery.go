@@ -1,13 +1,33 @@
 package adapter
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"runtime"
+	"sync"
+	"time"
 )
 
-// FindPrinters aggregates printers from all available sources (Windows Spooler, USB via SetupAPI).
+// FindPrinters aggregates printers from all available sources (Windows Spooler,
+// USB via SetupAPI or libusb, and mDNS for network printers).
 func FindPrinters() ([]PrinterInfo, error) {
+	return FindPrintersFiltered(false)
+}
+
+// FindPrintersFiltered is FindPrinters, leaving out USB devices that aren't
+// printers (keyboards, hubs, webcams, ...) when printersOnly is set.
+func FindPrintersFiltered(printersOnly bool) ([]PrinterInfo, error) {
+	return FindPrintersContext(context.Background(), printersOnly)
+}
+
+// FindPrintersContext is FindPrintersFiltered, bounded by ctx. If ctx ends
+// during the USB scan, the printers found so far are returned with an
+// error wrapping ErrDiscoveryIncomplete.
+func FindPrintersContext(ctx context.Context, printersOnly bool) ([]PrinterInfo, error) {
 	var allPrinters []PrinterInfo
+	var incomplete error
 
 	if runtime.GOOS == "windows" {
 		// 1. Windows Spooler Printers
@@ -19,11 +39,18 @@ func FindPrinters() ([]PrinterInfo, error) {
 		}
 
 		// 2. All USB Devices (via SetupAPI)
-		usbDevices, err := FindAllUSBDevices()
+		usbDevices, err := FindAllUSBDevicesContext(ctx)
+		if errors.Is(err, ErrDiscoveryIncomplete) {
+			log.Printf("[Discovery] USB scan incomplete: %v", err)
+			incomplete, err = err, nil
+		}
 		if err != nil {
 			log.Printf("[Discovery] Failed to list USB devices: %v", err)
 		} else {
 			for _, dev := range usbDevices {
+				if printersOnly && !dev.IsPrinter {
+					continue
+				}
 				allPrinters = append(allPrinters, PrinterInfo{
 					VendorID:     dev.VendorID,
 					ProductID:    dev.ProductID,
@@ -36,7 +63,11 @@ func FindPrinters() ([]PrinterInfo, error) {
 		}
 	} else {
 		// Non-Windows: use libusb-based discovery
-		usbPrinters, err := FindUSBPrinters()
+		usbPrinters, err := ScanUSBDevices(USBScanOptions{PrintersOnly: printersOnly, Context: ctx})
+		if errors.Is(err, ErrDiscoveryIncomplete) {
+			log.Printf("[Discovery] USB scan incomplete: %v", err)
+			incomplete, err = err, nil
+		}
 		if err != nil {
 			log.Printf("[Discovery] Failed to list USB printers: %v", err)
 		} else {
@@ -47,6 +78,109 @@ func FindPrinters() ([]PrinterInfo, error) {
 		}
 	}
 
-	return allPrinters, nil
+	// Network printers advertised over mDNS/Bonjour. Browsing takes a few
+	// seconds, so it runs in the background and the last results are used.
+	allPrinters = append(allPrinters, cachedNetworkPrinters()...)
+
+	return allPrinters, incomplete
+}
+
+// DefaultDiscoveryCacheTTL is how long CachedPrinters reuses a scan unless
+// changed with SetDiscoveryCacheTTL.
+const DefaultDiscoveryCacheTTL = 10 * time.Second
+
+var (
+	discoveryMu    sync.Mutex
+	discoveryTTL   = DefaultDiscoveryCacheTTL
+	discoveryCache = map[bool]discoveryResult{} // Keyed by printersOnly
+
+	// discoveryScan is held while a scan runs; a channel rather than a
+	// mutex so waiting callers can give up when their context ends.
+	discoveryScan = make(chan struct{}, 1)
+)
+
+type discoveryResult struct {
+	printers []PrinterInfo
+	scanned  time.Time
+}
+
+// SetDiscoveryCacheTTL sets how long CachedPrinters results are reused;
+// 0 disables caching.
+func SetDiscoveryCacheTTL(ttl time.Duration) {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+	discoveryTTL = ttl
+}
+
+// CachedPrinters returns FindPrintersContext results, reusing the last
+// scan if it is recent, so frequent status polling doesn't keep opening
+// USB devices. refresh forces a new scan. Concurrent callers wait for a
+// single scan instead of starting their own. Partial results are returned
+// with an error wrapping ErrDiscoveryIncomplete and are not cached.
+func CachedPrinters(ctx context.Context, printersOnly, refresh bool) ([]PrinterInfo, error) {
+	select {
+	case discoveryScan <- struct{}{}:
+		defer func() { <-discoveryScan }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrDiscoveryIncomplete, ctx.Err())
+	}
+
+	discoveryMu.Lock()
+	cached, ok := discoveryCache[printersOnly]
+	fresh := ok && !refresh && time.Since(cached.scanned) < discoveryTTL
+	discoveryMu.Unlock()
+	if fresh {
+		return append([]PrinterInfo(nil), cached.printers...), nil
+	}
+
+	printers, err := FindPrintersContext(ctx, printersOnly)
+	if err != nil {
+		if errors.Is(err, ErrDiscoveryIncomplete) {
+			return printers, err
+		}
+		return nil, err
+	}
+
+	discoveryMu.Lock()
+	discoveryCache[printersOnly] = discoveryResult{printers: printers, scanned: time.Now()}
+	discoveryMu.Unlock()
+	return append([]PrinterInfo(nil), printers...), nil
+}
+
+// networkCacheTTL is how long mDNS browse results are reused.
+const networkCacheTTL = 30 * time.Second
+
+var (
+	networkMu       sync.Mutex
+	networkPrinters []PrinterInfo
+	networkScanned  time.Time
+	networkScanning bool
+)
+
+// cachedNetworkPrinters returns the most recent mDNS results, starting a
+// background browse if they are stale.
+func cachedNetworkPrinters() []PrinterInfo {
+	networkMu.Lock()
+	defer networkMu.Unlock()
+
+	if !networkScanning && time.Since(networkScanned) > networkCacheTTL {
+		networkScanning = true
+		go func() {
+			printers, err := FindNetworkPrinters()
+			if err != nil {
+				log.Printf("[Discovery] Failed to browse network printers: %v", err)
+			}
+
+			networkMu.Lock()
+			if err == nil {
+				networkPrinters = printers
+			}
+			networkScanned = time.Now()
+			networkScanning = false
+			networkMu.Unlock()
+		}()
+	}
+
+	return append([]PrinterInfo(nil), networkPrinters...)
 }
 
@@ -0,0 +1,129 @@
+package adapter
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mdnsService is the DNS-SD service type advertised by raw (port 9100) printers.
+const mdnsService = "_pdl-datastream._tcp.local."
+
+// mdnsBrowseTime is how long FindNetworkPrinters listens for responses.
+const mdnsBrowseTime = 2 * time.Second
+
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// FindNetworkPrinters browses mDNS/Bonjour for raw network printers.
+// Each result has the printer's IP (and port, if not 9100) in Product,
+// the advertised instance name in Manufacturer and DeviceType "Network".
+func FindNetworkPrinters() ([]PrinterInfo, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mDNS socket: %v", err)
+	}
+	defer conn.Close()
+
+	query, err := buildMDNSQuery()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(query, mdnsAddr); err != nil {
+		return nil, fmt.Errorf("failed to send mDNS query: %v", err)
+	}
+
+	instances := make(map[string]bool)     // Instance names from PTR records
+	targets := make(map[string]mdnsTarget) // Instance -> SRV host/port
+	hosts := make(map[string]net.IP)       // Host -> IPv4 from A records
+
+	conn.SetReadDeadline(time.Now().Add(mdnsBrowseTime))
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			break // Deadline reached
+		}
+		parseMDNSResponse(buf[:n], instances, targets, hosts)
+	}
+
+	var printers []PrinterInfo
+	for instance := range instances {
+		target, ok := targets[instance]
+		if !ok {
+			continue
+		}
+		ip, ok := hosts[target.host]
+		if !ok {
+			continue
+		}
+
+		addr := ip.String()
+		if target.port != 0 && target.port != 9100 {
+			addr = net.JoinHostPort(addr, fmt.Sprint(target.port))
+		}
+
+		name := strings.TrimSuffix(instance, "."+mdnsService)
+		log.Printf("[Discovery] Found network printer %q at %s", name, addr)
+		printers = append(printers, PrinterInfo{
+			Manufacturer: name,
+			Product:      addr,
+			IsPrinter:    true,
+			DeviceType:   "Network",
+		})
+	}
+
+	return printers, nil
+}
+
+// mdnsTarget is the host and port from an SRV record.
+type mdnsTarget struct {
+	host string
+	port uint16
+}
+
+// buildMDNSQuery builds a PTR query for the printer service with the
+// unicast-response bit set, so replies come back to our socket.
+func buildMDNSQuery() ([]byte, error) {
+	name, err := dnsmessage.NewName(mdnsService)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  dnsmessage.TypePTR,
+			Class: dnsmessage.ClassINET | 1<<15, // QU bit
+		}},
+	}
+	return msg.Pack()
+}
+
+// parseMDNSResponse collects PTR, SRV and A records from an mDNS response.
+func parseMDNSResponse(data []byte, instances map[string]bool, targets map[string]mdnsTarget, hosts map[string]net.IP) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(data); err != nil {
+		return
+	}
+
+	records := append(msg.Answers, msg.Additionals...)
+	for _, rr := range records {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.PTRResource:
+			if strings.EqualFold(rr.Header.Name.String(), mdnsService) {
+				instances[body.PTR.String()] = true
+			}
+		case *dnsmessage.SRVResource:
+			targets[rr.Header.Name.String()] = mdnsTarget{
+				host: body.Target.String(),
+				port: body.Port,
+			}
+		case *dnsmessage.AResource:
+			hosts[rr.Header.Name.String()] = net.IP(body.A[:])
+		}
+	}
+}
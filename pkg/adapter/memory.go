@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MemoryAdapter records everything written to it in memory.
+// Useful in tests to inspect exactly what would have been sent to a printer.
+// Set OpenErr or WriteErr to simulate a failing printer.
+type MemoryAdapter struct {
+	// OpenErr, if set, is returned by Open.
+	OpenErr error
+	// WriteErr, if set, is returned by Write and nothing is recorded.
+	WriteErr error
+
+	mu   sync.Mutex
+	open bool
+	data []byte
+}
+
+// NewMemoryAdapter creates a new in-memory adapter.
+func NewMemoryAdapter() *MemoryAdapter {
+	return &MemoryAdapter{}
+}
+
+// Open marks the adapter as open, or returns OpenErr.
+func (m *MemoryAdapter) Open() error {
+	if m.OpenErr != nil {
+		return m.OpenErr
+	}
+	m.mu.Lock()
+	m.open = true
+	m.mu.Unlock()
+	return nil
+}
+
+// Write appends data to the recorded bytes.
+func (m *MemoryAdapter) Write(data []byte) error {
+	if m.WriteErr != nil {
+		return m.WriteErr
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.open {
+		return fmt.Errorf("adapter not open")
+	}
+	m.data = append(m.data, data...)
+	return nil
+}
+
+// Read returns empty data (the memory adapter has no printer to answer).
+func (m *MemoryAdapter) Read() ([]byte, error) {
+	return nil, nil
+}
+
+// Close marks the adapter as closed. Recorded bytes are kept.
+func (m *MemoryAdapter) Close() error {
+	m.mu.Lock()
+	m.open = false
+	m.mu.Unlock()
+	return nil
+}
+
+// IsOpen returns true if the adapter is open.
+func (m *MemoryAdapter) IsOpen() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.open
+}
+
+// Bytes returns a copy of everything written so far.
+func (m *MemoryAdapter) Bytes() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]byte(nil), m.data...)
+}
+
+// Reset discards the recorded bytes.
+func (m *MemoryAdapter) Reset() {
+	m.mu.Lock()
+	m.data = nil
+	m.mu.Unlock()
+}
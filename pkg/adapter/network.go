@@ -3,16 +3,24 @@ package adapter
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"time"
+
+	"printbridge/pkg/metrics"
 )
 
 // NetworkAdapter communicates with network receipt printers (typically port 9100).
 type NetworkAdapter struct {
 	address string
 	port    int
-	timeout time.Duration
 	conn    net.Conn
 	open    bool
+
+	// DialTimeout limits how long connecting to the printer may take.
+	DialTimeout time.Duration
+	// KeepAlive is the TCP keepalive period used to detect dead connections.
+	// Zero uses the system default, a negative value disables keepalive.
+	KeepAlive time.Duration
 }
 
 // NewNetworkAdapter creates a new network adapter.
@@ -21,9 +29,10 @@ func NewNetworkAdapter(address string, port int) *NetworkAdapter {
 		port = 9100 // Default printer port
 	}
 	return &NetworkAdapter{
-		address: address,
-		port:    port,
-		timeout: 30 * time.Second,
+		address:     address,
+		port:        port,
+		DialTimeout: 30 * time.Second,
+		KeepAlive:   30 * time.Second,
 	}
 }
 
@@ -32,9 +41,17 @@ func (n *NetworkAdapter) Open() error {
 	if n.open {
 		return nil
 	}
+	return n.dial()
+}
 
-	addr := fmt.Sprintf("%s:%d", n.address, n.port)
-	conn, err := net.DialTimeout("tcp", addr, n.timeout)
+// dial establishes a new TCP connection to the printer.
+func (n *NetworkAdapter) dial() error {
+	addr := net.JoinHostPort(n.address, strconv.Itoa(n.port))
+	dialer := net.Dialer{
+		Timeout:   n.DialTimeout,
+		KeepAlive: n.KeepAlive,
+	}
+	conn, err := dialer.Dial("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("failed to connect to %s: %v", addr, err)
 	}
@@ -44,12 +61,26 @@ func (n *NetworkAdapter) Open() error {
 	return nil
 }
 
-// Write sends data to the printer.
+// Write sends data to the printer. If the connection was dropped, it
+// reconnects once and retries before returning the error.
 func (n *NetworkAdapter) Write(data []byte) error {
 	if !n.open {
 		return fmt.Errorf("adapter not open")
 	}
+
 	_, err := n.conn.Write(data)
+	if err == nil {
+		return nil
+	}
+
+	// The printer may have closed the session, redial and try again
+	n.conn.Close()
+	n.open = false
+	if dialErr := n.dial(); dialErr != nil {
+		return fmt.Errorf("write failed: %v (reconnect failed: %v)", err, dialErr)
+	}
+	metrics.AdapterReconnects.Inc()
+	_, err = n.conn.Write(data)
 	return err
 }
 
@@ -77,6 +108,12 @@ func (n *NetworkAdapter) Close() error {
 	return err
 }
 
+// CanRead returns true; network printers answer status requests on the
+// same connection.
+func (n *NetworkAdapter) CanRead() bool {
+	return true
+}
+
 // IsOpen returns true if connected.
 func (n *NetworkAdapter) IsOpen() bool {
 	return n.open
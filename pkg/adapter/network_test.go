@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestNetworkAdapterReconnects drops the printer's side of the connection
+// mid-session and checks that writes carry on over a new connection.
+func TestNetworkAdapterReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 64)
+			n, _ := conn.Read(buf)
+			received <- buf[:n]
+			if i == 0 {
+				// Reset the session, as a printer that was power cycled does
+				conn.(*net.TCPConn).SetLinger(0)
+				conn.Close()
+				continue
+			}
+			io.Copy(io.Discard, conn)
+			conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	n := NewNetworkAdapter("127.0.0.1", addr.Port)
+	n.DialTimeout = time.Second
+	if err := n.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	if err := n.Write([]byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; !bytes.Equal(got, []byte("first")) {
+		t.Fatalf("first connection received %q", got)
+	}
+
+	// Writes right after the reset may still be accepted by the local
+	// socket; the one that fails must reconnect instead of erroring
+	deadline := time.After(5 * time.Second)
+	for {
+		if err := n.Write([]byte("second")); err != nil {
+			t.Fatalf("Write after the connection dropped: %v", err)
+		}
+		select {
+		case got := <-received:
+			if !bytes.Equal(got, []byte("second")) {
+				t.Fatalf("second connection received %q", got)
+			}
+			return
+		case <-deadline:
+			t.Fatal("adapter did not reconnect")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
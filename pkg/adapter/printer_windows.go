@@ -12,14 +12,16 @@ import (
 var (
 	modwinspool = windows.NewLazySystemDLL("winspool.drv")
 
-	procOpenPrinterW      = modwinspool.NewProc("OpenPrinterW")
-	procClosePrinter      = modwinspool.NewProc("ClosePrinter")
-	procStartDocPrinterW  = modwinspool.NewProc("StartDocPrinterW")
-	procStartPagePrinter  = modwinspool.NewProc("StartPagePrinter")
-	procWritePrinter      = modwinspool.NewProc("WritePrinter")
-	procEndPagePrinter    = modwinspool.NewProc("EndPagePrinter")
-	procEndDocPrinter     = modwinspool.NewProc("EndDocPrinter")
-	procEnumPrintersW     = modwinspool.NewProc("EnumPrintersW")
+	procOpenPrinterW       = modwinspool.NewProc("OpenPrinterW")
+	procClosePrinter       = modwinspool.NewProc("ClosePrinter")
+	procStartDocPrinterW   = modwinspool.NewProc("StartDocPrinterW")
+	procStartPagePrinter   = modwinspool.NewProc("StartPagePrinter")
+	procWritePrinter       = modwinspool.NewProc("WritePrinter")
+	procEndPagePrinter     = modwinspool.NewProc("EndPagePrinter")
+	procEndDocPrinter      = modwinspool.NewProc("EndDocPrinter")
+	procEnumPrintersW      = modwinspool.NewProc("EnumPrintersW")
+	procGetPrinterW        = modwinspool.NewProc("GetPrinterW")
+	procGetDefaultPrinterW = modwinspool.NewProc("GetDefaultPrinterW")
 )
 
 // WindowsPrinter adapters for Windows Spooler API
@@ -109,10 +111,63 @@ func (w *WindowsPrinter) Write(data []byte) error {
 
 func (w *WindowsPrinter) Read() ([]byte, error) {
 	// Reading from a raw Windows printer handle is not typically supported 
-	// or requires bidirectional communication setup. Returning nil for now.
+	// or requires bidirectional communication setup. Status is available
+	// from the spooler through SpoolerStatus instead.
 	return nil, nil
 }
 
+// SpoolerStatus reports the printer state from PRINTER_INFO_2.Status and
+// Attributes as maintained by the spooler and the printer's port monitor.
+func (w *WindowsPrinter) SpoolerStatus() (SpoolerStatus, error) {
+	var status SpoolerStatus
+
+	handle := w.handle
+	if handle == 0 {
+		if err := w.Open(); err != nil {
+			return status, err
+		}
+		defer w.Close()
+		handle = w.handle
+	}
+
+	info, err := getPrinterInfo2(handle)
+	if err != nil {
+		return status, err
+	}
+
+	st := info.Status
+	status.Online = info.online()
+	status.PaperOut = st&PRINTER_STATUS_PAPER_OUT != 0
+	status.CoverOpen = st&PRINTER_STATUS_DOOR_OPEN != 0
+	status.Error = st&(PRINTER_STATUS_ERROR|PRINTER_STATUS_PAPER_JAM|
+		PRINTER_STATUS_PAPER_PROBLEM|PRINTER_STATUS_USER_INTERVENTION) != 0
+	return status, nil
+}
+
+// getPrinterInfo2 calls GetPrinterW level 2 on an open printer handle.
+func getPrinterInfo2(handle windows.Handle) (*PRINTER_INFO_2, error) {
+	var needed uint32
+
+	// BOOL GetPrinterW(HANDLE hPrinter, DWORD Level, LPBYTE pPrinter, DWORD cbBuf, LPDWORD pcbNeeded);
+	procGetPrinterW.Call(uintptr(handle), 2, 0, 0, uintptr(unsafe.Pointer(&needed)))
+	if needed == 0 {
+		return nil, fmt.Errorf("GetPrinterW returned no data")
+	}
+
+	buffer := make([]byte, needed)
+	r1, _, e1 := procGetPrinterW.Call(
+		uintptr(handle),
+		2,
+		uintptr(unsafe.Pointer(&buffer[0])),
+		uintptr(needed),
+		uintptr(unsafe.Pointer(&needed)),
+	)
+	if r1 == 0 {
+		return nil, fmt.Errorf("GetPrinterW failed: %v", e1)
+	}
+	return (*PRINTER_INFO_2)(unsafe.Pointer(&buffer[0])), nil
+}
+
 func (w *WindowsPrinter) Close() error {
 	if w.handle != 0 {
 		procClosePrinter.Call(uintptr(w.handle))
@@ -131,10 +186,35 @@ type DOC_INFO_1 struct {
 	pDatatype   *uint16
 }
 
-type PRINTER_INFO_4 struct {
-	pPrinterName *uint16
-	pServerName  *uint16
-	Attributes   uint32
+// PRINTER_INFO_2W as returned by GetPrinterW and EnumPrintersW level 2.
+type PRINTER_INFO_2 struct {
+	pServerName         *uint16
+	pPrinterName        *uint16
+	pShareName          *uint16
+	pPortName           *uint16
+	pDriverName         *uint16
+	pComment            *uint16
+	pLocation           *uint16
+	pDevMode            uintptr
+	pSepFile            *uint16
+	pPrintProcessor     *uint16
+	pDatatype           *uint16
+	pParameters         *uint16
+	pSecurityDescriptor uintptr
+	Attributes          uint32
+	Priority            uint32
+	DefaultPriority     uint32
+	StartTime           uint32
+	UntilTime           uint32
+	Status              uint32
+	cJobs               uint32
+	AveragePPM          uint32
+}
+
+// online reports whether the spooler considers the printer reachable.
+func (info *PRINTER_INFO_2) online() bool {
+	return info.Status&(PRINTER_STATUS_OFFLINE|PRINTER_STATUS_NOT_AVAILABLE) == 0 &&
+		info.Attributes&PRINTER_ATTRIBUTE_WORK_OFFLINE == 0
 }
 
 const (
@@ -142,16 +222,31 @@ const (
 	PRINTER_ENUM_CONNECTIONS = 0x00000004
 )
 
-// FindWindowsPrinters enumerates all local and network printers.
+// PRINTER_INFO_2 Status flags
+const (
+	PRINTER_STATUS_ERROR             = 0x00000002
+	PRINTER_STATUS_PAPER_JAM         = 0x00000008
+	PRINTER_STATUS_PAPER_OUT         = 0x00000010
+	PRINTER_STATUS_PAPER_PROBLEM     = 0x00000040
+	PRINTER_STATUS_OFFLINE           = 0x00000080
+	PRINTER_STATUS_NOT_AVAILABLE     = 0x00001000
+	PRINTER_STATUS_USER_INTERVENTION = 0x00100000
+	PRINTER_STATUS_DOOR_OPEN         = 0x00400000
+
+	PRINTER_ATTRIBUTE_WORK_OFFLINE = 0x00000400
+)
+
+// FindWindowsPrinters enumerates all local and network printers, marking
+// the system default and those the spooler reports offline.
 func FindWindowsPrinters() ([]PrinterInfo, error) {
 	flags := uintptr(PRINTER_ENUM_LOCAL | PRINTER_ENUM_CONNECTIONS)
 	var needed, returned uint32
 
-	// First call to get size
+	// First call to get size. Level 2 includes status and attributes.
 	procEnumPrintersW.Call(
 		flags,
 		0,
-		4, // Level 4 is usually safe and fast
+		2,
 		0,
 		0,
 		uintptr(unsafe.Pointer(&needed)),
@@ -166,7 +261,7 @@ func FindWindowsPrinters() ([]PrinterInfo, error) {
 	r1, _, e1 := procEnumPrintersW.Call(
 		flags,
 		0,
-		4,
+		2,
 		uintptr(unsafe.Pointer(&buffer[0])),
 		uintptr(needed),
 		uintptr(unsafe.Pointer(&needed)),
@@ -177,26 +272,24 @@ func FindWindowsPrinters() ([]PrinterInfo, error) {
 		return nil, fmt.Errorf("EnumPrintersW failed: %v", e1)
 	}
 
-	var printers []PrinterInfo
-	for i := 0; i < int(returned); i++ {
-		// Calculate offset of the i-th struct
-		// In C: pPrinterEnum + i
-		// In Go, we need unsafe pointer arithmetic
-		// Size of PRINTER_INFO_4 is roughly 2 pointers + 1 uint32 = 16 (on 64-bit) or 12 (32-bit).
-		// We should rely on unsafe.Sizeof
-		
-		// Wait, manual parsing is risky. Let's cast to slice of struct if possible, but they contain pointers.
-		// It's safer to iterate via unsafe.Pointer
-		
-		// PRINTER_INFO_4W: pPrinterName (ptr), pServerName (ptr), Attributes (uint32)
-		// On 64-bit: 8 + 8 + 4 = 20 bytes + padding = 24 bytes?
-		// We'll trust unsafe.Sizeof(PRINTER_INFO_4{}) to be correct for the arch.
+	// The buffer holds an array of returned structs followed by the
+	// strings they point to. PRINTER_INFO_2 is pointers followed by DWORDs,
+	// which Go lays out like C on both 386 (84 bytes) and amd64 (136 bytes),
+	// so unsafe.Sizeof matches the stride.
+	size := unsafe.Sizeof(PRINTER_INFO_2{})
+	if uintptr(returned)*size > uintptr(len(buffer)) {
+		return nil, fmt.Errorf("EnumPrintersW returned %d printers, more than fit in %d bytes", returned, len(buffer))
 	}
-	
-	// Re-implement loop using unsafe to be correct
-	pInfos := (*[1024]PRINTER_INFO_4)(unsafe.Pointer(&buffer[0]))[:returned:returned]
-	
-	for _, info := range pInfos {
+	if returned == 0 {
+		return []PrinterInfo{}, nil
+	}
+	pInfos := unsafe.Slice((*PRINTER_INFO_2)(unsafe.Pointer(&buffer[0])), returned)
+
+	defaultName := defaultPrinterName()
+
+	var printers []PrinterInfo
+	for i := range pInfos {
+		info := &pInfos[i]
 		name := windows.UTF16PtrToString(info.pPrinterName)
 		log.Printf("Found printer: %s", name)
 		// Add to list
@@ -207,8 +300,29 @@ func FindWindowsPrinters() ([]PrinterInfo, error) {
 			Product:      name,
 			IsPrinter:    true,
 			DeviceType:   "Windows",
+			IsDefault:    name == defaultName,
+			Offline:      !info.online(),
 		})
 	}
 
 	return printers, nil
 }
+
+// defaultPrinterName returns the name of the user's default printer, or ""
+// if none is set.
+func defaultPrinterName() string {
+	var size uint32
+
+	// BOOL GetDefaultPrinterW(LPWSTR pszBuffer, LPDWORD pcchBuffer);
+	procGetDefaultPrinterW.Call(0, uintptr(unsafe.Pointer(&size)))
+	if size == 0 {
+		return ""
+	}
+
+	buf := make([]uint16, size)
+	r1, _, _ := procGetDefaultPrinterW.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if r1 == 0 {
+		return ""
+	}
+	return windows.UTF16ToString(buf)
+}
@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// scanWorkers bounds the number of concurrent dials in ScanNetworkPrinters.
+const scanWorkers = 64
+
+// maxScanHosts limits how large a network ScanNetworkPrinters will sweep.
+const maxScanHosts = 1 << 16
+
+// ScanNetworkPrinters dials port 9100 on every host in cidr and returns the
+// ones that accept a connection. timeout applies to each dial.
+func ScanNetworkPrinters(cidr string, timeout time.Duration) ([]PrinterInfo, error) {
+	hosts, err := scanHosts(cidr)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = 500 * time.Millisecond
+	}
+
+	log.Printf("[Discovery] Scanning %d hosts in %s for port 9100", len(hosts), cidr)
+
+	jobs := make(chan net.IP)
+	var (
+		mu    sync.Mutex
+		found []net.IP
+		wg    sync.WaitGroup
+	)
+
+	workers := scanWorkers
+	if len(hosts) < workers {
+		workers = len(hosts)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range jobs {
+				conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip.String(), "9100"), timeout)
+				if err != nil {
+					continue
+				}
+				conn.Close()
+
+				mu.Lock()
+				found = append(found, ip)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, ip := range hosts {
+		jobs <- ip
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool {
+		return binary.BigEndian.Uint32(found[i]) < binary.BigEndian.Uint32(found[j])
+	})
+
+	printers := make([]PrinterInfo, 0, len(found))
+	for _, ip := range found {
+		log.Printf("[Discovery] Port 9100 open on %s", ip)
+		printers = append(printers, PrinterInfo{
+			Product:    ip.String(),
+			IsPrinter:  true,
+			DeviceType: "Network",
+		})
+	}
+
+	return printers, nil
+}
+
+// scanHosts expands an IPv4 CIDR into its host addresses, skipping the
+// network and broadcast addresses where they exist.
+func scanHosts(cidr string) ([]net.IP, error) {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %v", cidr, err)
+	}
+
+	base := ipnet.IP.To4()
+	if base == nil {
+		return nil, fmt.Errorf("only IPv4 networks can be scanned: %s", cidr)
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	size := uint64(1) << uint(bits-ones)
+	if size > maxScanHosts {
+		return nil, fmt.Errorf("network %s is too large to scan (max /16)", cidr)
+	}
+
+	start := binary.BigEndian.Uint32(base)
+	first, last := uint64(0), size-1
+	if size > 2 {
+		first, last = 1, size-2
+	}
+
+	hosts := make([]net.IP, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, start+uint32(i))
+		hosts = append(hosts, ip)
+	}
+
+	return hosts, nil
+}
+
+// LocalSubnets returns the IPv4 networks of the machine's active,
+// non-loopback interfaces, for use as default scan targets.
+func LocalSubnets() []string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	var subnets []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || ipnet.IP.To4() == nil || ipnet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			network := &net.IPNet{IP: ipnet.IP.Mask(ipnet.Mask), Mask: ipnet.Mask}
+			subnets = append(subnets, network.String())
+		}
+	}
+
+	return subnets
+}
@@ -4,13 +4,21 @@
 package adapter
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/google/gousb"
 )
 
+// usbSupported reports whether this build can open USB printers.
+const usbSupported = true
+
+// usbReadTimeout bounds how long Read waits for the printer to answer.
+const usbReadTimeout = 500 * time.Millisecond
+
 // USBAdapter communicates with USB receipt printers.
 type USBAdapter struct {
 	mu        sync.Mutex
@@ -21,6 +29,7 @@ type USBAdapter struct {
 	inEP      *gousb.InEndpoint
 	done      func()
 	open      bool
+	claimed   USBID // Device registered as in use while open
 	VendorID  uint16
 	ProductID uint16
 }
@@ -136,6 +145,12 @@ func (u *USBAdapter) Open() error {
 		return fmt.Errorf("no OUT endpoint found")
 	}
 
+	// Let discovery know not to open this device while we print
+	u.claimed = USBID{uint16(u.device.Desc.Vendor), uint16(u.device.Desc.Product)}
+	mfr, _ := u.device.Manufacturer()
+	prod, _ := u.device.Product()
+	claimUSB(u.claimed, mfr, prod)
+
 	u.open = true
 	return nil
 }
@@ -153,7 +168,8 @@ func (u *USBAdapter) Write(data []byte) error {
 	return err
 }
 
-// Read reads data from the printer.
+// Read reads data from the printer. It returns ErrReadTimeout if the
+// printer sends nothing within usbReadTimeout.
 func (u *USBAdapter) Read() ([]byte, error) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
@@ -162,14 +178,26 @@ func (u *USBAdapter) Read() ([]byte, error) {
 		return nil, nil
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), usbReadTimeout)
+	defer cancel()
 	buf := make([]byte, 64)
-	n, err := u.inEP.Read(buf)
+	n, err := u.inEP.ReadContext(ctx, buf)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrReadTimeout
+		}
 		return nil, err
 	}
 	return buf[:n], nil
 }
 
+// CanRead returns true if the printer exposes an IN endpoint for status.
+func (u *USBAdapter) CanRead() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.inEP != nil
+}
+
 // Close closes the USB connection.
 func (u *USBAdapter) Close() error {
 	u.mu.Lock()
@@ -188,6 +216,7 @@ func (u *USBAdapter) Close() error {
 	if u.ctx != nil {
 		u.ctx.Close()
 	}
+	releaseUSB(u.claimed)
 
 	u.open = false
 	return nil
@@ -202,18 +231,83 @@ func (u *USBAdapter) IsOpen() bool {
 
 // FindUSBPrinters returns a list of connected USB devices.
 func FindUSBPrinters() ([]PrinterInfo, error) {
+	return FindUSBPrintersFiltered(false)
+}
+
+// FindUSBPrintersFiltered returns connected USB devices; with printersOnly,
+// only those with a printer-class interface. Filtered-out devices are not
+// opened to read their strings.
+func FindUSBPrintersFiltered(printersOnly bool) ([]PrinterInfo, error) {
+	return ScanUSBDevices(USBScanOptions{PrintersOnly: printersOnly})
+}
+
+// ScanUSBDevices returns connected USB devices as selected by opts. Each
+// device is briefly opened to read its manufacturer and product strings,
+// except devices in use, which report the strings last read from them.
+//
+// libusb calls can't be interrupted, so the scan runs in the background.
+// If opts.Context is done first, the devices found so far are returned
+// with an error wrapping ErrDiscoveryIncomplete, and the scan stops at the
+// next device.
+func ScanUSBDevices(opts USBScanOptions) ([]PrinterInfo, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	scan := &usbScan{}
+	done := make(chan error, 1)
+	go func() {
+		done <- scan.run(ctx, opts)
+	}()
+
+	select {
+	case err := <-done:
+		return scan.result(), err
+	case <-ctx.Done():
+		devices := scan.result()
+		log.Printf("[USB] Scan interrupted, returning %d devices found so far", len(devices))
+		return devices, fmt.Errorf("%w: %v", ErrDiscoveryIncomplete, ctx.Err())
+	}
+}
+
+// usbScan holds the devices found by a running scan.
+type usbScan struct {
+	mu      sync.Mutex
+	devices []PrinterInfo
+}
+
+// result returns a copy of the devices found so far.
+func (s *usbScan) result() []PrinterInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]PrinterInfo(nil), s.devices...)
+}
+
+func (s *usbScan) setStrings(i int, manufacturer, product string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[i].Manufacturer = manufacturer
+	s.devices[i].Product = product
+}
+
+func (s *usbScan) run(ctx context.Context, opts USBScanOptions) error {
 	log.Println("[USB] Starting USB device scan...")
-	ctx := gousb.NewContext()
-	defer ctx.Close()
+	usb := gousb.NewContext()
+	defer usb.Close()
 
 	var devices []PrinterInfo
 
 	// Collect device descriptors in the callback - we return false to avoid
 	// having gousb try to open every device (which fails for system devices)
-	_, _ = ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+	_, _ = usb.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+
 		vid := uint16(desc.Vendor)
 		pid := uint16(desc.Product)
-		
+
 		// Check if device has printer class interface
 		isPrinter := false
 		for _, cfg := range desc.Configs {
@@ -232,49 +326,64 @@ func FindUSBPrinters() ([]PrinterInfo, error) {
 				break
 			}
 		}
-		
+
 		log.Printf("[USB] Found device: VID=%04X PID=%04X IsPrinter=%v", vid, pid, isPrinter)
-		
+		if opts.PrintersOnly && !isPrinter {
+			return false
+		}
+
 		info := PrinterInfo{
 			VendorID:  vid,
 			ProductID: pid,
 			IsPrinter: isPrinter,
 		}
 		devices = append(devices, info)
-		
+
 		// Return false - we don't want to actually open every device
 		// as many will fail with LIBUSB_ERROR_NOT_SUPPORTED
 		return false
 	})
 
 	log.Printf("[USB] Enumerated %d USB devices", len(devices))
+	s.mu.Lock()
+	s.devices = devices
+	s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDiscoveryIncomplete, err)
+	}
 
 	// Now try to get manufacturer/product strings for each device
 	// by opening them individually (with error handling)
-	for i := range devices {
-		dev, err := ctx.OpenDeviceWithVIDPID(
-			gousb.ID(devices[i].VendorID),
-			gousb.ID(devices[i].ProductID),
-		)
+	for i, d := range devices {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrDiscoveryIncomplete, err)
+		}
+
+		id := USBID{d.VendorID, d.ProductID}
+		if usbInUse(id, opts.Skip) {
+			// Opening the active printer could disrupt a print job
+			mfr, prod, _ := knownUSBStrings(id)
+			s.setStrings(i, mfr, prod)
+			log.Printf("[USB] Skipping VID=%04X PID=%04X, device in use", id.VendorID, id.ProductID)
+			continue
+		}
+
+		dev, err := usb.OpenDeviceWithVIDPID(gousb.ID(d.VendorID), gousb.ID(d.ProductID))
 		if err != nil || dev == nil {
 			log.Printf("[USB] Could not open VID=%04X PID=%04X for details (likely system device)",
-				devices[i].VendorID, devices[i].ProductID)
+				d.VendorID, d.ProductID)
 			continue
 		}
 
-		if mfr, err := dev.Manufacturer(); err == nil {
-			devices[i].Manufacturer = mfr
-		}
-		if prod, err := dev.Product(); err == nil {
-			devices[i].Product = prod
-		}
-		log.Printf("[USB] Device details: VID=%04X PID=%04X Mfr=%q Product=%q IsPrinter=%v",
-			devices[i].VendorID, devices[i].ProductID, devices[i].Manufacturer, devices[i].Product, devices[i].IsPrinter)
+		mfr, _ := dev.Manufacturer()
+		prod, _ := dev.Product()
 		dev.Close()
+		s.setStrings(i, mfr, prod)
+		rememberUSBStrings(id, mfr, prod)
+		log.Printf("[USB] Device details: VID=%04X PID=%04X Mfr=%q Product=%q IsPrinter=%v",
+			d.VendorID, d.ProductID, mfr, prod, d.IsPrinter)
 	}
 
 	log.Printf("[USB] Returning %d devices", len(devices))
-	return devices, nil
+	return nil
 }
-
-
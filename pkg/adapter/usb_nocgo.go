@@ -7,6 +7,9 @@ import (
 	"fmt"
 )
 
+// usbSupported reports whether this build can open USB printers.
+const usbSupported = false
+
 // USBAdapter stub for non-CGO builds (Windows cross-compile)
 // USB support requires native build with CGO enabled
 type USBAdapter struct {
@@ -43,5 +46,15 @@ func (u *USBAdapter) IsOpen() bool {
 
 // FindUSBPrinters stub - returns empty list on non-CGO builds
 func FindUSBPrinters() ([]PrinterInfo, error) {
+	return FindUSBPrintersFiltered(false)
+}
+
+// FindUSBPrintersFiltered stub - returns empty list on non-CGO builds
+func FindUSBPrintersFiltered(printersOnly bool) ([]PrinterInfo, error) {
+	return ScanUSBDevices(USBScanOptions{PrintersOnly: printersOnly})
+}
+
+// ScanUSBDevices stub - returns empty list on non-CGO builds
+func ScanUSBDevices(opts USBScanOptions) ([]PrinterInfo, error) {
 	return nil, fmt.Errorf("USB printer discovery not available: requires native build with CGO")
 }
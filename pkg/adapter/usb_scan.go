@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// USBID identifies a USB device by vendor and product ID.
+type USBID struct {
+	VendorID  uint16
+	ProductID uint16
+}
+
+// USBScanOptions controls ScanUSBDevices.
+type USBScanOptions struct {
+	// PrintersOnly leaves out devices without a printer-class interface.
+	PrintersOnly bool
+
+	// Skip lists devices that must not be opened to read their strings,
+	// such as the printer currently in use. Devices held open by a
+	// USBAdapter are always skipped.
+	Skip []USBID
+
+	// Context bounds the scan; nil means no limit.
+	Context context.Context
+}
+
+// ErrDiscoveryIncomplete is wrapped by discovery errors when the context
+// ended before the scan finished. The devices found so far are returned
+// along with it.
+var ErrDiscoveryIncomplete = errors.New("discovery interrupted, results are partial")
+
+// Devices opened by USBAdapter, and the strings read from devices so far.
+// Discovery uses these to avoid opening a printer in the middle of a job.
+var (
+	usbMu      sync.Mutex
+	usbClaimed = map[USBID]int{}
+	usbStrings = map[USBID][2]string{} // Manufacturer, Product
+)
+
+func claimUSB(id USBID, manufacturer, product string) {
+	usbMu.Lock()
+	defer usbMu.Unlock()
+	usbClaimed[id]++
+	if manufacturer != "" || product != "" {
+		usbStrings[id] = [2]string{manufacturer, product}
+	}
+}
+
+func releaseUSB(id USBID) {
+	usbMu.Lock()
+	defer usbMu.Unlock()
+	if usbClaimed[id] <= 1 {
+		delete(usbClaimed, id)
+	} else {
+		usbClaimed[id]--
+	}
+}
+
+// usbInUse reports whether id is held open or listed in skip.
+func usbInUse(id USBID, skip []USBID) bool {
+	for _, s := range skip {
+		if s == id {
+			return true
+		}
+	}
+	usbMu.Lock()
+	defer usbMu.Unlock()
+	return usbClaimed[id] > 0
+}
+
+// knownUSBStrings returns the manufacturer and product strings last read
+// from id, if any.
+func knownUSBStrings(id USBID) (string, string, bool) {
+	usbMu.Lock()
+	defer usbMu.Unlock()
+	s, ok := usbStrings[id]
+	return s[0], s[1], ok
+}
+
+func rememberUSBStrings(id USBID, manufacturer, product string) {
+	usbMu.Lock()
+	defer usbMu.Unlock()
+	usbStrings[id] = [2]string{manufacturer, product}
+}
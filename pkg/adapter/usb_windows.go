@@ -1,6 +1,7 @@
 package adapter
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -67,6 +68,13 @@ type USBDeviceInfo struct {
 
 // FindAllUSBDevices enumerates all USB devices using Windows SetupAPI
 func FindAllUSBDevices() ([]USBDeviceInfo, error) {
+	return FindAllUSBDevicesContext(context.Background())
+}
+
+// FindAllUSBDevicesContext is FindAllUSBDevices, stopping between devices
+// once ctx is done. The devices read so far are then returned with an
+// error wrapping ErrDiscoveryIncomplete.
+func FindAllUSBDevicesContext(ctx context.Context) ([]USBDeviceInfo, error) {
 	// Get device info set for all present devices
 	hDevInfo, _, err := procSetupDiGetClassDevsW.Call(
 		0, // No class GUID - enumerate all
@@ -85,6 +93,10 @@ func FindAllUSBDevices() ([]USBDeviceInfo, error) {
 	devInfoData.CbSize = uint32(unsafe.Sizeof(devInfoData))
 
 	for i := uint32(0); ; i++ {
+		if err := ctx.Err(); err != nil {
+			return dedupeUSBDevices(devices), fmt.Errorf("%w: %v", ErrDiscoveryIncomplete, err)
+		}
+
 		r1, _, _ := procSetupDiEnumDeviceInfo.Call(
 			hDevInfo,
 			uintptr(i),
@@ -125,7 +137,44 @@ func FindAllUSBDevices() ([]USBDeviceInfo, error) {
 		devices = append(devices, device)
 	}
 
-	return devices, nil
+	return dedupeUSBDevices(devices), nil
+}
+
+// dedupeUSBDevices keeps one entry per VID/PID. Composite devices show up
+// once per interface; the entry with class "Printer" wins, then the one
+// with the most descriptive strings. Order of first appearance is kept.
+func dedupeUSBDevices(devices []USBDeviceInfo) []USBDeviceInfo {
+	index := make(map[[2]uint16]int)
+	var result []USBDeviceInfo
+
+	for _, d := range devices {
+		key := [2]uint16{d.VendorID, d.ProductID}
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(result)
+			result = append(result, d)
+			continue
+		}
+
+		isPrinter := result[i].IsPrinter || d.IsPrinter
+		if deviceRank(d) > deviceRank(result[i]) {
+			result[i] = d
+		}
+		result[i].IsPrinter = isPrinter
+	}
+	return result
+}
+
+// deviceRank scores how useful an entry is to show for its device.
+func deviceRank(d USBDeviceInfo) int {
+	rank := len(d.Description) + len(d.Manufacturer)
+	if d.IsPrinter {
+		rank += 1000
+	}
+	if d.DeviceClass == "Printer" {
+		rank += 2000
+	}
+	return rank
 }
 
 func getDeviceRegistryProperty(hDevInfo uintptr, devInfoData *SP_DEVINFO_DATA, property uint32) string {
@@ -0,0 +1,46 @@
+package adapter
+
+import "testing"
+
+func TestDedupeUSBDevices(t *testing.T) {
+	devices := []USBDeviceInfo{
+		{VendorID: 0x04b8, ProductID: 0x0202, Description: "USB Composite Device", DeviceClass: "USB", InstanceID: `USB\VID_04B8&PID_0202\1`},
+		{VendorID: 0x046d, ProductID: 0xc52b, Description: "USB Receiver", DeviceClass: "USB", InstanceID: `USB\VID_046D&PID_C52B\1`},
+		{VendorID: 0x04b8, ProductID: 0x0202, Description: "USB Printing Support", Manufacturer: "Microsoft", DeviceClass: "USBDevice", InstanceID: `USB\VID_04B8&PID_0202&MI_00\2`},
+		{VendorID: 0x04b8, ProductID: 0x0202, Description: "EPSON TM-T20", Manufacturer: "EPSON", DeviceClass: "Printer", InstanceID: `USBPRINT\EPSONTM-T20\3`, IsPrinter: true},
+		{VendorID: 0x04b8, ProductID: 0x0202, Description: "USB Input Device with a much longer description", DeviceClass: "HIDClass", InstanceID: `USB\VID_04B8&PID_0202&MI_01\4`},
+	}
+
+	got := dedupeUSBDevices(devices)
+	if len(got) != 2 {
+		t.Fatalf("got %d devices, want 2: %+v", len(got), got)
+	}
+
+	// First appearance order is kept
+	printer, receiver := got[0], got[1]
+	if printer.VendorID != 0x04b8 || receiver.VendorID != 0x046d {
+		t.Fatalf("devices out of order: %+v", got)
+	}
+	if printer.DeviceClass != "Printer" || printer.Description != "EPSON TM-T20" {
+		t.Errorf("kept %+v, want the Printer class entry", printer)
+	}
+	if !printer.IsPrinter {
+		t.Error("IsPrinter lost")
+	}
+}
+
+func TestDedupeUSBDevicesRichestDescription(t *testing.T) {
+	devices := []USBDeviceInfo{
+		{VendorID: 0x0416, ProductID: 0x5011, Description: "USB Device", InstanceID: "a"},
+		{VendorID: 0x0416, ProductID: 0x5011, Description: "POS-58 Receipt Printer", Manufacturer: "Winbond", InstanceID: "b", IsPrinter: true},
+		{VendorID: 0x0416, ProductID: 0x5011, Description: "USB Device", InstanceID: "c"},
+	}
+
+	got := dedupeUSBDevices(devices)
+	if len(got) != 1 {
+		t.Fatalf("got %d devices, want 1: %+v", len(got), got)
+	}
+	if got[0].InstanceID != "b" {
+		t.Errorf("kept instance %q, want b with the richest description", got[0].InstanceID)
+	}
+}
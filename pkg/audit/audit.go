@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the log size at which it is rotated, if not configured.
+const DefaultMaxSize = 5 << 20
+
+// Entry records one print job sent to the printer.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Printer  string    `json:"printer,omitempty"`
+	Endpoint string    `json:"endpoint"`
+	JobID    string    `json:"job_id,omitempty"`
+	Bytes    int       `json:"bytes"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Log appends entries as JSON lines to a file. When the file would grow
+// past maxSize it is renamed to path+".1", replacing the previous one.
+type Log struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+// Open opens or creates the audit log at path. maxSize <= 0 uses
+// DefaultMaxSize.
+func Open(path string, maxSize int64) (*Log, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	l := &Log{path: path, maxSize: maxSize}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Record appends an entry, rotating the file first if needed.
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// rotate moves the current file to path+".1" and starts a new one.
+func (l *Log) rotate() error {
+	l.file.Close()
+	l.file = nil
+
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return l.open()
+}
+
+// Recent returns up to n of the latest entries, newest first.
+func (l *Log) Recent(n int) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := readEntries(l.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) < n {
+		// Fill up from the rotated file
+		older, err := readEntries(l.path + ".1")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		entries = append(older, entries...)
+	}
+
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// readEntries parses a log file, skipping malformed lines.
+func readEntries(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewClient returns an HTTP client for calling the local PrintBridge
+// service. It sends the configured auth token, if any, with every request.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: authTransport{},
+	}
+}
+
+// authTransport adds the Authorization header from the current config.
+// The config is read per request so a changed token takes effect at once.
+type authTransport struct{}
+
+func (authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg, err := Load()
+	if err == nil && cfg.AuthToken != "" && req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+cfg.AuthToken)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// ServiceURL returns the base URL of the local service, e.g.
+// "http://127.0.0.1:9100", from the current config. The defaults are used
+// if the config can't be read.
+func ServiceURL() string {
+	cfg, err := Load()
+	if err != nil {
+		cfg = DefaultConfig()
+	}
+	return cfg.ServiceURL()
+}
@@ -2,61 +2,262 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Config represents the application configuration.
+//
+// Host is the address the HTTP API binds to. It defaults to the loopback
+// address 127.0.0.1 so the service is only reachable from this machine;
+// set it to "0.0.0.0" to accept print jobs from the network.
 type Config struct {
+	// Version is the shape of the file, see CurrentVersion.
+	Version int `json:"config_version"`
+
 	Host    string `json:"host"`
 	Port    int    `json:"port"`
-	Adapter string `json:"adapter"` // usb, windows, network, serial, console, auto
+	Adapter string `json:"adapter"` // usb, windows, network, serial, bluetooth, console, auto
+
+	PaperWidthMM int `json:"paper_width_mm"` // 58 or 80
+
+	// Timezone is the IANA zone (e.g. "Europe/Istanbul") order times are
+	// printed in. Empty uses the system's local time.
+	Timezone string `json:"timezone"`
+
+	// Language selects the labels on template receipts ("tr" or "en").
+	Language string `json:"language"`
+
+	// Currency (ISO 4217 code, e.g. "TRY") and Locale (e.g. "tr-TR") set
+	// how amounts are printed. Empty keeps "12.50 TL" on template orders
+	// and "$12.50" on receipts.
+	Currency string `json:"currency"`
+	Locale   string `json:"locale"`
+
+	// AuthToken, when set, must be sent as "Authorization: Bearer <token>"
+	// on every endpoint except /health.
+	AuthToken string `json:"auth_token"`
+
+	// AllowedOrigins lists the origins allowed by CORS; "*" allows any.
+	AllowedOrigins []string `json:"allowed_origins"`
 
 	AutoStart struct {
 		Enabled          bool `json:"enabled"`
 		InstallOnStartup bool `json:"install_on_startup"`
 	} `json:"autostart"`
 
-	USB struct {
-		VendorID  uint16 `json:"vendor_id"`
-		ProductID uint16 `json:"product_id"`
-	} `json:"usb"`
+	USB USBConfig `json:"usb"`
+
+	Windows   WindowsConfig   `json:"windows"`
+	Network   NetworkConfig   `json:"network"`
+	Serial    SerialConfig    `json:"serial"`
+	Bluetooth BluetoothConfig `json:"bluetooth"`
+
+	// Printers are named printers in addition to the default one set up by
+	// the fields above, e.g. a kitchen printer next to the receipt printer.
+	// Print requests pick one with ?printer=<name> or a "printer" field.
+	Printers map[string]PrinterConfig `json:"printers,omitempty"`
+
+	Queue struct {
+		MaxAttempts int `json:"max_attempts"` // Tries per print job before it is marked failed
+	} `json:"queue"`
+
+	Receipt struct {
+		Header []string `json:"header"`  // Store name, address, ... (first line bold)
+		Footer []string `json:"footer"`  // e.g. "Thank you for your visit!"
+		Logo   string   `json:"logo"`    // Image path, absolute or relative to templates dir
+		NVLogo int      `json:"nv_logo"` // Index of a logo stored with /logo/nv, printed instead of logo; 0 disables
+	} `json:"receipt"`
+
+	AuditLog struct {
+		Enabled   bool `json:"enabled"`     // Record every print job to audit.log
+		MaxSizeKB int  `json:"max_size_kb"` // Size at which the log is rotated
+	} `json:"audit_log"`
+
+	Metrics struct {
+		Enabled bool `json:"enabled"` // Serve Prometheus metrics on /metrics
+	} `json:"metrics"`
+
+	Image struct {
+		Threshold uint32 `json:"threshold"` // Luminance cutoff 0-65535 (default 32768)
+	} `json:"image"`
+
+	Discovery struct {
+		CacheTTLSeconds int `json:"cache_ttl_seconds"` // Reuse printer scans for /status this long (0 = always rescan)
+	} `json:"discovery"`
+
+	Update struct {
+		Channel string `json:"channel"` // "stable" (default) or "beta" to include pre-releases
+	} `json:"update"`
+
+	Text struct {
+		Font        string `json:"font"`         // Default font: "a", "b" or "c" (empty keeps the printer's)
+		LineSpacing int    `json:"line_spacing"` // Default line spacing in dots, 0 keeps the printer's
+	} `json:"text"`
+
+	Density struct {
+		Level   int    `json:"level"`   // -6 (lightest) to 6 (darkest), 0 keeps the printer's
+		Speed   int    `json:"speed"`   // 1 (slowest) to 9 (fastest), 0 keeps the printer's
+		Command string `json:"command"` // "gs_k" (default, Epson) or "dc2" (generic printers)
+	} `json:"density"`
+
+	Beep struct {
+		Variant string `json:"variant"` // Buzzer command: "esc_b" (default) or "esc_paren_a" for Epson TM
+	} `json:"beep"`
+}
+
+// DefaultPrinterName is the name of the printer configured by the
+// top-level adapter settings.
+const DefaultPrinterName = "default"
+
+// PrinterConfig selects the adapter and connection settings of a named
+// printer. A zero PaperWidthMM uses the top-level paper width.
+type PrinterConfig struct {
+	Adapter      string          `json:"adapter"`
+	PaperWidthMM int             `json:"paper_width_mm,omitempty"`
+	USB          USBConfig       `json:"usb"`
+	Windows      WindowsConfig   `json:"windows"`
+	Network      NetworkConfig   `json:"network"`
+	Serial       SerialConfig    `json:"serial"`
+	Bluetooth    BluetoothConfig `json:"bluetooth"`
+}
+
+// DefaultPrinter returns the top-level adapter settings as a PrinterConfig.
+func (c *Config) DefaultPrinter() PrinterConfig {
+	return PrinterConfig{
+		Adapter:      c.Adapter,
+		PaperWidthMM: c.PaperWidthMM,
+		USB:          c.USB,
+		Windows:      c.Windows,
+		Network:      c.Network,
+		Serial:       c.Serial,
+		Bluetooth:    c.Bluetooth,
+	}
+}
+
+// WindowsConfig selects a printer installed in the Windows spooler.
+type WindowsConfig struct {
+	PrinterName string `json:"printer_name"`
+}
+
+// NetworkConfig addresses a raw TCP printer.
+type NetworkConfig struct {
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
 
-	Windows struct {
-		PrinterName string `json:"printer_name"`
-	} `json:"windows"`
+// SerialConfig selects a serial port printer.
+type SerialConfig struct {
+	Port     string `json:"port"`
+	BaudRate int    `json:"baud_rate"`
+}
 
-	Network struct {
-		Address string `json:"address"`
-		Port    int    `json:"port"`
-	} `json:"network"`
+// BluetoothConfig selects a Bluetooth SPP printer.
+type BluetoothConfig struct {
+	Address string `json:"address"` // MAC address, e.g. 00:11:22:33:44:55
+	Channel int    `json:"channel"` // RFCOMM channel (default 1)
+}
 
-	Serial struct {
-		Port     string `json:"port"`
-		BaudRate int    `json:"baud_rate"`
-	} `json:"serial"`
+// USBConfig selects a USB printer by vendor and product ID. In JSON the IDs
+// may be numbers or hex strings as shown by Device Manager ("0x04b8", "04b8").
+type USBConfig struct {
+	VendorID  uint16 `json:"vendor_id"`
+	ProductID uint16 `json:"product_id"`
 }
 
+// UnmarshalJSON accepts vendor_id and product_id as numbers or hex strings.
+func (u *USBConfig) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		VendorID  interface{} `json:"vendor_id"`
+		ProductID interface{} `json:"product_id"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.VendorID != nil {
+		id, err := usbIDValue(raw.VendorID)
+		if err != nil {
+			return fmt.Errorf("usb.vendor_id: %v", err)
+		}
+		u.VendorID = id
+	}
+	if raw.ProductID != nil {
+		id, err := usbIDValue(raw.ProductID)
+		if err != nil {
+			return fmt.Errorf("usb.product_id: %v", err)
+		}
+		u.ProductID = id
+	}
+	return nil
+}
+
+// ParseUSBID parses a hex USB vendor/product ID with or without a 0x prefix.
+func ParseUSBID(s string) (uint16, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	id, err := strconv.ParseUint(s, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hex ID %q", s)
+	}
+	return uint16(id), nil
+}
+
+// DefaultHost is the loopback bind address used when Host is unset or invalid.
+const DefaultHost = "127.0.0.1"
+
 var (
 	configPath string
 	configOnce sync.Once
+
+	// fileMu serializes reading and writing config files within the process
+	fileMu sync.Mutex
 )
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
-	return &Config{
-		Host:    "0.0.0.0",
+	cfg := &Config{
+		Version: CurrentVersion,
+		Host:    DefaultHost,
 		Port:    9100,
 		Adapter: "auto",
+
+		PaperWidthMM: 80,
+		Language:     "tr",
+
+		AllowedOrigins: []string{"*"},
 	}
+	cfg.Queue.MaxAttempts = 5
+	cfg.Image.Threshold = 32768
+	cfg.AuditLog.MaxSizeKB = 5120
+	cfg.Update.Channel = "stable"
+	cfg.Discovery.CacheTTLSeconds = 10
+	cfg.Beep.Variant = "esc_b"
+	cfg.Density.Command = "gs_k"
+	return cfg
 }
 
 // GetConfigDir returns the PrintBridge config directory path.
 // On Windows: %APPDATA%/PrintBridge
 // On Linux/Mac: ~/.config/printbridge
+// When PRINTBRIDGE_CONFIG is set, the directory of that file is used, so
+// the Windows service, which runs as another user, shares the PID file
+// and logs with the user who installed it.
 func GetConfigDir() string {
+	if path := os.Getenv("PRINTBRIDGE_CONFIG"); path != "" {
+		return filepath.Dir(path)
+	}
+
 	if runtime.GOOS == "windows" {
 		appData := os.Getenv("APPDATA")
 		if appData != "" {
@@ -127,8 +328,21 @@ func Load() (*Config, error) {
 	return LoadFrom(GetConfigPath())
 }
 
-// LoadFrom loads configuration from a specific path.
+// LoadFrom loads configuration from a specific path, with environment
+// variable overrides applied (see ApplyEnv).
 func LoadFrom(path string) (*Config, error) {
+	fileMu.Lock()
+	cfg, err := loadFrom(path)
+	fileMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ApplyEnv(cfg)
+	return cfg, nil
+}
+
+func loadFrom(path string) (*Config, error) {
 	config := DefaultConfig()
 
 	data, err := os.ReadFile(path)
@@ -138,7 +352,7 @@ func LoadFrom(path string) (*Config, error) {
 			if err := EnsureConfigDir(); err != nil {
 				return config, nil
 			}
-			if err := SaveTo(path, config); err != nil {
+			if err := saveTo(path, config); err != nil {
 				return config, nil
 			}
 			return config, nil
@@ -146,13 +360,108 @@ func LoadFrom(path string) (*Config, error) {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(data, config); err != nil {
+	migrated, fromVersion, err := migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(migrated, config); err != nil {
 		return nil, err
 	}
 
+	if err := ValidateHost(config.Host); err != nil {
+		log.Printf("[Config] Invalid host %q (%v), using %s", config.Host, err, DefaultHost)
+		config.Host = DefaultHost
+	}
+
+	if fromVersion < CurrentVersion {
+		// Keep the original, then rewrite the file in the current shape
+		backup := fmt.Sprintf("%s.v%d.bak", path, fromVersion)
+		if err := WriteFile(backup, data); err != nil {
+			log.Printf("Warning: Failed to back up config before migrating: %v", err)
+		} else if err := saveTo(path, config); err != nil {
+			log.Printf("Warning: Failed to save migrated config: %v", err)
+		} else {
+			log.Printf("[Config] Migrated %s from version %d to %d (original kept as %s)", path, fromVersion, CurrentVersion, backup)
+		}
+	}
+
 	return config, nil
 }
 
+// CurrentVersion is the version of the config file shape this build
+// writes. Files with an older config_version, or none, are migrated when
+// loaded.
+const CurrentVersion = 1
+
+// migrations[v] upgrades the contents of a version v config file to
+// version v+1. Fields a file still lacks afterwards get their defaults.
+var migrations = []func(raw map[string]interface{}) error{
+	// 0 to 1: unversioned files already have the version 1 shape, so they
+	// only gain the fields they lack and the version
+	func(raw map[string]interface{}) error { return nil },
+}
+
+// migrate upgrades config file contents to CurrentVersion. It returns the
+// upgraded contents and the version the file had; data is returned as is
+// if it is current. Files from a newer version are loaded as they are.
+func migrate(data []byte) ([]byte, int, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, err
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	version := 0
+	if v, ok := raw["config_version"].(float64); ok && v > 0 {
+		version = int(v)
+	}
+	if version >= CurrentVersion {
+		if version > CurrentVersion {
+			log.Printf("Warning: Config version %d is newer than this build supports (%d)", version, CurrentVersion)
+		}
+		return data, version, nil
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, version, fmt.Errorf("failed to migrate config from version %d: %w", v, err)
+		}
+	}
+	raw["config_version"] = CurrentVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, version, err
+	}
+	return migrated, version, nil
+}
+
+// Modify loads the configuration from the default path, applies fn to it
+// and saves the result, holding the lock throughout so concurrent changes
+// within the process don't overwrite each other. Nothing is saved if fn
+// returns an error. Environment variable overrides are not applied, so
+// they are never written to the file.
+func Modify(fn func(*Config) error) (*Config, error) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	path := GetConfigPath()
+	cfg, err := loadFrom(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(cfg); err != nil {
+		return nil, err
+	}
+	if err := saveTo(path, cfg); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	return cfg, nil
+}
+
 // Save saves the configuration to the default path.
 func Save(config *Config) error {
 	return SaveTo(GetConfigPath(), config)
@@ -160,6 +469,12 @@ func Save(config *Config) error {
 
 // SaveTo saves configuration to a specific path.
 func SaveTo(path string, config *Config) error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	return saveTo(path, config)
+}
+
+func saveTo(path string, config *Config) error {
 	if err := EnsureConfigDir(); err != nil {
 		return err
 	}
@@ -169,42 +484,490 @@ func SaveTo(path string, config *Config) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return WriteFile(path, data)
 }
 
-// Update updates a specific field in the config and saves.
-func Update(key string, value interface{}) error {
-	config, err := Load()
+// WriteFile writes data to path with 0644 permissions, atomically: the
+// data goes to a temporary file in the same directory that is then renamed
+// into place, so a process killed mid-write leaves the old file intact
+// instead of a truncated one.
+func WriteFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // Fails harmlessly once renamed
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Adapters lists the accepted values for Config.Adapter.
+var Adapters = []string{"auto", "usb", "windows", "network", "serial", "bluetooth", "console"}
+
+// Languages lists the accepted values for Config.Language, the label sets
+// in printer.Locales.
+var Languages = []string{"en", "tr"}
+
+// Keys lists the dotted keys accepted by Set.
+var Keys = []string{
+	"host", "port", "adapter", "timezone", "language", "currency", "locale",
+	"auth_token", "allowed_origins", "paper_width_mm",
+	"autostart.enabled", "autostart.install_on_startup",
+	"windows.printer_name", "usb.vendor_id", "usb.product_id",
+	"network.address", "network.port", "serial.port", "serial.baud_rate",
+	"bluetooth.address", "bluetooth.channel",
+	"queue.max_attempts",
+	"receipt.header", "receipt.footer", "receipt.logo", "receipt.nv_logo",
+	"metrics.enabled", "audit_log.enabled", "audit_log.max_size_kb",
+	"discovery.cache_ttl_seconds", "update.channel",
+	"text.font", "text.line_spacing",
+	"density.level", "density.speed", "density.command",
+	"beep.variant", "printers", "image.threshold",
+}
+
+// EnvName returns the environment variable that overrides key, e.g.
+// PRINTBRIDGE_NETWORK_ADDRESS for network.address.
+func EnvName(key string) string {
+	return "PRINTBRIDGE_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// ApplyEnv overrides settings in config with the environment variables
+// named by EnvName, so they take precedence over the file and the
+// defaults. Values are parsed as by Set; printers takes a JSON object.
+// Invalid values are logged and ignored.
+func ApplyEnv(config *Config) {
+	for _, key := range EnvOverrides() {
+		var value interface{} = os.Getenv(EnvName(key))
+		if key == "printers" {
+			if err := json.Unmarshal([]byte(value.(string)), &value); err != nil {
+				log.Printf("[Config] Ignoring %s: invalid JSON: %v", EnvName(key), err)
+				continue
+			}
+		}
+		// Set may leave an invalid value behind, so apply it to a copy
+		c := *config
+		if err := Set(&c, key, value); err != nil {
+			log.Printf("[Config] Ignoring %s: %v", EnvName(key), err)
+			continue
+		}
+		*config = c
+	}
+}
+
+// EnvOverrides returns the keys overridden by environment variables.
+func EnvOverrides() []string {
+	var keys []string
+	for _, key := range Keys {
+		if _, ok := os.LookupEnv(EnvName(key)); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// KeyError reports a config key that could not be applied.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnknownKey is returned by Set for keys that don't map to a config field.
+var ErrUnknownKey = errors.New("unknown config key")
+
+// Update updates a specific field in the config and saves.
+func Update(key string, value interface{}) error {
+	_, err := Modify(func(config *Config) error {
+		return Set(config, key, value)
+	})
+	return err
+}
+
+// UpdateUSBDevice saves the USB vendor and product IDs of the selected
+// printer. Selecting a device also sets the adapter to usb.
+func UpdateUSBDevice(vendorID, productID uint16) error {
+	_, err := Modify(func(config *Config) error {
+		config.USB.VendorID = vendorID
+		config.USB.ProductID = productID
+		if vendorID != 0 || productID != 0 {
+			config.Adapter = "usb"
+		}
+		return nil
+	})
+	return err
+}
+
+// Set validates value and assigns it to the field named by the dotted key.
+// Values are expected as decoded from JSON (string, float64, bool); numbers
+// and booleans may also be given as strings, e.g. "9100" or "true".
+// It returns a *KeyError if the key is unknown or the value is invalid, in
+// which case config may be partially modified and should not be saved.
+func Set(config *Config, key string, value interface{}) error {
+	var err error
 
 	switch key {
 	case "host":
-		if v, ok := value.(string); ok {
-			config.Host = v
+		if config.Host, err = stringValue(value); err == nil {
+			err = ValidateHost(config.Host)
 		}
 	case "port":
-		if v, ok := value.(float64); ok {
-			config.Port = int(v)
-		}
+		config.Port, err = intValue(value, 1, 65535)
 	case "adapter":
-		if v, ok := value.(string); ok {
-			config.Adapter = v
+		var v string
+		if v, err = stringValue(value); err == nil {
+			if !isAdapter(v) {
+				err = fmt.Errorf("must be one of %s", strings.Join(Adapters, ", "))
+			} else {
+				config.Adapter = v
+			}
 		}
-	case "windows.printer_name":
-		if v, ok := value.(string); ok {
-			config.Windows.PrinterName = v
+	case "timezone":
+		var v string
+		if v, err = stringValue(value); err == nil {
+			if _, err = time.LoadLocation(v); err == nil {
+				config.Timezone = v
+			}
 		}
-	case "usb.vendor_id":
-		if v, ok := value.(float64); ok {
-			config.USB.VendorID = uint16(v)
+	case "language":
+		var v string
+		if v, err = stringValue(value); err == nil {
+			if !isLanguage(v) {
+				err = fmt.Errorf("must be one of %s", strings.Join(Languages, ", "))
+			} else {
+				config.Language = v
+			}
 		}
+	case "currency":
+		config.Currency, err = stringValue(value)
+	case "locale":
+		config.Locale, err = stringValue(value)
+	case "auth_token":
+		config.AuthToken, err = stringValue(value)
+	case "allowed_origins":
+		config.AllowedOrigins, err = stringListValue(value)
+	case "paper_width_mm":
+		config.PaperWidthMM, err = intValue(value, 58, 80)
+	case "autostart.enabled":
+		config.AutoStart.Enabled, err = boolValue(value)
+	case "autostart.install_on_startup":
+		config.AutoStart.InstallOnStartup, err = boolValue(value)
+	case "windows.printer_name":
+		config.Windows.PrinterName, err = stringValue(value)
+	case "usb.vendor_id":
+		config.USB.VendorID, err = usbIDValue(value)
 	case "usb.product_id":
-		if v, ok := value.(float64); ok {
-			config.USB.ProductID = uint16(v)
+		config.USB.ProductID, err = usbIDValue(value)
+	case "network.address":
+		config.Network.Address, err = stringValue(value)
+	case "network.port":
+		config.Network.Port, err = intValue(value, 1, 65535)
+	case "serial.port":
+		config.Serial.Port, err = stringValue(value)
+	case "serial.baud_rate":
+		config.Serial.BaudRate, err = intValue(value, 1, 4000000)
+	case "bluetooth.address":
+		config.Bluetooth.Address, err = stringValue(value)
+	case "bluetooth.channel":
+		config.Bluetooth.Channel, err = intValue(value, 1, 30)
+	case "queue.max_attempts":
+		config.Queue.MaxAttempts, err = intValue(value, 1, 100)
+	case "receipt.header":
+		config.Receipt.Header, err = linesValue(value)
+	case "receipt.footer":
+		config.Receipt.Footer, err = linesValue(value)
+	case "receipt.logo":
+		config.Receipt.Logo, err = stringValue(value)
+	case "receipt.nv_logo":
+		config.Receipt.NVLogo, err = intValue(value, 0, 99)
+	case "metrics.enabled":
+		config.Metrics.Enabled, err = boolValue(value)
+	case "audit_log.enabled":
+		config.AuditLog.Enabled, err = boolValue(value)
+	case "audit_log.max_size_kb":
+		config.AuditLog.MaxSizeKB, err = intValue(value, 1, 1<<20)
+	case "discovery.cache_ttl_seconds":
+		config.Discovery.CacheTTLSeconds, err = intValue(value, 0, 3600)
+	case "update.channel":
+		var v string
+		if v, err = stringValue(value); err == nil {
+			if v != "stable" && v != "beta" {
+				err = fmt.Errorf("must be stable or beta")
+			} else {
+				config.Update.Channel = v
+			}
+		}
+	case "text.font":
+		var v string
+		if v, err = stringValue(value); err == nil {
+			v = strings.ToLower(v)
+			if v != "" && v != "a" && v != "b" && v != "c" {
+				err = fmt.Errorf("must be a, b or c")
+			} else {
+				config.Text.Font = v
+			}
 		}
+	case "text.line_spacing":
+		config.Text.LineSpacing, err = intValue(value, 0, 255)
+	case "density.level":
+		config.Density.Level, err = intValue(value, -6, 6)
+	case "density.speed":
+		config.Density.Speed, err = intValue(value, 0, 9)
+	case "density.command":
+		var v string
+		if v, err = stringValue(value); err == nil {
+			if v != "gs_k" && v != "dc2" {
+				err = fmt.Errorf("must be gs_k or dc2")
+			} else {
+				config.Density.Command = v
+			}
+		}
+	case "beep.variant":
+		var v string
+		if v, err = stringValue(value); err == nil {
+			if v != "esc_b" && v != "esc_paren_a" {
+				err = fmt.Errorf("must be esc_b or esc_paren_a")
+			} else {
+				config.Beep.Variant = v
+			}
+		}
+	case "printers":
+		config.Printers, err = printersValue(value)
+	case "image.threshold":
+		var v float64
+		if v, err = numberValue(value); err == nil {
+			// Clamp to the 16-bit luminance range
+			if v < 0 {
+				v = 0
+			}
+			if v > 65535 {
+				v = 65535
+			}
+			config.Image.Threshold = uint32(v)
+		}
+	default:
+		err = ErrUnknownKey
 	}
 
-	return Save(config)
+	if err != nil {
+		return &KeyError{Key: key, Err: err}
+	}
+	return nil
+}
+
+// ValidateHost checks that host is an IP address or a plausible hostname.
+func ValidateHost(host string) error {
+	if host == "" {
+		return errors.New("host is empty")
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return nil
+	}
+	if len(host) > 253 {
+		return errors.New("host name is too long")
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return errors.New("not a valid IP address or host name")
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return errors.New("not a valid IP address or host name")
+			}
+		}
+	}
+	return nil
+}
+
+// ServiceURL returns the base URL clients use to reach the service, e.g.
+// "http://127.0.0.1:9100". A wildcard bind address such as "0.0.0.0" is
+// reached through the loopback address.
+func (c *Config) ServiceURL() string {
+	host := strings.Trim(c.Host, "[]")
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = DefaultHost
+	}
+	port := c.Port
+	if port == 0 {
+		port = DefaultConfig().Port
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// IsLoopbackHost reports whether host only accepts local connections.
+func IsLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+func isAdapter(name string) bool {
+	for _, a := range Adapters {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isLanguage(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// printersValue accepts an object of named printer configs.
+func printersValue(value interface{}) (map[string]PrinterConfig, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var printers map[string]PrinterConfig
+	if err := json.Unmarshal(data, &printers); err != nil {
+		return nil, fmt.Errorf("must be an object of printer configs: %v", err)
+	}
+	for name, p := range printers {
+		if err := ValidatePrinter(name, p); err != nil {
+			return nil, err
+		}
+	}
+	return printers, nil
+}
+
+// ValidatePrinter checks a named printer's name and adapter type.
+func ValidatePrinter(name string, p PrinterConfig) error {
+	if name == "" {
+		return errors.New("printer name is empty")
+	}
+	if name == DefaultPrinterName {
+		return fmt.Errorf("printer name %q is reserved for the top-level printer", name)
+	}
+	if !isAdapter(p.Adapter) {
+		return fmt.Errorf("printer %q: adapter must be one of %s", name, strings.Join(Adapters, ", "))
+	}
+	if p.PaperWidthMM != 0 && (p.PaperWidthMM < 58 || p.PaperWidthMM > 80) {
+		return fmt.Errorf("printer %q: paper_width_mm must be between 58 and 80", name)
+	}
+	return nil
+}
+
+func stringValue(value interface{}) (string, error) {
+	v, ok := value.(string)
+	if !ok {
+		return "", errors.New("must be a string")
+	}
+	return v, nil
+}
+
+// stringListValue accepts a JSON array of strings or a comma-separated string.
+func stringListValue(value interface{}) ([]string, error) {
+	var list []string
+	switch v := value.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, errors.New("must be a list of strings")
+			}
+			list = append(list, str)
+		}
+	default:
+		return nil, errors.New("must be a list of strings")
+	}
+	return list, nil
+}
+
+// linesValue accepts a JSON array of strings or a newline-separated string.
+func linesValue(value interface{}) ([]string, error) {
+	if v, ok := value.(string); ok {
+		if v == "" {
+			return nil, nil
+		}
+		return strings.Split(strings.ReplaceAll(v, "\r\n", "\n"), "\n"), nil
+	}
+	return stringListValue(value)
+}
+
+func numberValue(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, errors.New("must be a number")
+}
+
+func boolValue(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1, nil
+		}
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, nil
+		}
+	}
+	return false, errors.New("must be a boolean")
+}
+
+// usbIDValue accepts a USB ID as a number or a hex string.
+func usbIDValue(value interface{}) (uint16, error) {
+	if v, ok := value.(string); ok {
+		return ParseUSBID(v)
+	}
+	v, err := intValue(value, 0, 0xFFFF)
+	return uint16(v), err
+}
+
+// intValue checks that value is a whole number in [min, max].
+func intValue(value interface{}, min, max int) (int, error) {
+	v, err := numberValue(value)
+	if err != nil {
+		return 0, err
+	}
+	if v != math.Trunc(v) {
+		return 0, errors.New("must be a whole number")
+	}
+	if v < float64(min) || v > float64(max) {
+		return 0, fmt.Errorf("must be between %d and %d", min, max)
+	}
+	return int(v), nil
 }
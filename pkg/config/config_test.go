@@ -0,0 +1,343 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testConfigPath is the config file used by the tests. GetConfigPath
+// resolves it once per process, so every test shares it and starts by
+// removing it.
+var testConfigPath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "printbridge-config")
+	if err != nil {
+		panic(err)
+	}
+	testConfigPath = filepath.Join(dir, "config.json")
+	os.Setenv("PRINTBRIDGE_CONFIG", testConfigPath)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// resetConfigFile removes the config file and any backups, so the next
+// Load starts from the defaults.
+func resetConfigFile(t *testing.T) {
+	t.Helper()
+	matches, _ := filepath.Glob(testConfigPath + "*")
+	for _, path := range matches {
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// lookup returns the value at a dotted key in JSON decoded into raw.
+func lookup(raw map[string]interface{}, key string) interface{} {
+	var v interface{} = raw
+	for _, part := range strings.Split(key, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
+
+func TestUpdateRoundTrip(t *testing.T) {
+	tests := []struct {
+		key   string
+		value interface{} // As decoded from a JSON request
+		want  interface{} // As found in the saved file; for printers, the kitchen printer's network settings
+	}{
+		{"host", "0.0.0.0", "0.0.0.0"},
+		{"port", float64(8080), float64(8080)},
+		{"adapter", "network", "network"},
+		{"timezone", "Europe/Istanbul", "Europe/Istanbul"},
+		{"language", "en", "en"},
+		{"currency", "EUR", "EUR"},
+		{"locale", "de-DE", "de-DE"},
+		{"auth_token", "secret", "secret"},
+		{"allowed_origins", "https://a.example, https://b.example", []interface{}{"https://a.example", "https://b.example"}},
+		{"paper_width_mm", "58", float64(58)},
+		{"autostart.enabled", true, true},
+		{"autostart.install_on_startup", "true", true},
+		{"windows.printer_name", "POS-80", "POS-80"},
+		{"usb.vendor_id", "0x04b8", float64(0x04b8)},
+		{"usb.product_id", float64(0x0202), float64(0x0202)},
+		{"network.address", "192.168.1.50", "192.168.1.50"},
+		{"network.port", "9100", float64(9100)},
+		{"serial.port", "COM3", "COM3"},
+		{"serial.baud_rate", float64(115200), float64(115200)},
+		{"bluetooth.address", "00:11:22:33:44:55", "00:11:22:33:44:55"},
+		{"bluetooth.channel", float64(2), float64(2)},
+		{"queue.max_attempts", float64(3), float64(3)},
+		{"receipt.header", "Cafe\nMain St. 1", []interface{}{"Cafe", "Main St. 1"}},
+		{"receipt.footer", []interface{}{"Thanks!"}, []interface{}{"Thanks!"}},
+		{"receipt.logo", "logos/cafe.png", "logos/cafe.png"},
+		{"receipt.nv_logo", float64(2), float64(2)},
+		{"metrics.enabled", true, true},
+		{"audit_log.enabled", "1", true},
+		{"audit_log.max_size_kb", float64(1024), float64(1024)},
+		{"discovery.cache_ttl_seconds", float64(0), float64(0)},
+		{"update.channel", "beta", "beta"},
+		{"text.font", "B", "b"},
+		{"text.line_spacing", float64(40), float64(40)},
+		{"density.level", float64(-2), float64(-2)},
+		{"density.speed", float64(5), float64(5)},
+		{"density.command", "dc2", "dc2"},
+		{"beep.variant", "esc_paren_a", "esc_paren_a"},
+		{"printers", map[string]interface{}{"kitchen": map[string]interface{}{"adapter": "network", "network": map[string]interface{}{"address": "10.0.0.9", "port": float64(9100)}}},
+			map[string]interface{}{"address": "10.0.0.9", "port": float64(9100)}},
+		{"image.threshold", float64(70000), float64(65535)},
+	}
+
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		covered[tt.key] = true
+	}
+	for _, key := range Keys {
+		if !covered[key] {
+			t.Errorf("no round-trip case for key %s", key)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			resetConfigFile(t)
+			if err := Update(tt.key, tt.value); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+
+			data, err := os.ReadFile(testConfigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var raw map[string]interface{}
+			if err := json.Unmarshal(data, &raw); err != nil {
+				t.Fatal(err)
+			}
+			at := tt.key
+			if at == "printers" {
+				at = "printers.kitchen.network"
+			}
+			if got := lookup(raw, at); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("saved %s = %#v, want %#v", tt.key, got, tt.want)
+			}
+
+			// Loading and saving again must keep the value
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if err := Save(cfg); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			again, _ := os.ReadFile(testConfigPath)
+			if string(again) != string(data) {
+				t.Errorf("file changed after Load and Save:\n%s\nwant\n%s", again, data)
+			}
+		})
+	}
+}
+
+func TestUpdateRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		key   string
+		value interface{}
+	}{
+		{"port", float64(70000)},
+		{"port", "abc"},
+		{"adapter", "fax"},
+		{"timezone", "Mars/Olympus"},
+		{"language", "de"},
+		{"language", ""},
+		{"autostart.enabled", "maybe"},
+		{"network.port", float64(1.5)},
+		{"update.channel", "nightly"},
+		{"printers", map[string]interface{}{"default": map[string]interface{}{"adapter": "usb"}}},
+	}
+	for _, tt := range tests {
+		resetConfigFile(t)
+		err := Update(tt.key, tt.value)
+		var keyErr *KeyError
+		if !errors.As(err, &keyErr) || keyErr.Key != tt.key {
+			t.Errorf("Update(%s, %#v) = %v, want a KeyError for %s", tt.key, tt.value, err, tt.key)
+		}
+	}
+
+	resetConfigFile(t)
+	if err := Update("no.such.key", "x"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Update of an unknown key = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestUpdateUSBDevice(t *testing.T) {
+	resetConfigFile(t)
+	if err := UpdateUSBDevice(0x04b8, 0x0202); err != nil {
+		t.Fatalf("UpdateUSBDevice: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.USB.VendorID != 0x04b8 || cfg.USB.ProductID != 0x0202 {
+		t.Errorf("USB = %04x:%04x, want 04b8:0202", cfg.USB.VendorID, cfg.USB.ProductID)
+	}
+	if cfg.Adapter != "usb" {
+		t.Errorf("Adapter = %q, want usb", cfg.Adapter)
+	}
+
+	// Clearing the device keeps the adapter
+	if err := UpdateUSBDevice(0, 0); err != nil {
+		t.Fatalf("UpdateUSBDevice(0, 0): %v", err)
+	}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.USB.VendorID != 0 || cfg.USB.ProductID != 0 {
+		t.Errorf("USB = %04x:%04x after clearing, want 0000:0000", cfg.USB.VendorID, cfg.USB.ProductID)
+	}
+	if cfg.Adapter != "usb" {
+		t.Errorf("Adapter = %q after clearing, want usb", cfg.Adapter)
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	resetConfigFile(t)
+	t.Setenv("PRINTBRIDGE_PORT", "9200")
+	t.Setenv("PRINTBRIDGE_ADAPTER", "network")
+	t.Setenv("PRINTBRIDGE_NETWORK_ADDRESS", "192.168.1.50")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Port != 9200 {
+		t.Errorf("Port = %d, want 9200", cfg.Port)
+	}
+	if cfg.Adapter != "network" {
+		t.Errorf("Adapter = %q, want network", cfg.Adapter)
+	}
+	if cfg.Network.Address != "192.168.1.50" {
+		t.Errorf("Network.Address = %q, want 192.168.1.50", cfg.Network.Address)
+	}
+
+	want := []string{"port", "adapter", "network.address"}
+	if got := EnvOverrides(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvOverrides() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvOverridesIgnoreInvalidValues(t *testing.T) {
+	resetConfigFile(t)
+	t.Setenv("PRINTBRIDGE_PORT", "abc")
+	t.Setenv("PRINTBRIDGE_ADAPTER", "fax")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	def := DefaultConfig()
+	if cfg.Port != def.Port {
+		t.Errorf("Port = %d, want the default %d", cfg.Port, def.Port)
+	}
+	if cfg.Adapter != def.Adapter {
+		t.Errorf("Adapter = %q, want the default %q", cfg.Adapter, def.Adapter)
+	}
+}
+
+func TestEnvOverridesAreNotSaved(t *testing.T) {
+	resetConfigFile(t)
+	t.Setenv("PRINTBRIDGE_PORT", "9200")
+	t.Setenv("PRINTBRIDGE_NETWORK_ADDRESS", "192.168.1.50")
+
+	if err := Update("language", "en"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	data, err := os.ReadFile(testConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	def := DefaultConfig()
+	if got := lookup(raw, "port"); got != float64(def.Port) {
+		t.Errorf("saved port = %v, want the default %d", got, def.Port)
+	}
+	if got := lookup(raw, "network.address"); got != def.Network.Address {
+		t.Errorf("saved network.address = %v, want the default %q", got, def.Network.Address)
+	}
+	if got := lookup(raw, "language"); got != "en" {
+		t.Errorf("saved language = %v, want en", got)
+	}
+}
+
+func TestLoadMigratesUnversionedConfig(t *testing.T) {
+	resetConfigFile(t)
+	original := []byte(`{"port": 9300, "adapter": "network", "network": {"address": "10.0.0.5"}}`)
+	if err := os.WriteFile(testConfigPath, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+	if cfg.Port != 9300 || cfg.Adapter != "network" || cfg.Network.Address != "10.0.0.5" {
+		t.Errorf("migrated config lost settings: port %d, adapter %q, network.address %q", cfg.Port, cfg.Adapter, cfg.Network.Address)
+	}
+	// Fields the file lacked get their defaults
+	if def := DefaultConfig(); cfg.Host != def.Host {
+		t.Errorf("Host = %q, want the default %q", cfg.Host, def.Host)
+	}
+
+	data, err := os.ReadFile(testConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if got := lookup(raw, "config_version"); got != float64(CurrentVersion) {
+		t.Errorf("saved config_version = %v, want %d", got, CurrentVersion)
+	}
+	if got := lookup(raw, "port"); got != float64(9300) {
+		t.Errorf("saved port = %v, want 9300", got)
+	}
+
+	backup, err := os.ReadFile(testConfigPath + ".v0.bak")
+	if err != nil {
+		t.Fatalf("backup of the unversioned config: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Errorf("backup = %s, want the original %s", backup, original)
+	}
+
+	// Loading the migrated file again changes nothing
+	if _, err := Load(); err != nil {
+		t.Fatalf("second Load: %v", err)
+	}
+	again, _ := os.ReadFile(testConfigPath)
+	if string(again) != string(data) {
+		t.Errorf("file changed on second Load:\n%s\nwant\n%s", again, data)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PIDFilePath returns the file in the config directory that holds the
+// running service's process ID.
+func PIDFilePath() string {
+	return filepath.Join(GetConfigDir(), "printbridge.pid")
+}
+
+// WritePIDFile records the current process ID in the PID file.
+func WritePIDFile() error {
+	if err := EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	return os.WriteFile(PIDFilePath(), []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+}
+
+// RemovePIDFile removes the PID file if it belongs to this process, so an
+// instance that exits doesn't delete the file of one that replaced it.
+func RemovePIDFile() {
+	if pid, err := ReadPIDFile(); err == nil && pid == os.Getpid() {
+		os.Remove(PIDFilePath())
+	}
+}
+
+// ReadPIDFile returns the process ID recorded in the PID file.
+func ReadPIDFile() (int, error) {
+	data, err := os.ReadFile(PIDFilePath())
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file %s", PIDFilePath())
+	}
+	return pid, nil
+}
+
+// RunningServicePID returns the process ID of the running service, or 0 if
+// there is no PID file or the process it names has exited.
+func RunningServicePID() int {
+	pid, err := ReadPIDFile()
+	if err != nil || !processRunning(pid) {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package config
+
+import "syscall"
+
+// processRunning reports whether a process with the given ID exists.
+func processRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,21 @@
+package config
+
+import "golang.org/x/sys/windows"
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process.
+const stillActive = 259
+
+// processRunning reports whether a process with the given ID exists.
+func processRunning(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Field describes a config setting, for building config forms. Key is the
+// dotted key accepted by Set and POST /config.
+type Field struct {
+	Key     string      `json:"key"`
+	Type    string      `json:"type"` // string, integer, boolean, array (of strings) or object
+	Default interface{} `json:"default"`
+	Enum    []string    `json:"enum,omitempty"` // Allowed values, if limited
+	Min     *int        `json:"min,omitempty"`
+	Max     *int        `json:"max,omitempty"`
+	Help    string      `json:"help"`
+}
+
+// fieldInfo is what Schema can't read from the Config struct.
+type fieldInfo struct {
+	help     string
+	enum     []string
+	min, max int // Range of integers; both 0 for none
+}
+
+// fieldInfos describes the settings in Keys.
+var fieldInfos = map[string]fieldInfo{
+	"host":                         {help: "Address the API listens on; 127.0.0.1 for this machine only, 0.0.0.0 for the network"},
+	"port":                         {help: "Port the API listens on", min: 1, max: 65535},
+	"adapter":                      {help: "How the default printer is connected; auto picks windows on Windows and usb elsewhere", enum: Adapters},
+	"timezone":                     {help: "IANA time zone order times are printed in, e.g. Europe/Istanbul; empty for the system's"},
+	"language":                     {help: "Language of the labels on template receipts", enum: Languages},
+	"currency":                     {help: "ISO 4217 currency amounts are printed in, e.g. TRY; empty for the defaults"},
+	"locale":                       {help: "Locale of the number format, e.g. tr-TR; empty for the currency's own"},
+	"auth_token":                   {help: "Bearer token required on every endpoint except /health; empty disables auth"},
+	"allowed_origins":              {help: "Origins allowed to call the API from a browser; * allows any"},
+	"paper_width_mm":               {help: "Paper width of the default printer in millimetres", min: 58, max: 80},
+	"autostart.enabled":            {help: "Start the service automatically"},
+	"autostart.install_on_startup": {help: "Install the autostart service when the service starts, if it isn't installed"},
+	"windows.printer_name":         {help: "Windows printer to print to; empty for the default printer"},
+	"usb.vendor_id":                {help: "USB vendor ID of the printer, as a number or hex string", min: 0, max: 0xFFFF},
+	"usb.product_id":               {help: "USB product ID of the printer, as a number or hex string", min: 0, max: 0xFFFF},
+	"network.address":              {help: "IP address or host name of a network printer"},
+	"network.port":                 {help: "TCP port of a network printer, usually 9100", min: 1, max: 65535},
+	"serial.port":                  {help: "Serial port of the printer, e.g. COM3"},
+	"serial.baud_rate":             {help: "Serial port speed", min: 1, max: 4000000},
+	"bluetooth.address":            {help: "MAC address of a Bluetooth printer, e.g. 00:11:22:33:44:55"},
+	"bluetooth.channel":            {help: "RFCOMM channel of a Bluetooth printer", min: 1, max: 30},
+	"queue.max_attempts":           {help: "Tries per print job before it is marked failed", min: 1, max: 100},
+	"receipt.header":               {help: "Lines printed at the top of receipts; the first is bold"},
+	"receipt.footer":               {help: "Lines printed at the bottom of receipts"},
+	"receipt.logo":                 {help: "Logo image printed on receipts, absolute or relative to the templates directory"},
+	"receipt.nv_logo":              {help: "Index of a logo stored in the printer, printed instead of logo; 0 disables", min: 0, max: 99},
+	"metrics.enabled":              {help: "Serve Prometheus metrics on /metrics"},
+	"audit_log.enabled":            {help: "Record every print job to audit.log"},
+	"audit_log.max_size_kb":        {help: "Size in KB at which the audit log is rotated", min: 1, max: 1 << 20},
+	"discovery.cache_ttl_seconds":  {help: "Seconds printer scans are reused for; 0 always rescans", min: 0, max: 3600},
+	"update.channel":               {help: "Releases the tray offers; beta includes pre-releases", enum: []string{"stable", "beta"}},
+	"text.font":                    {help: "Default font; empty keeps the printer's", enum: []string{"", "a", "b", "c"}},
+	"text.line_spacing":            {help: "Default line spacing in dots; 0 keeps the printer's", min: 0, max: 255},
+	"density.level":                {help: "Print density from -6 (lightest) to 6 (darkest); 0 keeps the printer's", min: -6, max: 6},
+	"density.speed":                {help: "Print speed from 1 (slowest) to 9 (fastest); 0 keeps the printer's", min: 0, max: 9},
+	"density.command":              {help: "Density command: gs_k for Epson, dc2 for generic printers", enum: []string{"gs_k", "dc2"}},
+	"beep.variant":                 {help: "Buzzer command: esc_b, or esc_paren_a for Epson TM", enum: []string{"esc_b", "esc_paren_a"}},
+	"printers":                     {help: "Named printers in addition to the default one, each with its own adapter settings"},
+	"image.threshold":              {help: "Luminance below which image pixels print black", min: 0, max: 65535},
+}
+
+// Schema describes the settings in Keys, in the order of the Config
+// struct. Keys, types and defaults are read from Config and DefaultConfig,
+// so they can't drift from them.
+func Schema() []Field {
+	settable := make(map[string]bool, len(Keys))
+	for _, key := range Keys {
+		settable[key] = true
+	}
+
+	var fields []Field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			key := prefix + name
+			fv := v.Field(i)
+
+			if !settable[key] {
+				if fv.Kind() == reflect.Struct {
+					walk(fv, key+".")
+				}
+				continue
+			}
+
+			info := fieldInfos[key]
+			field := Field{
+				Key:     key,
+				Type:    schemaType(fv.Kind()),
+				Default: fv.Interface(),
+				Enum:    info.enum,
+				Help:    info.help,
+			}
+			if info.min != 0 || info.max != 0 {
+				min, max := info.min, info.max
+				field.Min, field.Max = &min, &max
+			}
+			fields = append(fields, field)
+		}
+	}
+	walk(reflect.ValueOf(DefaultConfig()).Elem(), "")
+	return fields
+}
+
+// schemaType returns the Field.Type of a Config field kind.
+func schemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Slice:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "string"
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher detects changes to a config file made outside the process, such
+// as edits through the tray's Open Config, by comparing its modification
+// time and size.
+type Watcher struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher returns a Watcher for path that treats its current contents
+// as seen.
+func NewWatcher(path string) *Watcher {
+	w := &Watcher{path: path}
+	w.Changed()
+	return w
+}
+
+// Changed reports whether the file was modified since the last call, or
+// since NewWatcher. A missing file counts as unchanged.
+func (w *Watcher) Changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+	return true
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a count that only goes up.
+type Counter struct {
+	v atomic.Uint64
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.v.Add(1)
+}
+
+// Value returns the current count.
+func (c *Counter) Value() uint64 {
+	return c.v.Load()
+}
+
+// AdapterReconnects counts printer connections reopened after they dropped.
+var AdapterReconnects Counter
+
+// printKey labels prints_total.
+type printKey struct {
+	endpoint string
+	status   string
+}
+
+var (
+	mu     sync.Mutex
+	prints = map[printKey]uint64{}
+	errs   = map[string]uint64{} // Failed prints by endpoint
+)
+
+// RecordPrint counts a print job sent through endpoint; err is its outcome.
+func RecordPrint(endpoint string, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	prints[printKey{endpoint, status}]++
+	if err != nil {
+		errs[endpoint]++
+	}
+}
+
+// PrinterState is a configured printer's state at scrape time.
+type PrinterState struct {
+	Name      string
+	Connected bool
+	Queued    int // Jobs waiting in the printer's queue
+}
+
+// Handler serves the counters in the Prometheus text format. printers is
+// called on every scrape for the per-printer gauges.
+func Handler(printers func() []PrinterState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var b strings.Builder
+
+		mu.Lock()
+		keys := make([]printKey, 0, len(prints))
+		for k := range prints {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].endpoint != keys[j].endpoint {
+				return keys[i].endpoint < keys[j].endpoint
+			}
+			return keys[i].status < keys[j].status
+		})
+		header(&b, "prints_total", "counter", "Print jobs by endpoint and status.")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "prints_total{endpoint=\"%s\",status=\"%s\"} %d\n", escape(k.endpoint), k.status, prints[k])
+		}
+
+		endpoints := make([]string, 0, len(errs))
+		for e := range errs {
+			endpoints = append(endpoints, e)
+		}
+		sort.Strings(endpoints)
+		header(&b, "print_errors_total", "counter", "Print jobs that failed, by endpoint.")
+		for _, e := range endpoints {
+			fmt.Fprintf(&b, "print_errors_total{endpoint=\"%s\"} %d\n", escape(e), errs[e])
+		}
+		mu.Unlock()
+
+		header(&b, "adapter_reconnects_total", "counter", "Printer connections reopened after they dropped.")
+		fmt.Fprintf(&b, "adapter_reconnects_total %d\n", AdapterReconnects.Value())
+
+		states := printers()
+		header(&b, "printer_connected", "gauge", "Whether the printer's adapter is connected (1) or not (0).")
+		for _, s := range states {
+			connected := 0
+			if s.Connected {
+				connected = 1
+			}
+			fmt.Fprintf(&b, "printer_connected{printer=\"%s\"} %d\n", escape(s.Name), connected)
+		}
+		header(&b, "queue_depth", "gauge", "Print jobs waiting in the printer's queue.")
+		for _, s := range states {
+			fmt.Fprintf(&b, "queue_depth{printer=\"%s\"} %d\n", escape(s.Name), s.Queued)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(b.String()))
+	}
+}
+
+// header writes a metric's HELP and TYPE lines.
+func header(b *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// labelEscaper escapes label values as the text format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,214 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidBarcode is returned when barcode data doesn't match the symbology.
+var ErrInvalidBarcode = errors.New("invalid barcode data")
+
+// code39Chars is the character set supported by CODE39.
+const code39Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ -.$/+%*"
+
+// maxCode128Data is the most CODE128 data GS k can send, including the
+// code set selections added by EncodeCode128.
+const maxCode128Data = 255
+
+// BarcodeTypes lists the symbologies BarcodeOptions.Type accepts.
+var BarcodeTypes = []string{"UPC_A", "UPC_E", "EAN13", "EAN8", "CODE39", "CODE128"}
+
+// ValidateBarcode checks code against the rules of barcodeType and returns
+// the data to print. For EAN13, EAN8 and UPC-A the check digit is appended
+// when it is missing, and verified when it is present.
+func ValidateBarcode(code string, barcodeType string) (string, error) {
+	switch barcodeType {
+	case "UPC_A", "UPC-A":
+		return validateGTIN(code, 12, "UPC-A")
+	case "UPC_E", "UPC-E":
+		if !isDigits(code) || !(len(code) >= 6 && len(code) <= 8 || len(code) == 11 || len(code) == 12) {
+			return "", fmt.Errorf("%w: UPC-E requires 6-8, 11 or 12 digits", ErrInvalidBarcode)
+		}
+		return code, nil
+	case "EAN13":
+		return validateGTIN(code, 13, "EAN13")
+	case "EAN8":
+		return validateGTIN(code, 8, "EAN8")
+	case "CODE128":
+		if code == "" {
+			return "", fmt.Errorf("%w: CODE128 data is empty", ErrInvalidBarcode)
+		}
+		for _, r := range code {
+			if r > 0x7f {
+				return "", fmt.Errorf("%w: CODE128 supports ASCII only, got %q", ErrInvalidBarcode, r)
+			}
+		}
+		if n := len(EncodeCode128(code)); n > maxCode128Data {
+			return "", fmt.Errorf("%w: CODE128 data encodes to %d bytes, maximum is %d", ErrInvalidBarcode, n, maxCode128Data)
+		}
+		return code, nil
+	default: // CODE39
+		if code == "" {
+			return "", fmt.Errorf("%w: CODE39 data is empty", ErrInvalidBarcode)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(code39Chars, r) {
+				return "", fmt.Errorf("%w: CODE39 does not support %q", ErrInvalidBarcode, r)
+			}
+		}
+		return code, nil
+	}
+}
+
+// BarcodeChecked validates code for the chosen symbology before printing it.
+// Nothing is added to the buffer if validation fails; the returned error
+// wraps ErrInvalidBarcode.
+func (p *Printer) BarcodeChecked(code string, opts BarcodeOptions) error {
+	data, err := ValidateBarcode(code, opts.Type)
+	if err != nil {
+		return err
+	}
+	p.BarcodeWithOptions(data, opts)
+	return nil
+}
+
+// validateGTIN validates an EAN/UPC code of the given full length (including
+// the check digit), appending the check digit if it was left off.
+func validateGTIN(code string, length int, name string) (string, error) {
+	if !isDigits(code) || (len(code) != length && len(code) != length-1) {
+		return "", fmt.Errorf("%w: %s requires %d or %d digits", ErrInvalidBarcode, name, length-1, length)
+	}
+
+	check := gtinCheckDigit(code[:length-1])
+	if len(code) == length-1 {
+		return code + string(rune('0'+check)), nil
+	}
+	if int(code[length-1]-'0') != check {
+		return "", fmt.Errorf("%w: %s check digit should be %d", ErrInvalidBarcode, name, check)
+	}
+	return code, nil
+}
+
+// EAN13CheckDigit returns the check digit for the first 12 digits of an
+// EAN-13 code.
+func EAN13CheckDigit(digits string) (int, error) {
+	return checkDigit(digits, 12, "EAN13")
+}
+
+// EAN8CheckDigit returns the check digit for the first 7 digits of an
+// EAN-8 code.
+func EAN8CheckDigit(digits string) (int, error) {
+	return checkDigit(digits, 7, "EAN8")
+}
+
+// UPCACheckDigit returns the check digit for the first 11 digits of a
+// UPC-A code.
+func UPCACheckDigit(digits string) (int, error) {
+	return checkDigit(digits, 11, "UPC-A")
+}
+
+func checkDigit(digits string, n int, name string) (int, error) {
+	if len(digits) != n || !isDigits(digits) {
+		return 0, fmt.Errorf("%w: %s check digit needs exactly %d digits", ErrInvalidBarcode, name, n)
+	}
+	return gtinCheckDigit(digits), nil
+}
+
+// withCheckDigit appends the check digit to EAN/UPC data that is one digit
+// short for barcodeType. Anything else is returned unchanged.
+func withCheckDigit(code, barcodeType string) string {
+	var n int
+	switch barcodeType {
+	case "UPC_A", "UPC-A":
+		n = 11
+	case "EAN13":
+		n = 12
+	case "EAN8":
+		n = 7
+	default:
+		return code
+	}
+	if len(code) != n || !isDigits(code) {
+		return code
+	}
+	return code + string(rune('0'+gtinCheckDigit(code)))
+}
+
+// gtinCheckDigit computes the GS1 mod-10 check digit for digits (without
+// the check digit). Weights alternate 3,1 starting from the rightmost digit.
+func gtinCheckDigit(digits string) int {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// isDigits reports whether s is non-empty and only contains ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeCode128 converts data into the code-set escaped form expected by
+// GS k 73. Code set C is used for runs of four or more digits, code set A
+// for control characters and code set B for everything else. Data that
+// already starts with a code-set selector ("{A", "{B" or "{C") is passed
+// through unchanged so callers can control the encoding themselves.
+func EncodeCode128(data string) []byte {
+	if len(data) >= 2 && data[0] == '{' && (data[1] == 'A' || data[1] == 'B' || data[1] == 'C') {
+		return []byte(data)
+	}
+
+	var out []byte
+	set := byte(0)
+	selectSet := func(s byte) {
+		if set != s {
+			out = append(out, '{', s)
+			set = s
+		}
+	}
+
+	for i := 0; i < len(data); {
+		// Count the run of digits starting here
+		run := 0
+		for i+run < len(data) && data[i+run] >= '0' && data[i+run] <= '9' {
+			run++
+		}
+
+		if run >= 4 {
+			selectSet('C')
+			for n := 0; n < run/2; n++ {
+				out = append(out, (data[i]-'0')*10+(data[i+1]-'0'))
+				i += 2
+			}
+			continue
+		}
+
+		c := data[i]
+		if c < 0x20 {
+			selectSet('A')
+		} else if set != 'A' || c >= 0x60 {
+			selectSet('B')
+		}
+		if c == '{' {
+			// A literal brace is escaped by doubling it
+			out = append(out, '{')
+		}
+		out = append(out, c)
+		i++
+	}
+	return out
+}
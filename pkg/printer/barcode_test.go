@@ -0,0 +1,235 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPDF417Framing(t *testing.T) {
+	p := newTestPrinter()
+	p.PDF417("ABC", 4, 3, 2)
+
+	want := []byte{
+		0x1d, 0x28, 0x6b, 0x03, 0x00, 0x30, 0x41, 4, // fn 065: 4 columns
+		0x1d, 0x28, 0x6b, 0x03, 0x00, 0x30, 0x44, 3, // fn 068: row height 3
+		0x1d, 0x28, 0x6b, 0x04, 0x00, 0x30, 0x45, 0x30, 0x32, // fn 069: level 2
+		0x1d, 0x28, 0x6b, 0x06, 0x00, 0x30, 0x50, 0x30, 'A', 'B', 'C', // fn 080: pL = 3 + len
+		0x1d, 0x28, 0x6b, 0x03, 0x00, 0x30, 0x51, 0x30, // fn 081: print
+	}
+	if !bytes.Equal(p.buffer, want) {
+		t.Errorf("PDF417 emitted\n% x\nwant\n% x", p.buffer, want)
+	}
+}
+
+func TestPDF417Clamping(t *testing.T) {
+	p := newTestPrinter()
+	p.PDF417("X", 31, 1, 9)
+	b := p.buffer
+	if b[7] != 30 {
+		t.Errorf("columns = %d, want 30", b[7])
+	}
+	if b[15] != 2 {
+		t.Errorf("row height = %d, want 2", b[15])
+	}
+	if b[24] != 0x30+8 {
+		t.Errorf("error level = %#x, want %#x", b[24], 0x30+8)
+	}
+}
+
+func TestPDF417StoreLength(t *testing.T) {
+	// 300 bytes of data need a pH byte
+	data := bytes.Repeat([]byte("9"), 300)
+	p := newTestPrinter()
+	p.PDF417(string(data), 6, 3, 1)
+
+	store := bytes.Index(p.buffer, []byte{0x30, 0x50, 0x30})
+	if store < 2 {
+		t.Fatal("no store data function")
+	}
+	pL, pH := int(p.buffer[store-2]), int(p.buffer[store-1])
+	if n := pL + pH*256; n != len(data)+3 {
+		t.Errorf("pL/pH = %d, want %d", n, len(data)+3)
+	}
+	if !bytes.Equal(p.buffer[store+3:store+3+len(data)], data) {
+		t.Error("data does not follow the store function")
+	}
+}
+
+func TestDataMatrixFraming(t *testing.T) {
+	p := newTestPrinter()
+	p.DataMatrix("https://x.io", 5)
+
+	want := []byte{
+		0x1d, 0x28, 0x6b, 0x05, 0x00, 0x36, 0x42, 0x00, 0x00, 0x00, // fn 066: square, automatic size
+		0x1d, 0x28, 0x6b, 0x03, 0x00, 0x36, 0x43, 5, // fn 067: module size 5
+		0x1d, 0x28, 0x6b, 0x0f, 0x00, 0x36, 0x50, 0x30, // fn 080: pL = 3 + 12
+	}
+	want = append(want, "https://x.io"...)
+	want = append(want, 0x1d, 0x28, 0x6b, 0x03, 0x00, 0x36, 0x51, 0x30) // fn 081: print
+	if !bytes.Equal(p.buffer, want) {
+		t.Errorf("DataMatrix emitted\n% x\nwant\n% x", p.buffer, want)
+	}
+}
+
+func TestDataMatrixModuleSizeClamped(t *testing.T) {
+	for _, tt := range []struct{ size, want int }{{0, 2}, {1, 2}, {2, 2}, {16, 16}, {17, 16}} {
+		p := newTestPrinter()
+		p.DataMatrix("A", tt.size)
+		if got := int(p.buffer[17]); got != tt.want {
+			t.Errorf("DataMatrix size %d: module size %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeCode128(t *testing.T) {
+	tests := []struct {
+		data string
+		want []byte
+	}{
+		{"ABC123", []byte("{BABC123")},
+		{"12345678", []byte{'{', 'C', 12, 34, 56, 78}},
+		{"AB12345", []byte{'{', 'B', 'A', 'B', '{', 'C', 12, 34, '{', 'B', '5'}},
+		{"A{B", []byte("{BA{{B")},
+		{"\tA", []byte{'{', 'A', '\t', 'A'}},
+		{"{Craw", []byte("{Craw")},
+	}
+	for _, tt := range tests {
+		if got := EncodeCode128(tt.data); !bytes.Equal(got, tt.want) {
+			t.Errorf("EncodeCode128(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestBarcodeCode128(t *testing.T) {
+	p := newTestPrinter()
+	p.BarcodeWithOptions("ABC123", BarcodeOptions{Type: "CODE128", Width: 2, Height: 60})
+
+	// GS k 73 n, then the code set B data
+	want := append([]byte{0x1d, 0x6b, 0x49, 8}, "{BABC123"...)
+	if !bytes.HasSuffix(p.buffer, want) {
+		t.Errorf("CODE128 emitted % x, want it to end with % x", p.buffer, want)
+	}
+}
+
+func TestBarcodeModuleWidth(t *testing.T) {
+	tests := []struct{ dots, want int }{{1, 2}, {2, 2}, {4, 4}, {6, 6}, {7, 6}}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		if got := p.BarcodeModuleWidth(tt.dots); got != tt.want {
+			t.Errorf("BarcodeModuleWidth(%d) = %d, want %d", tt.dots, got, tt.want)
+		}
+		if want := []byte{0x1d, 0x77, byte(tt.want)}; !bytes.Equal(p.buffer, want) {
+			t.Errorf("BarcodeModuleWidth(%d) emitted % x, want % x", tt.dots, p.buffer, want)
+		}
+	}
+}
+
+func TestBarcodeHeightDots(t *testing.T) {
+	tests := []struct{ dots, want int }{{0, 1}, {1, 1}, {7, 7}, {255, 255}, {256, 255}}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		if got := p.BarcodeHeightDots(tt.dots); got != tt.want {
+			t.Errorf("BarcodeHeightDots(%d) = %d, want %d", tt.dots, got, tt.want)
+		}
+		if want := []byte{0x1d, 0x68, byte(tt.want)}; !bytes.Equal(p.buffer, want) {
+			t.Errorf("BarcodeHeightDots(%d) emitted % x, want % x", tt.dots, p.buffer, want)
+		}
+	}
+}
+
+func TestCheckDigits(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     func(string) (int, error)
+		digits string
+		want   int
+	}{
+		{"EAN13", EAN13CheckDigit, "400638133393", 1},
+		{"EAN13", EAN13CheckDigit, "590123412345", 7},
+		{"EAN8", EAN8CheckDigit, "9638507", 4},
+		{"EAN8", EAN8CheckDigit, "5512345", 7},
+		{"UPC-A", UPCACheckDigit, "03600029145", 2},
+		{"UPC-A", UPCACheckDigit, "01234567890", 5},
+	}
+	for _, tt := range tests {
+		got, err := tt.fn(tt.digits)
+		if err != nil {
+			t.Errorf("%s check digit of %s: %v", tt.name, tt.digits, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s check digit of %s = %d, want %d", tt.name, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDigitsRejectInvalidInput(t *testing.T) {
+	for _, digits := range []string{"", "40063813339", "4006381333931", "40063813339X"} {
+		if _, err := EAN13CheckDigit(digits); !errors.Is(err, ErrInvalidBarcode) {
+			t.Errorf("EAN13CheckDigit(%q) error = %v, want ErrInvalidBarcode", digits, err)
+		}
+	}
+}
+
+func TestBarcodeAppendsCheckDigit(t *testing.T) {
+	tests := []struct {
+		barcodeType, code, want string
+	}{
+		{"EAN13", "400638133393", "4006381333931"},
+		{"EAN13", "4006381333931", "4006381333931"},
+		{"EAN8", "9638507", "96385074"},
+		{"UPC_A", "03600029145", "036000291452"},
+		{"UPC-A", "03600029145", "036000291452"},
+		{"CODE39", "1234567", "1234567"},
+	}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		p.BarcodeWithOptions(tt.code, BarcodeOptions{Type: tt.barcodeType})
+		if want := append([]byte(tt.want), 0x00); !bytes.HasSuffix(p.buffer, want) {
+			t.Errorf("%s %s emitted % x, want it to end with % x", tt.barcodeType, tt.code, p.buffer, want)
+		}
+	}
+}
+
+func TestValidateBarcodeCheckDigit(t *testing.T) {
+	if got, err := ValidateBarcode("590123412345", "EAN13"); err != nil || got != "5901234123457" {
+		t.Errorf("ValidateBarcode(12 digits) = %q, %v, want 5901234123457", got, err)
+	}
+	if got, err := ValidateBarcode("5901234123457", "EAN13"); err != nil || got != "5901234123457" {
+		t.Errorf("ValidateBarcode(13 digits) = %q, %v, want 5901234123457", got, err)
+	}
+	if _, err := ValidateBarcode("5901234123450", "EAN13"); !errors.Is(err, ErrInvalidBarcode) {
+		t.Errorf("ValidateBarcode(wrong check digit) error = %v, want ErrInvalidBarcode", err)
+	}
+}
+
+func TestCode128TooLong(t *testing.T) {
+	// Letters encode one byte each after the 2 byte code set selection
+	fits := strings.Repeat("A", maxCode128Data-2)
+	if _, err := ValidateBarcode(fits, "CODE128"); err != nil {
+		t.Errorf("ValidateBarcode(%d letters): %v", len(fits), err)
+	}
+
+	for _, code := range []string{fits + "A", strings.Repeat("A", 300)} {
+		if _, err := ValidateBarcode(code, "CODE128"); !errors.Is(err, ErrInvalidBarcode) {
+			t.Errorf("ValidateBarcode(%d bytes encoding to %d) error = %v, want ErrInvalidBarcode", len(code), len(EncodeCode128(code)), err)
+		}
+
+		p := newTestPrinter()
+		if err := p.BarcodeChecked(code, BarcodeOptions{Type: "CODE128"}); !errors.Is(err, ErrInvalidBarcode) {
+			t.Errorf("BarcodeChecked error = %v, want ErrInvalidBarcode", err)
+		}
+		if len(p.buffer) != 0 {
+			t.Errorf("BarcodeChecked emitted % x on error, want nothing", p.buffer)
+		}
+
+		// Unchecked, the barcode is left out rather than cut short
+		p = newTestPrinter()
+		p.BarcodeWithOptions(code, BarcodeOptions{Type: "CODE128"})
+		if bytes.Contains(p.buffer, BARCODE_CODE128) {
+			t.Errorf("BarcodeWithOptions printed %d bytes of CODE128 data, want no barcode", len(EncodeCode128(code)))
+		}
+	}
+}
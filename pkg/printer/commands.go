@@ -1,5 +1,7 @@
 package printer
 
+import "fmt"
+
 // ESC/POS Commands based on node-escpos reference
 
 // Control characters
@@ -45,8 +47,13 @@ var (
 	TXT_BOLD_OFF = []byte{0x1b, 0x45, 0x00} // Bold off
 	TXT_BOLD_ON  = []byte{0x1b, 0x45, 0x01} // Bold on
 
-	TXT_ITALIC_OFF = []byte{0x1b, 0x35} // Italic off
-	TXT_ITALIC_ON  = []byte{0x1b, 0x34} // Italic on
+	TXT_DOUBLE_STRIKE_OFF = []byte{0x1b, 0x47, 0x00} // Double-strike off
+	TXT_DOUBLE_STRIKE_ON  = []byte{0x1b, 0x47, 0x01} // Double-strike on
+
+	// ESC/POS has no italic command; these are the ESC/P codes, which only
+	// printers with ESC/P support honor. See Printer.Italic.
+	TXT_ITALIC_OFF = []byte{0x1b, 0x35} // Italic off (ESC 5)
+	TXT_ITALIC_ON  = []byte{0x1b, 0x34} // Italic on (ESC 4)
 
 	TXT_FONT_A = []byte{0x1b, 0x4d, 0x00} // Font A
 	TXT_FONT_B = []byte{0x1b, 0x4d, 0x01} // Font B
@@ -57,6 +64,99 @@ var (
 	TXT_ALIGN_RT = []byte{0x1b, 0x61, 0x02} // Right align
 )
 
+// Right-side character spacing (ESC SP n) - adds n dots after each character
+func CharSpacing(n int) []byte {
+	if n < 0 {
+		n = 0
+	}
+	if n > 255 {
+		n = 255
+	}
+	return []byte{0x1b, 0x20, byte(n)}
+}
+
+// Print density and speed command variants. As with the buzzer, printers
+// ignore the variant they don't support.
+const (
+	// DensityGSK uses GS ( K fn 49/50. Epson TM printers and compatibles
+	// (Bixolon, Citizen in ESC/POS mode).
+	DensityGSK = "gs_k"
+
+	// DensityDC2 uses DC2 # n for density and ESC 7 heating settings for
+	// speed. Generic 58mm and panel printers (Xprinter, Goojprt, Adafruit
+	// and other CSN-A2 based printers).
+	DensityDC2 = "dc2"
+)
+
+// Density and print speed limits for SetDensity and SetPrintSpeed.
+const (
+	MinDensity = -6 // Lightest
+	MaxDensity = 6  // Darkest
+	MinSpeed   = 1  // Slowest, darkest
+	MaxSpeed   = 9  // Fastest
+)
+
+// DensityCommand returns the command setting print density to level
+// (MinDensity to MaxDensity, clamped; 0 is the printer's standard).
+func DensityCommand(variant string, level int) []byte {
+	level = max(MinDensity, min(level, MaxDensity))
+	if variant == DensityDC2 {
+		// n = heating break time (bits 5-7) | density 50% + 5% x n (bits 0-4)
+		return []byte{0x12, 0x23, 2<<5 | byte(10+level)}
+	}
+	// m is signed: 250-255 lighter, 0 standard, 1-6 darker
+	return []byte{0x1d, 0x28, 0x4b, 0x02, 0x00, 0x31, byte(int8(level))}
+}
+
+// SpeedCommand returns the command setting print speed to level (MinSpeed
+// to MaxSpeed, clamped).
+func SpeedCommand(variant string, level int) []byte {
+	level = max(MinSpeed, min(level, MaxSpeed))
+	if variant == DensityDC2 {
+		// ESC 7 max heating dots, heating time (x 10us), heating interval;
+		// level 5 gives the common 11/120/40 defaults
+		return []byte{0x1b, 0x37, 11, byte(200 - (level-1)*20), 40}
+	}
+	return []byte{0x1d, 0x28, 0x4b, 0x02, 0x00, 0x32, byte(level)}
+}
+
+// Horizontal tab positions (ESC D n1 ... nk NUL); no positions clears them
+func SetTabPositions(positions ...int) []byte {
+	cmd := []byte{0x1b, 0x44}
+	for _, n := range positions {
+		cmd = append(cmd, byte(n))
+	}
+	return append(cmd, 0x00)
+}
+
+// Text rotation (ESC V n) and upside-down printing (ESC { n)
+var (
+	TXT_ROTATE_OFF = []byte{0x1b, 0x56, 0x00} // 90° rotation off
+	TXT_ROTATE_90  = []byte{0x1b, 0x56, 0x01} // Rotate 90° clockwise
+	TXT_UPSIDE_OFF = []byte{0x1b, 0x7b, 0x00} // Upside-down off
+	TXT_UPSIDE_ON  = []byte{0x1b, 0x7b, 0x01} // Upside-down (180°) on
+)
+
+// Page mode
+var (
+	PAGE_MODE_ON    = []byte{0x1b, 0x4c} // ESC L - Select page mode
+	PAGE_MODE_PRINT = []byte{0x1b, 0x0c} // ESC FF - Print the page, stay in page mode
+	PAGE_MODE_OFF   = []byte{0x1b, 0x53} // ESC S - Select standard mode
+)
+
+// Page mode print area (ESC W xL xH yL yH dxL dxH dyL dyH), in motion units
+func PrintArea(x, y, w, h int) []byte {
+	return []byte{0x1b, 0x57,
+		byte(x), byte(x >> 8), byte(y), byte(y >> 8),
+		byte(w), byte(w >> 8), byte(h), byte(h >> 8)}
+}
+
+// Page mode absolute position (ESC $ nL nH horizontal, GS $ nL nH vertical),
+// relative to the print area, in motion units
+func PagePosition(x, y int) []byte {
+	return []byte{0x1b, 0x24, byte(x), byte(x >> 8), 0x1d, 0x24, byte(y), byte(y >> 8)}
+}
+
 // Paper cutting
 var (
 	PAPER_FULL_CUT = []byte{0x1d, 0x56, 0x00} // Full cut
@@ -103,9 +203,69 @@ var (
 	QR_PRINT      = []byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x31, 0x51, 0x30} // Print QR
 )
 
-// Beep
+// PDF417 (GS ( k, cn=48)
+var (
+	PDF417_COLUMNS    = []byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x30, 0x41}       // fn 065 - Set number of columns
+	PDF417_ROW_HEIGHT = []byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x30, 0x44}       // fn 068 - Set row height
+	PDF417_ERROR      = []byte{0x1d, 0x28, 0x6b, 0x04, 0x00, 0x30, 0x45, 0x30} // fn 069 - Set error correction level (level mode)
+	PDF417_STORE_PRE  = []byte{0x1d, 0x28, 0x6b}                               // Store data prefix
+	PDF417_STORE_POST = []byte{0x30, 0x50, 0x30}                               // fn 080 - Store data postfix
+	PDF417_PRINT      = []byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x30, 0x51, 0x30} // fn 081 - Print PDF417
+)
+
+// DataMatrix (GS ( k, cn=54)
+var (
+	DATAMATRIX_TYPE       = []byte{0x1d, 0x28, 0x6b, 0x05, 0x00, 0x36, 0x42, 0x00, 0x00, 0x00} // fn 066 - Square, automatic size
+	DATAMATRIX_SIZE       = []byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x36, 0x43}                   // fn 067 - Set module size
+	DATAMATRIX_STORE_PRE  = []byte{0x1d, 0x28, 0x6b}                                           // Store data prefix
+	DATAMATRIX_STORE_POST = []byte{0x36, 0x50, 0x30}                                           // fn 080 - Store data postfix
+	DATAMATRIX_PRINT      = []byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x36, 0x51, 0x30}             // fn 081 - Print DataMatrix
+)
+
+// Beep (ESC B n t)
 var BEEP = []byte{0x1b, 0x42}
 
+// Buzzer command variants. Printers ignore the ones they don't support, so
+// the right one has to be chosen per model.
+const (
+	// BeepESCB is ESC B n t: n beeps of t x 50ms. Used by most generic
+	// 58/80mm printers (Xprinter, HPRT, Rongta, Gprinter, ...).
+	BeepESCB = "esc_b"
+
+	// BeepESCParenA is ESC ( A pL pH 97 c t: c beeps of t x 100ms. Used by
+	// Epson TM models with a built-in buzzer (TM-T20III, TM-T88VI, TM-m30).
+	BeepESCParenA = "esc_paren_a"
+)
+
+// BeepLimits returns the maximum times and duration accepted by a buzzer
+// variant; both start at 1.
+func BeepLimits(variant string) (maxTimes, maxDuration int, err error) {
+	switch variant {
+	case BeepESCB:
+		return 9, 9, nil
+	case BeepESCParenA:
+		return 63, 255, nil
+	}
+	return 0, 0, fmt.Errorf("unknown beep variant %q (use %s or %s)", variant, BeepESCB, BeepESCParenA)
+}
+
+// BeepCommand returns the buzzer command for variant, clamping times and
+// duration to the variant's limits.
+func BeepCommand(variant string, times, duration int) []byte {
+	maxTimes, maxDuration, err := BeepLimits(variant)
+	if err != nil {
+		variant = BeepESCB
+		maxTimes, maxDuration, _ = BeepLimits(variant)
+	}
+	times = max(1, min(times, maxTimes))
+	duration = max(1, min(duration, maxDuration))
+
+	if variant == BeepESCParenA {
+		return []byte{0x1b, 0x28, 0x41, 0x03, 0x00, 0x61, byte(times), byte(duration)}
+	}
+	return append(append([]byte{}, BEEP...), byte(times), byte(duration))
+}
+
 // TxtCustomSize returns the command for custom text size.
 func TxtCustomSize(width, height int) []byte {
 	if width < 1 {
@@ -125,26 +285,39 @@ func TxtCustomSize(width, height int) []byte {
 	return []byte{0x1d, 0x21, size}
 }
 
-// BarcodeHeight returns the command for barcode height.
+// BarcodeHeight returns the command for barcode height (GS h n).
+// height is in dots (1-255); out-of-range values are clamped.
 func BarcodeHeight(height int) []byte {
+	return []byte{0x1d, 0x68, byte(ClampBarcodeHeight(height))}
+}
+
+// BarcodeWidth returns the command for barcode module width (GS w n).
+// width is the width of the narrowest bar in dots (2-6); out-of-range
+// values are clamped.
+func BarcodeWidth(width int) []byte {
+	return []byte{0x1d, 0x77, byte(ClampBarcodeWidth(width))}
+}
+
+// ClampBarcodeHeight limits a barcode height to the supported 1-255 dots.
+func ClampBarcodeHeight(height int) int {
 	if height < 1 {
 		height = 1
 	}
 	if height > 255 {
 		height = 255
 	}
-	return []byte{0x1d, 0x68, byte(height)}
+	return height
 }
 
-// BarcodeWidth returns the command for barcode width.
-func BarcodeWidth(width int) []byte {
+// ClampBarcodeWidth limits a barcode module width to the supported 2-6 dots.
+func ClampBarcodeWidth(width int) int {
 	if width < 2 {
 		width = 2
 	}
 	if width > 6 {
 		width = 6
 	}
-	return []byte{0x1d, 0x77, byte(width)}
+	return width
 }
 
 // ============== HIGH-PRIORITY ESC/POS COMMANDS ==============
@@ -266,3 +439,72 @@ func RasterImageCmd(mode int, widthBytes, heightDots int) []byte {
 	yH := byte(heightDots / 256)
 	return []byte{0x1d, 0x76, 0x30, byte(mode), xL, xH, yL, yH}
 }
+
+// Graphics print (GS ( L / GS 8 L) - higher resolution than GS v 0
+var GRAPHICS_PRINT = []byte{0x1d, 0x28, 0x4c, 0x02, 0x00, 0x30, 0x32} // GS ( L fn=50 - Print buffered graphics
+
+// GraphicsStoreCmd returns the command prefix for storing monochrome raster
+// graphics data in the print buffer (fn=112).
+// Format: GS ( L pL pH m fn a bx by c xL xH yL yH d1...dk
+// When the parameter block exceeds 65535 bytes the extended form
+// GS 8 L p1 p2 p3 p4 is used instead.
+// widthDots: horizontal dots, heightDots: vertical dots, dataLen: k
+func GraphicsStoreCmd(widthDots, heightDots, dataLen int) []byte {
+	// m=48, fn=112, a=48 (monochrome), bx=1, by=1, c=49 (color 1)
+	return graphicsCmd([]byte{
+		0x30, 0x70, 0x30, 0x01, 0x01, 0x31,
+		byte(widthDots % 256), byte(widthDots / 256),
+		byte(heightDots % 256), byte(heightDots / 256),
+	}, dataLen)
+}
+
+// NVGraphicsStoreCmd returns the command prefix for defining monochrome
+// raster graphics in NV memory under key codes kc1 kc2 (fn=67).
+// Format: GS ( L pL pH m fn a kc1 kc2 b xL xH yL yH c d1...dk
+func NVGraphicsStoreCmd(kc1, kc2 byte, widthDots, heightDots, dataLen int) []byte {
+	// m=48, fn=67, a=48 (raster), b=1 (one color), c=49 (color 1)
+	return graphicsCmd([]byte{
+		0x30, 0x43, 0x30, kc1, kc2, 0x01,
+		byte(widthDots % 256), byte(widthDots / 256),
+		byte(heightDots % 256), byte(heightDots / 256),
+		0x31,
+	}, dataLen)
+}
+
+// NVGraphicsPrintCmd returns the command printing the NV graphics stored
+// under key codes kc1 kc2 at x times width and y times height (1 or 2).
+// Format: GS ( L pL pH m fn kc1 kc2 x y (fn=69)
+func NVGraphicsPrintCmd(kc1, kc2 byte, x, y int) []byte {
+	return []byte{0x1d, 0x28, 0x4c, 0x06, 0x00, 0x30, 0x45, kc1, kc2, byte(x), byte(y)}
+}
+
+// graphicsCmd frames a GS ( L parameter block followed by dataLen bytes of
+// image data, switching to GS 8 L when it exceeds 65535 bytes.
+func graphicsCmd(params []byte, dataLen int) []byte {
+	size := len(params) + dataLen
+
+	var cmd []byte
+	if size <= 65535 {
+		cmd = []byte{0x1d, 0x28, 0x4c, byte(size % 256), byte(size / 256)}
+	} else {
+		cmd = []byte{0x1d, 0x38, 0x4c,
+			byte(size), byte(size >> 8), byte(size >> 16), byte(size >> 24)}
+	}
+	return append(cmd, params...)
+}
+
+// Real-time status transmission (DLE EOT n)
+const (
+	STATUS_PRINTER = 1 // Printer status
+	STATUS_OFFLINE = 2 // Offline cause
+	STATUS_ERROR   = 3 // Error cause
+	STATUS_PAPER   = 4 // Paper roll sensor
+)
+
+// StatusRequest returns the DLE EOT n command for the given status type (1-4).
+func StatusRequest(n int) []byte {
+	if n < 1 || n > 4 {
+		n = 1
+	}
+	return []byte{0x10, 0x04, byte(n)}
+}
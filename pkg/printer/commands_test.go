@@ -0,0 +1,44 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBeepCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		variant         string
+		times, duration int
+		want            []byte
+	}{
+		{"esc_b", BeepESCB, 2, 3, []byte{0x1b, 0x42, 2, 3}},
+		{"esc_b clamped", BeepESCB, 20, 0, []byte{0x1b, 0x42, 9, 1}},
+		{"esc_paren_a", BeepESCParenA, 3, 100, []byte{0x1b, 0x28, 0x41, 0x03, 0x00, 0x61, 3, 100}},
+		{"esc_paren_a clamped", BeepESCParenA, 64, 300, []byte{0x1b, 0x28, 0x41, 0x03, 0x00, 0x61, 63, 255}},
+		{"unknown falls back to esc_b", "bell", 1, 1, []byte{0x1b, 0x42, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BeepCommand(tt.variant, tt.times, tt.duration); !bytes.Equal(got, tt.want) {
+				t.Errorf("BeepCommand(%q, %d, %d) = % x, want % x", tt.variant, tt.times, tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinterBeepUsesVariant(t *testing.T) {
+	for _, variant := range []string{BeepESCB, BeepESCParenA} {
+		p := newTestPrinter()
+		if err := p.SetBeepVariant(variant); err != nil {
+			t.Fatal(err)
+		}
+		p.Beep(2, 2)
+		if got, want := p.buffer, BeepCommand(variant, 2, 2); !bytes.Equal(got, want) {
+			t.Errorf("%s: Beep emitted % x, want % x", variant, got, want)
+		}
+	}
+	if err := newTestPrinter().SetBeepVariant("bell"); err == nil {
+		t.Error("SetBeepVariant accepted an unknown variant")
+	}
+}
@@ -0,0 +1,221 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Section types for TemplateSection. The named sections render a part of
+// the order; "text" and "line" add fixed content.
+const (
+	SectionMerchant = "merchant" // Merchant name and location
+	SectionOrder    = "order"    // Order time and type
+	SectionCustomer = "customer" // Customer name, phone and address
+	SectionItems    = "items"    // Ordered items with prices
+	SectionTotals   = "totals"   // Subtotal, fees and total
+	SectionPayment  = "payment"  // Payment method and note
+	SectionNotes    = "notes"    // Customer note, if any
+	SectionFooter   = "footer"   // Closing line
+	SectionTracking = "tracking" // QR code of the order's TrackingURL, if any, with optional Size
+	SectionText     = "text"     // Fixed Text, with optional Align and Bold
+	SectionLine     = "line"     // Separator drawn with Char (default "-")
+)
+
+// DefaultSections is the layout used by the built-in platform templates.
+var DefaultSections = []TemplateSection{
+	{Type: SectionMerchant},
+	{Type: SectionOrder},
+	{Type: SectionCustomer},
+	{Type: SectionItems},
+	{Type: SectionTotals},
+	{Type: SectionPayment},
+	{Type: SectionNotes},
+	{Type: SectionFooter},
+	{Type: SectionTracking},
+}
+
+// TemplateSection is one entry in a template's layout. In JSON it may be
+// written as just the type name, e.g. "items", or as an object.
+type TemplateSection struct {
+	Type  string `json:"type"`
+	Text  string `json:"text,omitempty"`
+	Align string `json:"align,omitempty"`
+	Bold  bool   `json:"bold,omitempty"`
+	Char  string `json:"char,omitempty"`
+	Size  int    `json:"size,omitempty"` // QR module size 1-16 for tracking; 0 is 6
+}
+
+// UnmarshalJSON accepts a section as a string or an object.
+func (s *TemplateSection) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = TemplateSection{Type: name}
+		return nil
+	}
+
+	type section TemplateSection
+	return json.Unmarshal(data, (*section)(s))
+}
+
+func (s TemplateSection) validate() error {
+	switch s.Type {
+	case SectionMerchant, SectionOrder, SectionCustomer, SectionItems,
+		SectionTotals, SectionPayment, SectionNotes, SectionFooter, SectionLine:
+		return nil
+	case SectionTracking:
+		if s.Size < 0 || s.Size > 16 {
+			return fmt.Errorf("tracking QR size must be between 1 and 16")
+		}
+		return nil
+	case SectionText:
+		if s.Text == "" {
+			return fmt.Errorf("text section has no text")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown section type %q", s.Type)
+}
+
+var (
+	userTemplatesMu sync.RWMutex
+	userTemplates   = map[string]Template{}
+)
+
+// LoadTemplates reads user-defined templates from the *.json files in dir,
+// replacing any loaded before. They take precedence over PlatformTemplates.
+// Files that fail to parse are logged and skipped; a missing dir is not an
+// error. It returns the number of templates loaded.
+func LoadTemplates(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	loaded := map[string]Template{}
+	count := 0
+	for _, file := range files {
+		tmpl, err := loadTemplateFile(file)
+		if err != nil {
+			log.Printf("[Templates] Skipping %s: %v", file, err)
+			continue
+		}
+
+		loaded[NormalizePlatform(tmpl.ID)] = tmpl
+		for _, alias := range tmpl.Aliases {
+			loaded[NormalizePlatform(alias)] = tmpl
+		}
+		log.Printf("[Templates] Loaded template %q from %s", tmpl.ID, file)
+		count++
+	}
+
+	userTemplatesMu.Lock()
+	userTemplates = loaded
+	userTemplatesMu.Unlock()
+
+	return count, nil
+}
+
+// loadTemplateFile parses and validates one template definition. The ID
+// defaults to the file name without extension.
+func loadTemplateFile(path string) (Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Template{}, err
+	}
+
+	var tmpl Template
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return Template{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if tmpl.ID == "" {
+		tmpl.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = tmpl.ID
+	}
+	for i, section := range tmpl.Sections {
+		if err := section.validate(); err != nil {
+			return Template{}, fmt.Errorf("section %d: %w", i+1, err)
+		}
+	}
+	for name, sections := range tmpl.Printers {
+		if len(sections) == 0 {
+			return Template{}, fmt.Errorf("printer %q has no sections", name)
+		}
+		for i, section := range sections {
+			if err := section.validate(); err != nil {
+				return Template{}, fmt.Errorf("printer %q section %d: %w", name, i+1, err)
+			}
+		}
+	}
+
+	return tmpl, nil
+}
+
+// userTemplate looks up a loaded user-defined template by normalized key.
+func userTemplate(key string) (Template, bool) {
+	userTemplatesMu.RLock()
+	defer userTemplatesMu.RUnlock()
+	tmpl, ok := userTemplates[key]
+	return tmpl, ok
+}
+
+// TemplateInfo describes an available template for listing, e.g. in a
+// platform dropdown.
+type TemplateInfo struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"` // Other platform names that select it
+	Logo    string   `json:"logo,omitempty"`
+	HasLogo bool     `json:"has_logo"` // Whether the logo file exists
+	Custom  bool     `json:"custom"`   // Loaded from the templates directory
+}
+
+// ListTemplates returns the built-in and user-defined templates sorted by
+// ID, with user-defined templates replacing built-in ones of the same ID.
+// Logos are looked up in templatesDir.
+func ListTemplates(templatesDir string) []TemplateInfo {
+	byID := map[string]TemplateInfo{}
+	for key, tmpl := range PlatformTemplates {
+		info := TemplateInfo{ID: key, Name: tmpl.Name, Aliases: []string{}, Logo: TemplateLogoPath(templatesDir, tmpl)}
+		for alias, target := range platformAliases {
+			if target == key {
+				info.Aliases = append(info.Aliases, alias)
+			}
+		}
+		byID[key] = info
+	}
+
+	userTemplatesMu.RLock()
+	for _, tmpl := range userTemplates {
+		key := NormalizePlatform(tmpl.ID)
+		if info, ok := byID[key]; ok && info.Custom {
+			continue // Already added under another alias
+		}
+		aliases := make([]string, 0, len(tmpl.Aliases))
+		for _, alias := range tmpl.Aliases {
+			aliases = append(aliases, NormalizePlatform(alias))
+		}
+		byID[key] = TemplateInfo{ID: key, Name: tmpl.Name, Aliases: aliases, Logo: TemplateLogoPath(templatesDir, tmpl), Custom: true}
+	}
+	userTemplatesMu.RUnlock()
+
+	list := make([]TemplateInfo, 0, len(byID))
+	for _, info := range byID {
+		sort.Strings(info.Aliases)
+		if info.Logo != "" {
+			_, err := os.Stat(filepath.Join(templatesDir, info.Logo))
+			info.HasLogo = err == nil
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
@@ -0,0 +1,358 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// RenderText interprets an ESC/POS byte stream and returns an approximate
+// plain-text picture of the printout, width characters wide. Alignment is
+// applied with spaces, bold text is wrapped in **, cuts are drawn as a row
+// of dashes and images, barcodes and 2D codes are shown as [placeholders].
+// Text bytes are decoded from the given encoding (see SetEncoding).
+func RenderText(data []byte, width int, encoding string) string {
+	if width <= 0 {
+		width = 48
+	}
+	r := &textRenderer{width: width, enc: encodings[normalizeEncoding(encoding)], sizeW: 1}
+	r.render(data)
+	return r.out.String()
+}
+
+// RenderText renders data as plain text using this printer's paper width
+// and encoding.
+func (p *Printer) RenderText(data []byte) string {
+	return RenderText(data, p.paperWidth, p.encoding)
+}
+
+type textRenderer struct {
+	width int
+	enc   runeEncoder
+
+	out     strings.Builder
+	line    strings.Builder
+	cols    int    // Printed columns on the current line
+	text    []byte // Undecoded text bytes not yet added to line
+	align   byte   // 0 left, 1 center, 2 right
+	bold    bool
+	marked  bool // Whether the current line has an open ** marker
+	sizeW   int
+	symbols map[byte]string // Stored 2D code data by symbol type (cn)
+	tabs    []int           // Tab stops set with ESC D; nil is every 8 columns
+}
+
+func (r *textRenderer) render(data []byte) {
+	// arg returns data[i], or 0 past the end so truncated input is harmless
+	arg := func(i int) int {
+		if i < len(data) {
+			return int(data[i])
+		}
+		return 0
+	}
+
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch b {
+		case 0x0a: // LF
+			r.newline()
+		case 0x09: // HT
+			r.flushText()
+			if pad := r.tabPad(); pad > 0 {
+				r.write(strings.Repeat(" ", pad), pad)
+			}
+		case 0x0c: // FF
+			r.newline()
+		case 0x10: // DLE EOT n, DLE ENQ n
+			i += 2
+		case 0x12: // DC2 # n print density
+			i += 2
+		case 0x1b: // ESC
+			i += r.esc(data, i, arg)
+		case 0x1d: // GS
+			i += r.gs(data, i, arg)
+		case 0x1c: // FS
+			switch arg(i + 1) {
+			case 'p': // Print NV bit image n m
+				r.block("[logo]")
+				i += 3
+			default:
+				i++
+			}
+		default:
+			if b >= 0x20 {
+				r.text = append(r.text, b)
+			}
+		}
+	}
+	r.flushText()
+	if r.cols > 0 {
+		r.newline()
+	}
+}
+
+// tabPad returns the spaces from the cursor to the next tab stop, or 0 if
+// there is none.
+func (r *textRenderer) tabPad() int {
+	if r.tabs == nil {
+		return 8 - r.cols%8
+	}
+	for _, stop := range r.tabs {
+		if stop > r.cols {
+			return stop - r.cols
+		}
+	}
+	return 0
+}
+
+// esc handles an ESC sequence at data[i] and returns the bytes consumed
+// after the ESC.
+func (r *textRenderer) esc(data []byte, i int, arg func(int) int) int {
+	switch arg(i + 1) {
+	case '@': // Initialize
+		r.setBold(false)
+		r.align, r.sizeW = 0, 1
+		r.tabs = nil
+		return 1
+	case 'a': // Alignment
+		r.flushText()
+		r.align = byte(arg(i+2) % 48)
+		return 2
+	case 'E', 'G': // Bold / double-strike
+		r.setBold(arg(i+2)&1 == 1)
+		return 2
+	case '!': // Print mode
+		n := arg(i + 2)
+		r.setBold(n&0x08 != 0)
+		r.setSizeW(1 + (n>>5)&1)
+		return 2
+	case 'd': // Feed n lines
+		r.newline()
+		for n := arg(i + 2); n > 1; n-- {
+			r.newline()
+		}
+		return 2
+	case 'D': // Tab stops n1 ... nk NUL
+		r.tabs = []int{}
+		n := 2
+		for ; i+n < len(data) && data[i+n] != 0; n++ {
+			r.tabs = append(r.tabs, int(data[i+n]))
+		}
+		return n
+	case 'p': // Cash drawer pulse m t1 t2
+		r.block("[cash drawer]")
+		return 4
+	case 'B': // Beep n t
+		return 3
+	case '*': // Bit image m nL nH d1...dk
+		m, n := arg(i+2), arg(i+3)+arg(i+4)*256
+		if m == 32 || m == 33 {
+			n *= 3
+		}
+		r.block("[image]")
+		return 4 + n
+	case '(':
+		// ESC ( A pL pH ... and other function-code commands
+		return 4 + arg(i+3) + arg(i+4)*256
+	case '7': // Heating settings n1 n2 n3
+		return 4
+	case 0x0c: // Print page in page mode
+		r.newline()
+		return 1
+	case '2', '4', '5', '<':
+		return 1
+	case '-', 'M', 't', 'R', '3', ' ', 'J', 'V', '{', '=', '?', 'c', 'U', 'r', 'S', 'T', 'L', 'W', '$', '\\':
+		// Single-parameter settings that don't affect the text layout
+		switch arg(i + 1) {
+		case 'W':
+			return 9
+		case '$', '\\':
+			return 3
+		case 'L', 'S':
+			return 1
+		}
+		return 2
+	}
+	return 1
+}
+
+// gs handles a GS sequence at data[i] and returns the bytes consumed
+// after the GS.
+func (r *textRenderer) gs(data []byte, i int, arg func(int) int) int {
+	switch arg(i + 1) {
+	case '!': // Character size
+		r.setSizeW(1 + (arg(i+2)>>4)&7)
+		return 2
+	case 'V': // Cut
+		m := arg(i + 2)
+		r.block(strings.Repeat("-", r.width))
+		if m == 65 || m == 66 || m == 97 || m == 98 || m == 103 || m == 104 {
+			return 3
+		}
+		return 2
+	case 'v': // GS v 0 m xL xH yL yH raster image
+		w, h := arg(i+4)+arg(i+5)*256, arg(i+6)+arg(i+7)*256
+		r.block(fmt.Sprintf("[image %dx%d]", w*8, h))
+		return 7 + w*h
+	case '(':
+		n := arg(i+3) + arg(i+4)*256
+		switch arg(i + 2) {
+		case 'k': // 2D codes: GS ( k pL pH cn fn ...
+			cn, fn := byte(arg(i+5)), arg(i+6)
+			switch fn {
+			case 80: // Store data
+				if end := i + 5 + n; end <= len(data) && i+8 <= end {
+					if r.symbols == nil {
+						r.symbols = map[byte]string{}
+					}
+					r.symbols[cn] = string(data[i+8 : end])
+				}
+			case 81: // Print symbol
+				name := map[byte]string{48: "PDF417", 49: "QR", 54: "DataMatrix"}[cn]
+				if name == "" {
+					name = "2D code"
+				}
+				r.block(fmt.Sprintf("[%s: %s]", name, r.symbols[cn]))
+			}
+		case 'L': // Graphics: GS ( L pL pH m fn ...
+			switch arg(i + 6) {
+			case 50, 2:
+				r.block("[image]")
+			case 69: // Print NV graphics kc1 kc2
+				r.block(fmt.Sprintf("[logo %c%c]", arg(i+7), arg(i+8)))
+			}
+		}
+		return 4 + n
+	case '8': // GS 8 L p1 p2 p3 p4 ... (large graphics)
+		n := arg(i+3) | arg(i+4)<<8 | arg(i+5)<<16 | arg(i+6)<<24
+		return 6 + n
+	case 'k': // Barcode
+		m := arg(i + 2)
+		if m <= 6 {
+			// Data terminated by NUL
+			end := i + 3
+			for end < len(data) && data[end] != 0 {
+				end++
+			}
+			r.block(fmt.Sprintf("[barcode: %s]", data[i+3:min(end, len(data))]))
+			return end - i
+		}
+		n := arg(i + 3)
+		end := min(i+4+n, len(data))
+		code := string(data[min(i+4, end):end])
+		if m == 73 && len(code) >= 2 && code[0] == '{' {
+			code = strings.NewReplacer("{A", "", "{B", "", "{C", "").Replace(code)
+		}
+		r.block(fmt.Sprintf("[barcode: %s]", code))
+		return 3 + n
+	case 'B', 'H', 'f', 'h', 'w', 'L', 'W', 'b', 'a', 'r', 'I', '$':
+		switch arg(i + 1) {
+		case 'L', 'W', '$':
+			return 3
+		}
+		return 2
+	}
+	return 1
+}
+
+// setBold toggles bold; the change is marked with ** when text follows.
+func (r *textRenderer) setBold(on bool) {
+	r.flushText()
+	r.bold = on
+}
+
+func (r *textRenderer) setSizeW(w int) {
+	r.flushText()
+	if w < 1 {
+		w = 1
+	}
+	r.sizeW = w
+}
+
+// flushText decodes pending text bytes into the current line.
+func (r *textRenderer) flushText() {
+	if len(r.text) == 0 {
+		return
+	}
+
+	var s string
+	if r.enc == nil {
+		s = strings.ToValidUTF8(string(r.text), "?")
+	} else {
+		runes := make([]rune, 0, len(r.text))
+		for _, b := range r.text {
+			runes = append(runes, decodeByte(r.enc, b))
+		}
+		s = string(runes)
+	}
+	r.text = r.text[:0]
+	r.write(s, DisplayWidth(s)*r.sizeW)
+}
+
+func (r *textRenderer) write(s string, cols int) {
+	if r.bold != r.marked {
+		r.line.WriteString("**")
+		r.marked = r.bold
+	}
+	r.line.WriteString(s)
+	r.cols += cols
+}
+
+// newline ends the current line, padding it for the alignment.
+func (r *textRenderer) newline() {
+	r.flushText()
+
+	line := r.line.String()
+	if r.marked {
+		// Close bold at the end of the line; write reopens it on the next
+		line += "**"
+		r.marked = false
+	}
+
+	pad := r.width - r.cols
+	switch {
+	case pad <= 0 || line == "":
+	case r.align == 1:
+		line = strings.Repeat(" ", pad/2) + line
+	case r.align == 2:
+		line = strings.Repeat(" ", pad) + line
+	}
+	r.out.WriteString(strings.TrimRight(line, " "))
+	r.out.WriteByte('\n')
+
+	r.line.Reset()
+	r.cols = 0
+}
+
+// block prints a placeholder on a line of its own.
+func (r *textRenderer) block(s string) {
+	r.flushText()
+	if r.cols > 0 {
+		r.newline()
+	}
+	bold := r.bold
+	r.bold = false
+	r.write(s, utf8.RuneCountInString(s))
+	r.newline()
+	r.bold = bold
+}
+
+// decodeByte maps a code page byte back to its rune.
+func decodeByte(enc runeEncoder, b byte) rune {
+	if b < utf8.RuneSelf {
+		return rune(b)
+	}
+	switch e := enc.(type) {
+	case *charmap.Charmap:
+		return e.DecodeByte(b)
+	case codePage857:
+		for r, c := range e {
+			if c == b {
+				return r
+			}
+		}
+	}
+	return '?'
+}
@@ -0,0 +1,152 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// runeEncoder maps a single rune to its byte in a single-byte code page.
+type runeEncoder interface {
+	EncodeRune(r rune) (byte, bool)
+}
+
+// Supported encodings for SetEncoding. Names are matched case-insensitively.
+// Remember to also select the matching table on the printer with CodePage(),
+// since table numbers differ between printer models.
+//
+//	UTF-8 (default, no conversion)
+//	CP437, CP850, CP852, CP857 (Turkish), CP858, CP860, CP863, CP865, CP866
+//	CP1250, CP1251, CP1252, CP1253, CP1254 (Turkish), CP1255, CP1256, CP1257, CP1258
+//	ISO-8859-9 (Latin-5 Turkish)
+var encodings = map[string]runeEncoder{
+	"CP437":      charmap.CodePage437,
+	"CP850":      charmap.CodePage850,
+	"CP852":      charmap.CodePage852,
+	"CP857":      pc857,
+	"CP858":      charmap.CodePage858,
+	"CP860":      charmap.CodePage860,
+	"CP863":      charmap.CodePage863,
+	"CP865":      charmap.CodePage865,
+	"CP866":      charmap.CodePage866,
+	"CP1250":     charmap.Windows1250,
+	"CP1251":     charmap.Windows1251,
+	"CP1252":     charmap.Windows1252,
+	"CP1253":     charmap.Windows1253,
+	"CP1254":     charmap.Windows1254,
+	"CP1255":     charmap.Windows1255,
+	"CP1256":     charmap.Windows1256,
+	"CP1257":     charmap.Windows1257,
+	"CP1258":     charmap.Windows1258,
+	"ISO-8859-9": charmap.ISO8859_9,
+}
+
+// normalizeEncoding maps aliases like "pc857" or "windows-1254" to the
+// names used in the encodings table.
+func normalizeEncoding(name string) string {
+	n := strings.ToUpper(strings.TrimSpace(name))
+	n = strings.ReplaceAll(n, "_", "-")
+	switch {
+	case n == "UTF8":
+		return "UTF-8"
+	case strings.HasPrefix(n, "PC"):
+		return "CP" + n[2:]
+	case strings.HasPrefix(n, "WINDOWS-"):
+		return "CP" + n[8:]
+	case strings.HasPrefix(n, "WPC"):
+		return "CP" + n[3:]
+	case n == "ISO8859-9":
+		return "ISO-8859-9"
+	}
+	return n
+}
+
+// SetEncoding sets the code page that Text, Println and DrawLine convert
+// UTF-8 strings to. Runes that don't exist in the code page print as '?'.
+// Use "UTF-8" to disable conversion.
+func (p *Printer) SetEncoding(name string) error {
+	n := normalizeEncoding(name)
+	if n != "UTF-8" {
+		if _, ok := encodings[n]; !ok {
+			return fmt.Errorf("unsupported encoding: %s", name)
+		}
+	}
+	p.encoding = n
+	return nil
+}
+
+// Encoding returns the current text encoding name.
+func (p *Printer) Encoding() string {
+	return p.encoding
+}
+
+// encode converts s to the printer's current encoding.
+func (p *Printer) encode(s string) []byte {
+	enc, ok := encodings[p.encoding]
+	if !ok {
+		return []byte(s)
+	}
+
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+			continue
+		}
+		if b, ok := enc.EncodeRune(r); ok {
+			out = append(out, b)
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// canEncode reports whether every rune in s exists in the current
+// encoding. With UTF-8, strings are sent unchanged and always can.
+func (p *Printer) canEncode(s string) bool {
+	enc, ok := encodings[p.encoding]
+	if !ok {
+		return true
+	}
+	for _, r := range s {
+		if _, ok := enc.EncodeRune(r); r >= utf8.RuneSelf && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// codePage857 implements IBM PC857 (Turkish), which x/text does not provide.
+type codePage857 map[rune]byte
+
+func (c codePage857) EncodeRune(r rune) (byte, bool) {
+	if r < 0x80 {
+		return byte(r), true
+	}
+	b, ok := c[r]
+	return b, ok
+}
+
+// pc857 holds the upper half (0x80-0xFF) of PC857; 0 marks undefined slots.
+var pc857 = func() codePage857 {
+	upper := [128]rune{
+		'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ı', 'Ä', 'Å',
+		'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'İ', 'Ö', 'Ü', 'ø', '£', 'Ø', 'Ş', 'ş',
+		'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'Ğ', 'ğ', '¿', '®', '¬', '½', '¼', '¡', '«', '»',
+		'░', '▒', '▓', '│', '┤', 'Á', 'Â', 'À', '©', '╣', '║', '╗', '╝', '¢', '¥', '┐',
+		'└', '┴', '┬', '├', '─', '┼', 'ã', 'Ã', '╚', '╔', '╩', '╦', '╠', '═', '╬', '¤',
+		'º', 'ª', 'Ê', 'Ë', 'È', 0, 'Í', 'Î', 'Ï', '┘', '┌', '█', '▄', '¦', 'Ì', '▀',
+		'Ó', 'ß', 'Ô', 'Ò', 'õ', 'Õ', 'µ', 0, '×', 'Ú', 'Û', 'Ù', 'ì', 'ÿ', '¯', '´',
+		'\u00ad', '±', 0, '¾', '¶', '§', '÷', '¸', '°', '¨', '·', '¹', '³', '²', '■', '\u00a0',
+	}
+	c := make(codePage857, len(upper))
+	for i, r := range upper {
+		if r != 0 {
+			c[r] = byte(0x80 + i)
+		}
+	}
+	return c
+}()
@@ -0,0 +1,105 @@
+package printer
+
+import "fmt"
+
+// Labels holds the fixed text printed on template orders. Labels that are
+// followed by a value (e.g. "Tel") are printed as "Label: value".
+type Labels struct {
+	Receipt      string // Subtitle under the platform name
+	OrderTime    string
+	OrderType    string
+	Customer     string // Customer section heading
+	Name         string
+	Phone        string
+	Address      string
+	FloorApt     string // Format with floor and apartment numbers, e.g. "Floor: %d, Apt: %d"
+	AddressNote  string
+	Items        string // Items section heading
+	Subtotal     string
+	DeliveryFee  string
+	ServiceFee   string
+	Discount     string
+	Tip          string
+	VATIncluded  string
+	Total        string
+	Payment      string
+	CustomerNote string // Customer note heading
+	Footer       string // Closing line
+}
+
+// DefaultLanguage is the label set used when none is configured.
+const DefaultLanguage = "tr"
+
+// Locales maps language codes to label sets. To add a language, add an
+// entry with every field filled in.
+var Locales = map[string]Labels{
+	"tr": {
+		Receipt:      "Sipariş Fişi",
+		OrderTime:    "Sipariş Zamanı",
+		OrderType:    "Sipariş Tipi",
+		Customer:     "MÜŞTERİ BİLGİLERİ",
+		Name:         "Ad",
+		Phone:        "Tel",
+		Address:      "Adres",
+		FloorApt:     "Kat: %d, Daire: %d",
+		AddressNote:  "Not",
+		Items:        "SİPARİŞ DETAYI",
+		Subtotal:     "Ara Toplam",
+		DeliveryFee:  "Paket Servis",
+		ServiceFee:   "Hizmet Bedeli",
+		Discount:     "İndirim",
+		Tip:          "Bahşiş",
+		VATIncluded:  "(KDV Dahil)",
+		Total:        "TOPLAM",
+		Payment:      "Ödeme",
+		CustomerNote: "MÜŞTERİ NOTU",
+		Footer:       "Afiyet olsun!",
+	},
+	"en": {
+		Receipt:      "Order Receipt",
+		OrderTime:    "Order Time",
+		OrderType:    "Order Type",
+		Customer:     "CUSTOMER",
+		Name:         "Name",
+		Phone:        "Phone",
+		Address:      "Address",
+		FloorApt:     "Floor: %d, Apt: %d",
+		AddressNote:  "Note",
+		Items:        "ORDER DETAILS",
+		Subtotal:     "Subtotal",
+		DeliveryFee:  "Delivery",
+		ServiceFee:   "Service Fee",
+		Discount:     "Discount",
+		Tip:          "Tip",
+		VATIncluded:  "(VAT included)",
+		Total:        "TOTAL",
+		Payment:      "Payment",
+		CustomerNote: "CUSTOMER NOTE",
+		Footer:       "Enjoy your meal!",
+	},
+}
+
+// SetLanguage selects the label set used for template orders.
+func (p *Printer) SetLanguage(lang string) error {
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	if _, ok := Locales[lang]; !ok {
+		return fmt.Errorf("unsupported language %q", lang)
+	}
+	p.language = lang
+	return nil
+}
+
+// Language returns the selected label language.
+func (p *Printer) Language() string {
+	if p.language == "" {
+		return DefaultLanguage
+	}
+	return p.language
+}
+
+// labels returns the label set for the selected language.
+func (p *Printer) labels() Labels {
+	return Locales[p.Language()]
+}
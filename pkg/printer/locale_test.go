@@ -0,0 +1,22 @@
+package printer
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"printbridge/pkg/config"
+)
+
+// The config accepts, and /config/schema offers, exactly the languages
+// there are labels for.
+func TestLocalesMatchConfigLanguages(t *testing.T) {
+	var langs []string
+	for lang := range Locales {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	if !reflect.DeepEqual(langs, config.Languages) {
+		t.Errorf("Locales has %q, config.Languages is %q", langs, config.Languages)
+	}
+}
@@ -0,0 +1,163 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/bmp"
+)
+
+// LogosDir is the directory, relative to the templates directory, that
+// uploaded logos are stored in.
+const LogosDir = "logos"
+
+// LogoInfo describes a logo stored in the logos directory.
+type LogoInfo struct {
+	Platform string `json:"platform"` // Template key the logo is printed for
+	Path     string `json:"path"`     // Relative to the templates directory
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int64  `json:"size"` // File size in bytes
+}
+
+// UploadedLogoPath returns the path, relative to the templates directory,
+// that the logo uploaded for a platform is stored at. It is the path the
+// built-in templates load their logo from.
+func UploadedLogoPath(platform string) string {
+	return filepath.ToSlash(filepath.Join(LogosDir, NormalizePlatform(platform)+".bmp"))
+}
+
+// TemplateLogoPath returns the logo printed with tmpl: the uploaded logo for
+// its platform if there is one, otherwise the template's own logo.
+func TemplateLogoPath(templatesDir string, tmpl Template) string {
+	uploaded := UploadedLogoPath(tmpl.ID)
+	if _, err := os.Stat(filepath.Join(templatesDir, uploaded)); err == nil {
+		return uploaded
+	}
+	return tmpl.LogoPath
+}
+
+// ValidateLogo checks that a logo fits the paper, which is maxDots wide.
+// Logos may be as tall as the paper is wide.
+func ValidateLogo(img image.Image, maxDots int) error {
+	b := img.Bounds()
+	return checkLogoBounds(b.Dx(), b.Dy(), maxDots)
+}
+
+// ValidateLogoData checks the size an encoded logo declares, like
+// ValidateLogo, without decoding its pixels. Use it before DecodeLogo, so
+// a small file declaring a huge image is rejected before it is allocated.
+func ValidateLogoData(data []byte, maxDots int) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode logo (use PNG, JPEG, GIF or BMP): %w", err)
+	}
+	return checkLogoBounds(cfg.Width, cfg.Height, maxDots)
+}
+
+func checkLogoBounds(width, height, maxDots int) error {
+	if width < 1 || height < 1 {
+		return errors.New("logo is empty")
+	}
+	if width > maxDots || height > maxDots {
+		return fmt.Errorf("logo is %dx%d pixels, at most %dx%d fits the paper", width, height, maxDots, maxDots)
+	}
+	return nil
+}
+
+// checkLogoPlatform rejects platform names that would put the logo file
+// outside the logos directory.
+func checkLogoPlatform(platform string) error {
+	name := NormalizePlatform(platform)
+	if name == "" || strings.ContainsAny(name, `/\:`) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid platform name %q", platform)
+	}
+	return nil
+}
+
+// SaveLogo stores img as the logo of a platform, converted to BMP, and
+// returns the path it was stored at relative to the templates directory.
+func SaveLogo(templatesDir, platform string, img image.Image) (string, error) {
+	if err := checkLogoPlatform(platform); err != nil {
+		return "", err
+	}
+	rel := UploadedLogoPath(platform)
+	path := filepath.Join(templatesDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create logos directory: %w", err)
+	}
+
+	// Write to a temporary file first, so a failed write doesn't leave a
+	// broken logo behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".logo-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to save logo: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := bmp.Encode(tmp, img); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to encode logo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to save logo: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to save logo: %w", err)
+	}
+	return rel, nil
+}
+
+// DeleteLogo removes the uploaded logo of a platform. It returns an error
+// wrapping os.ErrNotExist if there is none.
+func DeleteLogo(templatesDir, platform string) error {
+	if err := checkLogoPlatform(platform); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(templatesDir, UploadedLogoPath(platform))); err != nil {
+		return fmt.Errorf("failed to delete logo: %w", err)
+	}
+	return nil
+}
+
+// ListLogos returns the logos in the logos directory sorted by platform.
+// Files that aren't images are skipped.
+func ListLogos(templatesDir string) ([]LogoInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(templatesDir, LogosDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []LogoInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logos directory: %w", err)
+	}
+
+	logos := []LogoInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || LogoFormatFromExt(name) == "" {
+			continue
+		}
+		rel := filepath.ToSlash(filepath.Join(LogosDir, name))
+		info := LogoInfo{
+			Platform: strings.TrimSuffix(name, filepath.Ext(name)),
+			Path:     rel,
+		}
+		if fi, err := entry.Info(); err == nil {
+			info.Size = fi.Size()
+		}
+		if f, err := os.Open(filepath.Join(templatesDir, rel)); err == nil {
+			if cfg, _, err := image.DecodeConfig(f); err == nil {
+				info.Width, info.Height = cfg.Width, cfg.Height
+			}
+			f.Close()
+		}
+		logos = append(logos, info)
+	}
+	sort.Slice(logos, func(i, j int) bool { return logos[i].Platform < logos[j].Platform })
+	return logos, nil
+}
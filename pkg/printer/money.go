@@ -0,0 +1,183 @@
+package printer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money describes how amounts are printed.
+type Money struct {
+	Symbol      string // Currency symbol, e.g. "₺"
+	ASCII       string // Printed instead of Symbol when the encoding lacks it, e.g. "TL"
+	SymbolAfter bool   // "12,50 ₺" rather than "$12.50"
+	Space       bool   // Space between the amount and the symbol
+	Decimal     string // Decimal separator
+	Thousands   string // Thousands separator; "" for none
+	Decimals    int    // Digits after the decimal separator
+}
+
+// Formats used when no currency is configured.
+var (
+	DefaultOrderMoney   = Money{Symbol: "TL", SymbolAfter: true, Space: true, Decimal: ".", Decimals: 2} // Template orders: "1234.56 TL"
+	DefaultReceiptMoney = Money{Symbol: "$", Decimal: ".", Decimals: 2}                                  // Receipts and the test print: "$1234.56"
+)
+
+// Format returns amount with the currency symbol, e.g. "1.234,56 ₺".
+// Negative amounts start with a minus sign.
+func (m Money) Format(amount float64) string {
+	sign := ""
+	if amount < 0 && math.Round(amount*math.Pow10(m.Decimals)) != 0 {
+		sign = "-"
+	}
+	return sign + m.format(math.Abs(amount))
+}
+
+// FormatSigned is like Format but also marks positive amounts with a plus
+// sign, for price changes such as item options.
+func (m Money) FormatSigned(amount float64) string {
+	if amount < 0 {
+		return m.Format(amount)
+	}
+	return "+" + m.format(amount)
+}
+
+func (m Money) format(amount float64) string {
+	digits := strconv.FormatFloat(amount, 'f', m.Decimals, 64)
+	whole, frac, _ := strings.Cut(digits, ".")
+
+	if m.Thousands != "" {
+		var b strings.Builder
+		for i, d := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				b.WriteString(m.Thousands)
+			}
+			b.WriteRune(d)
+		}
+		whole = b.String()
+	}
+
+	number := whole
+	if frac != "" {
+		number += m.Decimal + frac
+	}
+
+	space := ""
+	if m.Space {
+		space = " "
+	}
+	if m.SymbolAfter {
+		return number + space + m.Symbol
+	}
+	return m.Symbol + space + number
+}
+
+// currency describes an ISO 4217 currency.
+type currency struct {
+	symbol   string
+	ascii    string // Fallback for code pages without the symbol
+	decimals int
+	locale   string // Locale used when none is configured
+}
+
+// Currencies supported by NewMoney, by ISO 4217 code.
+var currencies = map[string]currency{
+	"TRY": {"₺", "TL", 2, "tr-TR"},
+	"USD": {"$", "$", 2, "en-US"},
+	"EUR": {"€", "EUR", 2, "de-DE"},
+	"GBP": {"£", "GBP", 2, "en-GB"},
+	"CHF": {"CHF", "CHF", 2, "de-CH"},
+	"JPY": {"¥", "JPY", 0, "ja-JP"},
+	"BRL": {"R$", "R$", 2, "pt-BR"},
+	"AZN": {"₼", "AZN", 2, "az-AZ"},
+}
+
+// moneyLocale holds a locale's separators and symbol placement.
+type moneyLocale struct {
+	decimal     string
+	thousands   string
+	symbolAfter bool
+	space       bool
+}
+
+// moneyLocales maps languages, or language-region tags where a region
+// differs from its language, to number formats.
+var moneyLocales = map[string]moneyLocale{
+	"en":    {".", ",", false, false},
+	"tr":    {",", ".", true, true},
+	"de":    {",", ".", true, true},
+	"de-CH": {".", "'", false, true},
+	"fr":    {",", " ", true, true},
+	"es":    {",", ".", true, true},
+	"it":    {",", ".", true, true},
+	"nl":    {",", ".", false, true},
+	"pt":    {",", ".", true, true},
+	"pt-BR": {",", ".", false, true},
+	"ja":    {".", ",", false, false},
+	"az":    {",", " ", true, true},
+}
+
+// NewMoney returns the format for a currency (ISO 4217 code, e.g. "TRY")
+// in a locale (e.g. "tr-TR"). An empty locale uses the currency's home
+// locale.
+func NewMoney(code, locale string) (Money, error) {
+	c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency %q", code)
+	}
+	if locale == "" {
+		locale = c.locale
+	}
+
+	tag := strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	lang, region, _ := strings.Cut(tag, "-")
+	lang = strings.ToLower(lang)
+	l, ok := moneyLocales[lang+"-"+strings.ToUpper(region)]
+	if !ok {
+		l, ok = moneyLocales[lang]
+	}
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported locale %q", locale)
+	}
+
+	return Money{
+		Symbol:      c.symbol,
+		ASCII:       c.ascii,
+		SymbolAfter: l.symbolAfter,
+		Space:       l.space,
+		Decimal:     l.decimal,
+		Thousands:   l.thousands,
+		Decimals:    c.decimals,
+	}, nil
+}
+
+// SetMoney sets the currency and locale amounts are printed in; see
+// NewMoney. An empty currency restores the defaults, DefaultOrderMoney on
+// template orders and DefaultReceiptMoney on receipts.
+func (p *Printer) SetMoney(code, locale string) error {
+	if code == "" {
+		p.money = nil
+		return nil
+	}
+	m, err := NewMoney(code, locale)
+	if err != nil {
+		return err
+	}
+	p.money = &m
+	return nil
+}
+
+// Money returns the configured money format, or def if none is set. If the
+// printer's encoding can't print the currency symbol, the format uses its
+// ASCII fallback instead.
+func (p *Printer) Money(def Money) Money {
+	m := def
+	if p.money != nil {
+		m = *p.money
+	}
+	if m.ASCII != "" && !p.canEncode(m.Symbol) {
+		m.Symbol = m.ASCII
+	}
+	return m
+}
@@ -0,0 +1,87 @@
+package printer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OrderTimeFormat is the layout order times are printed in.
+const OrderTimeFormat = "02.01.2006 15:04"
+
+// orderTimeLayouts are the timestamp formats seen from delivery platforms,
+// tried in order. Layouts without a zone are read in the printer's timezone.
+var orderTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02-01-2006 15:04:05",
+	"02-01-2006 15:04",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// ParseOrderTime parses a platform timestamp: any of the known layouts, or
+// Unix epoch seconds or milliseconds. Timestamps without a zone are taken
+// to be in loc (time.Local if nil).
+func ParseOrderTime(s string, loc *time.Location) (time.Time, bool) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	if isDigits(s) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// 13+ digits is milliseconds; seconds won't reach that until year 33658
+		if len(s) >= 13 {
+			return time.UnixMilli(n), true
+		}
+		return time.Unix(n, 0), true
+	}
+
+	for _, layout := range orderTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// SetTimezone sets the IANA timezone (e.g. "Europe/Istanbul") order times
+// are printed in. An empty name uses the system's local time.
+func (p *Printer) SetTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	p.location = loc
+	return nil
+}
+
+// formatOrderTime normalizes an order timestamp to OrderTimeFormat in the
+// printer's timezone, returning it unchanged if it can't be parsed.
+func (p *Printer) formatOrderTime(s string) string {
+	loc := p.location
+	if loc == nil {
+		loc = time.Local
+	}
+	if t, ok := ParseOrderTime(s, loc); ok {
+		return t.In(loc).Format(OrderTimeFormat)
+	}
+	return s
+}
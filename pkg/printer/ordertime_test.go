@@ -0,0 +1,58 @@
+package printer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatOrderTime(t *testing.T) {
+	// All samples are 15 March 2024, 21:30 in Istanbul (18:30 UTC)
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"Getir, UTC with milliseconds", "2024-03-15T18:30:00.000Z"},
+		{"Yemeksepeti, RFC 3339 with offset", "2024-03-15T21:30:00+03:00"},
+		{"Trendyol Go, epoch milliseconds", "1710527400000"},
+		{"epoch seconds", "1710527400"},
+		{"Migros Yemek, Turkish local time", "15.03.2024 21:30"},
+		{"local time with seconds", "2024-03-15 21:30:00"},
+		{"local ISO without zone", "2024-03-15T21:30:00"},
+		{"offset without colon", "2024-03-15T18:30:00+0000"},
+		{"slashes", "15/03/2024 21:30"},
+		{"RFC 1123", "Fri, 15 Mar 2024 18:30:00 UTC"},
+		{"surrounding spaces", "  15.03.2024 21:30:00 "},
+	}
+
+	p := newTestPrinter()
+	if err := p.SetTimezone("Europe/Istanbul"); err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	for _, tt := range tests {
+		if got := p.formatOrderTime(tt.in); got != "15.03.2024 21:30" {
+			t.Errorf("%s: formatOrderTime(%q) = %q, want 15.03.2024 21:30", tt.name, tt.in, got)
+		}
+	}
+}
+
+func TestFormatOrderTimeConvertsToTimezone(t *testing.T) {
+	p := newTestPrinter()
+	if err := p.SetTimezone("America/New_York"); err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	if got := p.formatOrderTime("2024-03-15T18:30:00Z"); got != "15.03.2024 14:30" {
+		t.Errorf("formatOrderTime in New York = %q, want 15.03.2024 14:30", got)
+	}
+}
+
+func TestFormatOrderTimeUnparsable(t *testing.T) {
+	p := newTestPrinter()
+	for _, in := range []string{"", "yesterday", "2024-13-45T99:00:00Z"} {
+		if got := p.formatOrderTime(in); got != in {
+			t.Errorf("formatOrderTime(%q) = %q, want it unchanged", in, got)
+		}
+	}
+	if _, ok := ParseOrderTime("soon", time.UTC); ok {
+		t.Error("ParseOrderTime accepted \"soon\"")
+	}
+}
@@ -1,9 +1,14 @@
 package printer
 
 import (
+	"errors"
 	"fmt"
+	"image"
+	"strings"
+	"time"
 
 	"printbridge/pkg/adapter"
+	"printbridge/pkg/metrics"
 )
 
 // Printer provides a fluent API for building ESC/POS print jobs.
@@ -12,6 +17,25 @@ type Printer struct {
 	buffer   []byte
 	encoding string
 	width    int
+	sizeW    int // Current character width multiplier (GS !)
+	italic   bool
+
+	paperWidth int // Font A characters per line for the loaded paper
+
+	imageThreshold uint32
+	location       *time.Location // Timezone for printed order times; nil is local
+	language       string         // Key into Locales for template labels
+	beepVariant    string         // Buzzer command used by Beep
+	money          *Money         // Amount format; nil uses the per-receipt defaults
+
+	defaultFont        string // Font selected by Init; "" leaves the printer's
+	defaultLineSpacing int    // Line spacing in dots set by Init; 0 leaves the printer's
+	densityCommand     string // DensityGSK or DensityDC2
+	defaultDensity     int    // Density set by Init; 0 leaves the printer's
+	defaultSpeed       int    // Print speed set by Init; 0 leaves the printer's
+
+	written int  // Bytes handed to the adapter by Flush
+	opened  bool // Flush has seen the adapter open, so reopening it is a reconnect
 }
 
 // New creates a new Printer with the given adapter.
@@ -21,27 +45,272 @@ func New(a adapter.Adapter) *Printer {
 		buffer:   make([]byte, 0, 1024),
 		encoding: "UTF-8",
 		width:    48, // Default character width for 80mm paper
+		sizeW:    1,
+
+		paperWidth:     48,
+		imageThreshold: DefaultImageThreshold,
+	}
+}
+
+// WithAdapter returns a Printer with the same settings as p that writes
+// to a, with an empty buffer. Used to render jobs without printing them.
+func (p *Printer) WithAdapter(a adapter.Adapter) *Printer {
+	c := *p
+	c.adapter = a
+	c.buffer = make([]byte, 0, 1024)
+	c.written = 0
+	c.opened = false
+	return &c
+}
+
+// SetImageThreshold sets the luminance cutoff (0-65535) used when rasterizing
+// template logos. Values above 65535 are clamped.
+func (p *Printer) SetImageThreshold(threshold uint32) {
+	if threshold > 65535 {
+		threshold = 65535
 	}
+	p.imageThreshold = threshold
+}
+
+// ImageThreshold returns the luminance cutoff used when rasterizing images.
+func (p *Printer) ImageThreshold() uint32 {
+	return p.imageThreshold
 }
 
-// Init initializes the printer.
+// NewWithWidth creates a new Printer for paper that fits chars characters
+// per line in Font A (32 for 58mm, 48 for 80mm).
+func NewWithWidth(a adapter.Adapter, chars int) *Printer {
+	p := New(a)
+	p.SetWidth(chars)
+	return p
+}
+
+// PaperWidthChars returns the Font A characters per line for a paper width
+// in millimeters: 32 for 58mm paper, 48 for 80mm (the default).
+func PaperWidthChars(mm int) int {
+	if mm > 0 && mm < 80 {
+		return 32
+	}
+	return 48
+}
+
+// SetWidth sets the paper width as Font A characters per line.
+// Font B/C widths are derived from it (e.g. 48 -> 64, 32 -> 42).
+func (p *Printer) SetWidth(chars int) {
+	if chars < 1 {
+		chars = 48
+	}
+	p.paperWidth = chars
+	p.width = chars
+}
+
+// PaperWidth returns the paper width as Font A characters per line.
+func (p *Printer) PaperWidth() int {
+	return p.paperWidth
+}
+
+// PaperDots returns the printable width in dots for the current paper
+// (12 dots per Font A character: 576 dots for 80mm, 384 for 58mm).
+func (p *Printer) PaperDots() int {
+	return p.paperWidth * 12
+}
+
+// Init initializes the printer, then applies the default font and line
+// spacing, if set. Later Font, Size and LineSpacing calls override them.
 func (p *Printer) Init() *Printer {
 	p.buffer = append(p.buffer, HW_INIT...)
+	p.width = p.paperWidth
+	p.sizeW = 1
+	p.italic = false
+	if p.defaultFont != "" {
+		p.Font(p.defaultFont)
+	}
+	if p.defaultLineSpacing > 0 {
+		p.LineSpacing(p.defaultLineSpacing)
+	}
+	if p.defaultDensity != 0 {
+		p.SetDensity(p.defaultDensity)
+	}
+	if p.defaultSpeed != 0 {
+		p.SetPrintSpeed(p.defaultSpeed)
+	}
+	return p
+}
+
+// SetDefaultFont sets the font ("a", "b" or "c") selected by every Init;
+// "" keeps the printer's power-on font.
+func (p *Printer) SetDefaultFont(font string) error {
+	switch font {
+	case "", "a", "A", "b", "B", "c", "C":
+		p.defaultFont = font
+		return nil
+	}
+	return fmt.Errorf("unknown font %q (use a, b or c)", font)
+}
+
+// SetDensityCommand selects the density and speed commands (DensityGSK or
+// DensityDC2); empty selects DensityGSK.
+func (p *Printer) SetDensityCommand(variant string) error {
+	switch variant {
+	case "", DensityGSK, DensityDC2:
+		p.densityCommand = variant
+		return nil
+	}
+	return fmt.Errorf("unknown density command %q (use %s or %s)", variant, DensityGSK, DensityDC2)
+}
+
+// SetDefaultDensity sets the print density applied by every Init, clamped
+// to MinDensity-MaxDensity; 0 keeps the printer's.
+func (p *Printer) SetDefaultDensity(level int) {
+	p.defaultDensity = max(MinDensity, min(level, MaxDensity))
+}
+
+// SetDefaultPrintSpeed sets the print speed applied by every Init, clamped
+// to MinSpeed-MaxSpeed; 0 keeps the printer's.
+func (p *Printer) SetDefaultPrintSpeed(level int) {
+	if level != 0 {
+		level = max(MinSpeed, min(level, MaxSpeed))
+	}
+	p.defaultSpeed = level
+}
+
+// SetDensity sets the print density from MinDensity (lightest) to
+// MaxDensity (darkest), clamped; 0 is the printer's standard density.
+// Higher density fixes faded prints at the cost of speed and print head wear.
+func (p *Printer) SetDensity(level int) *Printer {
+	p.buffer = append(p.buffer, DensityCommand(p.densityCommand, level)...)
 	return p
 }
 
+// SetPrintSpeed sets the print speed from MinSpeed (slowest, darkest) to
+// MaxSpeed (fastest), clamped.
+func (p *Printer) SetPrintSpeed(level int) *Printer {
+	p.buffer = append(p.buffer, SpeedCommand(p.densityCommand, level)...)
+	return p
+}
+
+// SetDefaultLineSpacing sets the line spacing in dots (1-255) applied by
+// every Init; 0 keeps the printer's default of 1/6 inch.
+func (p *Printer) SetDefaultLineSpacing(dots int) error {
+	if dots < 0 || dots > 255 {
+		return fmt.Errorf("line spacing must be between 0 and 255 dots")
+	}
+	p.defaultLineSpacing = dots
+	return nil
+}
+
+// LineWidth returns the number of characters that fit on one line with the
+// current font and size.
+func (p *Printer) LineWidth() int {
+	if p.sizeW < 1 {
+		return p.width
+	}
+	return p.width / p.sizeW
+}
+
 // Text adds text to the buffer.
 func (p *Printer) Text(content string) *Printer {
-	p.buffer = append(p.buffer, []byte(content)...)
+	p.buffer = append(p.buffer, p.encode(content)...)
 	return p
 }
 
 // Println adds text with a newline.
 func (p *Printer) Println(content string) *Printer {
-	p.buffer = append(p.buffer, []byte(content+EOL)...)
+	p.buffer = append(p.buffer, p.encode(content+EOL)...)
 	return p
 }
 
+// PrintlnWrapped prints content word-wrapped to the current line width.
+// Words longer than a line are hard-broken.
+func (p *Printer) PrintlnWrapped(content string) *Printer {
+	for _, line := range WrapText(content, p.LineWidth()) {
+		p.Println(line)
+	}
+	return p
+}
+
+// Row prints left-justified and right-justified text on one line, padded to
+// fill the current line width exactly. If both don't fit, the left side is
+// truncated.
+func (p *Printer) Row(left, right string) *Printer {
+	width := p.LineWidth()
+	right = TruncateWidth(right, width)
+	rightWidth := DisplayWidth(right)
+
+	leftMax := width - rightWidth - 1
+	if rightWidth == 0 {
+		leftMax = width
+	}
+	left = TruncateWidth(left, leftMax)
+
+	pad := width - DisplayWidth(left) - rightWidth
+	if pad < 0 {
+		pad = 0
+	}
+	return p.Println(left + strings.Repeat(" ", pad) + right)
+}
+
+// Column describes one cell of a Columns row.
+type Column struct {
+	Text  string
+	Width int    // Width in characters; 0 shares the remaining space
+	Align string // "left", "center" or "right" (default left)
+}
+
+// Columns prints a row of cells that together fill the current line width.
+// Columns with Width 0 split whatever space the fixed-width columns leave.
+// Text longer than its column is truncated.
+func (p *Printer) Columns(cols ...Column) *Printer {
+	if len(cols) == 0 {
+		return p
+	}
+
+	width := p.LineWidth()
+	widths := make([]int, len(cols))
+	remaining := width
+	flexible := 0
+	for i, c := range cols {
+		if c.Width > 0 {
+			widths[i] = c.Width
+			remaining -= c.Width
+		} else {
+			flexible++
+		}
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	// Distribute the remaining space, giving any leftover to the last flexible column
+	for i, c := range cols {
+		if c.Width > 0 {
+			continue
+		}
+		flexible--
+		if flexible == 0 {
+			widths[i] = remaining
+		} else {
+			widths[i] = remaining / (flexible + 1)
+			remaining -= widths[i]
+		}
+	}
+
+	var line strings.Builder
+	for i, c := range cols {
+		text := TruncateWidth(c.Text, widths[i])
+		pad := widths[i] - DisplayWidth(text)
+		switch c.Align {
+		case "right", "rt", "RT":
+			line.WriteString(strings.Repeat(" ", pad) + text)
+		case "center", "ct", "CT":
+			line.WriteString(strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2))
+		default:
+			line.WriteString(text + strings.Repeat(" ", pad))
+		}
+	}
+	return p.Println(strings.TrimRight(line.String(), " "))
+}
+
 // NewLine adds a line feed.
 func (p *Printer) NewLine() *Printer {
 	p.buffer = append(p.buffer, CTL_LF...)
@@ -95,6 +364,7 @@ func (p *Printer) Underline(mode int) *Printer {
 // Size sets custom text size (1-8 for width and height).
 func (p *Printer) Size(width, height int) *Printer {
 	p.buffer = append(p.buffer, TxtCustomSize(width, height)...)
+	p.sizeW = min(max(width, 1), 8)
 	return p
 }
 
@@ -103,37 +373,198 @@ func (p *Printer) Font(font string) *Printer {
 	switch font {
 	case "a", "A":
 		p.buffer = append(p.buffer, TXT_FONT_A...)
-		p.width = 48
+		p.width = p.paperWidth
 	case "b", "B":
 		p.buffer = append(p.buffer, TXT_FONT_B...)
-		p.width = 64
+		p.width = p.paperWidth * 4 / 3
 	case "c", "C":
 		p.buffer = append(p.buffer, TXT_FONT_C...)
-		p.width = 64
+		p.width = p.paperWidth * 4 / 3
 	}
 	return p
 }
 
-// Normal resets text formatting.
+// MaxTabs is the number of tab stops SetTabs accepts.
+const MaxTabs = 32
+
+// SetTabs sets the horizontal tab stops used by Tab, each given as the
+// number of characters (in the current font and size) from the start of
+// the line. Positions must be ascending and inside the line; with none,
+// all tab stops are cleared. Init restores the printer's default of a
+// stop every 8 characters.
+func (p *Printer) SetTabs(positions ...int) error {
+	if len(positions) > MaxTabs {
+		return fmt.Errorf("at most %d tab stops are supported", MaxTabs)
+	}
+	width := p.LineWidth()
+	for i, n := range positions {
+		if n < 1 || n >= width {
+			return fmt.Errorf("tab stop %d is outside the line (1-%d)", n, width-1)
+		}
+		if i > 0 && n <= positions[i-1] {
+			return fmt.Errorf("tab stops must be ascending, %d follows %d", n, positions[i-1])
+		}
+	}
+	p.buffer = append(p.buffer, SetTabPositions(positions...)...)
+	return nil
+}
+
+// Tab moves to the next tab stop set by SetTabs.
+func (p *Printer) Tab() *Printer {
+	p.buffer = append(p.buffer, CTL_HT...)
+	return p
+}
+
+// Rotation modes for Rotate.
+const (
+	RotateOff = 0 // Upright text
+	Rotate90  = 1 // 90° clockwise
+	Rotate270 = 2 // 270° clockwise (90° counter-clockwise)
+)
+
+// Rotate turns subsequent text by mode until Rotate(RotateOff) or Init.
+// ESC V only rotates clockwise, so Rotate270 combines it with upside-down
+// printing (ESC {), which printers apply from the start of the next line.
+// Unknown modes are ignored.
+func (p *Printer) Rotate(mode int) *Printer {
+	switch mode {
+	case RotateOff:
+		p.buffer = append(p.buffer, TXT_ROTATE_OFF...)
+		p.buffer = append(p.buffer, TXT_UPSIDE_OFF...)
+	case Rotate90:
+		p.buffer = append(p.buffer, TXT_ROTATE_90...)
+		p.buffer = append(p.buffer, TXT_UPSIDE_OFF...)
+	case Rotate270:
+		p.buffer = append(p.buffer, TXT_ROTATE_90...)
+		p.buffer = append(p.buffer, TXT_UPSIDE_ON...)
+	}
+	return p
+}
+
+// EnterPageMode switches to page mode (ESC L), which lays out a page in
+// the printer's memory so text, barcodes and images can be placed at
+// absolute positions, e.g. on a label. Nothing prints until PrintPageMode.
+//
+// Page mode coordinates and sizes are in motion units, which are one dot
+// (1/203 inch, about 0.125mm) on most 203 dpi thermal printers; some
+// models default to 1/180 or 1/360 inch, so check the printer's manual.
+//
+//	p.EnterPageMode().SetPrintArea(0, 0, 384, 240).
+//		PagePosition(16, 40).Text("SKU 1042").
+//		PrintPageMode().ExitPageMode()
+func (p *Printer) EnterPageMode() *Printer {
+	p.buffer = append(p.buffer, PAGE_MODE_ON...)
+	return p
+}
+
+// SetPrintArea sets the page's print area (ESC W), with the origin at x, y
+// and a size of w by h motion units. Values are clamped to 0-65535, and
+// the size to at least 1. Only valid in page mode.
+func (p *Printer) SetPrintArea(x, y, w, h int) *Printer {
+	clamp := func(n, lo int) int { return max(lo, min(n, 0xffff)) }
+	p.buffer = append(p.buffer, PrintArea(clamp(x, 0), clamp(y, 0), clamp(w, 1), clamp(h, 1))...)
+	return p
+}
+
+// PagePosition moves to x, y inside the print area (ESC $ and GS $), in
+// motion units clamped to 0-65535. Only valid in page mode.
+func (p *Printer) PagePosition(x, y int) *Printer {
+	clamp := func(n int) int { return max(0, min(n, 0xffff)) }
+	p.buffer = append(p.buffer, PagePosition(clamp(x), clamp(y))...)
+	return p
+}
+
+// PrintPageMode prints the page (ESC FF) and stays in page mode, keeping
+// the print area for the next page.
+func (p *Printer) PrintPageMode() *Printer {
+	p.buffer = append(p.buffer, PAGE_MODE_PRINT...)
+	return p
+}
+
+// ExitPageMode returns to standard mode (ESC S). Anything not yet printed
+// with PrintPageMode is discarded.
+func (p *Printer) ExitPageMode() *Printer {
+	p.buffer = append(p.buffer, PAGE_MODE_OFF...)
+	return p
+}
+
+// Normal resets text formatting, including double-strike, character
+// spacing and italics. ESC ! 0 also selects Font A at normal size, so the
+// line width is reset to match.
 func (p *Printer) Normal() *Printer {
 	p.buffer = append(p.buffer, TXT_NORMAL...)
+	p.sizeW = 1
+	p.width = p.paperWidth
+	p.buffer = append(p.buffer, TXT_DOUBLE_STRIKE_OFF...)
+	p.buffer = append(p.buffer, CharSpacing(0)...)
+	if p.italic {
+		p.Italic(false)
+	}
 	return p
 }
 
-// DrawLine prints a line of characters.
+// Italic sets italic printing with ESC 4 / ESC 5. ESC/POS has no italic
+// command: these come from ESC/P and only work on printers that support
+// it, such as dot-matrix models with ESC/P emulation. Epson TM and most
+// thermal printers ignore them, and a few assign the codes to other
+// functions, so check the printer's manual first.
+func (p *Printer) Italic(on bool) *Printer {
+	if on {
+		p.buffer = append(p.buffer, TXT_ITALIC_ON...)
+	} else {
+		p.buffer = append(p.buffer, TXT_ITALIC_OFF...)
+	}
+	p.italic = on
+	return p
+}
+
+// DoubleStrike sets double-strike mode, which prints each dot twice for
+// darker text. Most printers render it like Bold. Init and Normal turn it off.
+func (p *Printer) DoubleStrike(on bool) *Printer {
+	if on {
+		p.buffer = append(p.buffer, TXT_DOUBLE_STRIKE_ON...)
+	} else {
+		p.buffer = append(p.buffer, TXT_DOUBLE_STRIKE_OFF...)
+	}
+	return p
+}
+
+// CharSpacing adds dots (0-255, clamped) of space to the right of each
+// character. Wide spacing reduces how many characters fit on a line, which
+// LineWidth doesn't account for. Init and Normal reset it to 0.
+func (p *Printer) CharSpacing(dots int) *Printer {
+	p.buffer = append(p.buffer, CharSpacing(dots)...)
+	return p
+}
+
+// DrawLine prints a line of characters spanning the current line width,
+// taking the active font and size multiplier into account.
 func (p *Printer) DrawLine(char string) *Printer {
+	return p.DrawLineWidth(char, p.LineWidth())
+}
+
+// DrawLineWidth prints a line n columns wide by repeating char.
+// Multi-character patterns are repeated and cut to fit.
+func (p *Printer) DrawLineWidth(char string, n int) *Printer {
 	if char == "" {
 		char = "-"
 	}
-	for i := 0; i < p.width; i++ {
-		p.buffer = append(p.buffer, []byte(char)...)
+	charWidth := DisplayWidth(char)
+	if charWidth == 0 {
+		charWidth = 1
 	}
+	line := strings.Repeat(char, (n+charWidth-1)/charWidth)
+	p.buffer = append(p.buffer, p.encode(TruncateWidth(line, n))...)
 	return p.NewLine()
 }
 
-// Cut cuts the paper.
+// Cut feeds the paper clear of the tear bar and cuts it.
 func (p *Printer) Cut(partial bool) *Printer {
-	p.Feed(3)
+	return p.Feed(3).CutNoFeed(partial)
+}
+
+// CutNoFeed cuts the paper where it is, without feeding first.
+func (p *Printer) CutNoFeed(partial bool) *Printer {
 	if partial {
 		p.buffer = append(p.buffer, PAPER_PART_CUT...)
 	} else {
@@ -142,6 +573,36 @@ func (p *Printer) Cut(partial bool) *Printer {
 	return p
 }
 
+// Cut modes accepted by CutMode.
+const (
+	CutFull    = "full"
+	CutPartial = "partial"
+	CutNone    = "none"
+)
+
+// ValidCutMode reports whether mode is a known cut mode or empty.
+func ValidCutMode(mode string) bool {
+	switch mode {
+	case "", CutFull, CutPartial, CutNone:
+		return true
+	}
+	return false
+}
+
+// CutMode feeds the paper clear of the tear bar and cuts according to mode.
+// An empty mode means a full cut; CutNone only feeds, for printers without
+// an auto-cutter.
+func (p *Printer) CutMode(mode string) *Printer {
+	switch mode {
+	case CutPartial:
+		return p.Cut(true)
+	case CutNone:
+		return p.Feed(3)
+	default:
+		return p.Cut(false)
+	}
+}
+
 // CashDraw kicks the cash drawer.
 func (p *Printer) CashDraw(pin int) *Printer {
 	if pin == 5 {
@@ -152,21 +613,99 @@ func (p *Printer) CashDraw(pin int) *Printer {
 	return p
 }
 
-// Beep makes the printer beep.
+// Beep makes the printer beep using the buzzer command selected with
+// SetBeepVariant. times and duration are clamped to the variant's limits.
 func (p *Printer) Beep(times, duration int) *Printer {
-	p.buffer = append(p.buffer, BEEP...)
-	p.buffer = append(p.buffer, byte(times), byte(duration))
+	p.buffer = append(p.buffer, BeepCommand(p.BeepVariant(), times, duration)...)
 	return p
 }
 
-// Barcode prints a barcode.
+// SetBeepVariant selects the buzzer command (BeepESCB or BeepESCParenA);
+// empty selects BeepESCB.
+func (p *Printer) SetBeepVariant(variant string) error {
+	if variant == "" {
+		variant = BeepESCB
+	}
+	if _, _, err := BeepLimits(variant); err != nil {
+		return err
+	}
+	p.beepVariant = variant
+	return nil
+}
+
+// BeepVariant returns the buzzer command used by Beep.
+func (p *Printer) BeepVariant() string {
+	if p.beepVariant == "" {
+		return BeepESCB
+	}
+	return p.beepVariant
+}
+
+// BarcodeModuleWidth sets the barcode module (narrowest bar) width in dots
+// and returns the value actually applied after clamping to 2-6.
+func (p *Printer) BarcodeModuleWidth(dots int) int {
+	dots = ClampBarcodeWidth(dots)
+	p.buffer = append(p.buffer, BarcodeWidth(dots)...)
+	return dots
+}
+
+// BarcodeHeightDots sets the barcode height in dots and returns the value
+// actually applied after clamping to 1-255.
+func (p *Printer) BarcodeHeightDots(dots int) int {
+	dots = ClampBarcodeHeight(dots)
+	p.buffer = append(p.buffer, BarcodeHeight(dots)...)
+	return dots
+}
+
+// Barcode prints a barcode with HRI text below in Font A. EAN13, EAN8 and
+// UPC-A data one digit short gets its check digit appended.
+// width is the module width in dots (2-6), height the bar height in dots (1-255).
 func (p *Printer) Barcode(code string, barcodeType string, width, height int) *Printer {
-	p.buffer = append(p.buffer, BARCODE_TXT_BLW...)
-	p.buffer = append(p.buffer, BARCODE_FONT_A...)
-	p.buffer = append(p.buffer, BarcodeHeight(height)...)
-	p.buffer = append(p.buffer, BarcodeWidth(width)...)
+	return p.BarcodeWithOptions(code, BarcodeOptions{
+		Type:   barcodeType,
+		Width:  width,
+		Height: height,
+	})
+}
 
-	switch barcodeType {
+// BarcodeOptions controls barcode symbology, size and HRI
+// (human readable interpretation) text.
+type BarcodeOptions struct {
+	Type    string // UPC_A, UPC_E, EAN13, EAN8, CODE39, CODE128 (default CODE39)
+	Width   int    // Module width in dots (2-6)
+	Height  int    // Bar height in dots (1-255)
+	HRI     string // "none", "above", "below" (default) or "both"
+	HRIFont string // "A" (default) or "B"
+}
+
+// BarcodeWithOptions prints a barcode with full control over HRI position and font.
+// EAN13, EAN8 and UPC-A data one digit short gets its check digit appended.
+// CODE128 data too long for the printer is not printed; BarcodeChecked
+// reports it as an error.
+func (p *Printer) BarcodeWithOptions(code string, opts BarcodeOptions) *Printer {
+	switch opts.HRI {
+	case "none", "off":
+		p.buffer = append(p.buffer, BARCODE_TXT_OFF...)
+	case "above":
+		p.buffer = append(p.buffer, BARCODE_TXT_ABV...)
+	case "both":
+		p.buffer = append(p.buffer, BARCODE_TXT_BTH...)
+	default:
+		p.buffer = append(p.buffer, BARCODE_TXT_BLW...)
+	}
+
+	switch opts.HRIFont {
+	case "b", "B":
+		p.buffer = append(p.buffer, BARCODE_FONT_B...)
+	default:
+		p.buffer = append(p.buffer, BARCODE_FONT_A...)
+	}
+
+	p.buffer = append(p.buffer, BarcodeHeight(opts.Height)...)
+	p.buffer = append(p.buffer, BarcodeWidth(opts.Width)...)
+
+	code = withCheckDigit(code, opts.Type)
+	switch opts.Type {
 	case "UPC_A", "UPC-A":
 		p.buffer = append(p.buffer, BARCODE_UPC_A...)
 	case "UPC_E", "UPC-E":
@@ -178,7 +717,16 @@ func (p *Printer) Barcode(code string, barcodeType string, width, height int) *P
 	case "CODE39":
 		p.buffer = append(p.buffer, BARCODE_CODE39...)
 	case "CODE128":
+		// CODE128 uses the length-prefixed format (GS k 73 n d1...dn)
+		data := EncodeCode128(code)
+		if len(data) > maxCode128Data {
+			// Cutting it short would encode a different value
+			return p
+		}
 		p.buffer = append(p.buffer, BARCODE_CODE128...)
+		p.buffer = append(p.buffer, byte(len(data)))
+		p.buffer = append(p.buffer, data...)
+		return p
 	default:
 		p.buffer = append(p.buffer, BARCODE_CODE39...)
 	}
@@ -247,6 +795,134 @@ func (p *Printer) QRCodeAdvanced(content string, size int, errorLevel int, model
 	return p
 }
 
+// PDF417 prints a PDF417 2D barcode.
+// columns: 1-30 (data columns)
+// rowHeight: 2-8 (row height as a multiple of the module width)
+// errorLevel: 0-8 (error correction level)
+func (p *Printer) PDF417(data string, columns, rowHeight, errorLevel int) *Printer {
+	if columns < 1 {
+		columns = 1
+	}
+	if columns > 30 {
+		columns = 30
+	}
+	if rowHeight < 2 {
+		rowHeight = 2
+	}
+	if rowHeight > 8 {
+		rowHeight = 8
+	}
+	if errorLevel < 0 {
+		errorLevel = 0
+	}
+	if errorLevel > 8 {
+		errorLevel = 8
+	}
+
+	// Set number of columns
+	p.buffer = append(p.buffer, PDF417_COLUMNS...)
+	p.buffer = append(p.buffer, byte(columns))
+
+	// Set row height
+	p.buffer = append(p.buffer, PDF417_ROW_HEIGHT...)
+	p.buffer = append(p.buffer, byte(rowHeight))
+
+	// Set error correction level
+	p.buffer = append(p.buffer, PDF417_ERROR...)
+	p.buffer = append(p.buffer, byte(48+errorLevel))
+
+	// Store data
+	content := []byte(data)
+	storeLen := len(content) + 3
+	pL := byte(storeLen % 256)
+	pH := byte(storeLen / 256)
+	p.buffer = append(p.buffer, PDF417_STORE_PRE...)
+	p.buffer = append(p.buffer, pL, pH)
+	p.buffer = append(p.buffer, PDF417_STORE_POST...)
+	p.buffer = append(p.buffer, content...)
+
+	// Print PDF417
+	p.buffer = append(p.buffer, PDF417_PRINT...)
+
+	return p
+}
+
+// DataMatrix prints a square DataMatrix 2D barcode with automatic symbol size.
+// size: 2-16 (module size in dots)
+func (p *Printer) DataMatrix(data string, size int) *Printer {
+	if size < 2 {
+		size = 2
+	}
+	if size > 16 {
+		size = 16
+	}
+
+	// Select symbol type (square, automatic rows/columns)
+	p.buffer = append(p.buffer, DATAMATRIX_TYPE...)
+
+	// Set module size
+	p.buffer = append(p.buffer, DATAMATRIX_SIZE...)
+	p.buffer = append(p.buffer, byte(size))
+
+	// Store data
+	content := []byte(data)
+	storeLen := len(content) + 3
+	pL := byte(storeLen % 256)
+	pH := byte(storeLen / 256)
+	p.buffer = append(p.buffer, DATAMATRIX_STORE_PRE...)
+	p.buffer = append(p.buffer, pL, pH)
+	p.buffer = append(p.buffer, DATAMATRIX_STORE_POST...)
+	p.buffer = append(p.buffer, content...)
+
+	// Print DataMatrix
+	p.buffer = append(p.buffer, DATAMATRIX_PRINT...)
+
+	return p
+}
+
+// ErrQRCapacity is returned when QR content doesn't fit in the largest
+// symbol for the chosen model and error correction level.
+var ErrQRCapacity = errors.New("QR content exceeds capacity")
+
+// QRCapacity returns the maximum payload in bytes (byte mode) for a QR code
+// with the given error correction level and model.
+func QRCapacity(errorLevel int, model int) int {
+	if model == QRModel1 {
+		// Model 1, version 14
+		switch errorLevel {
+		case QRErrorM:
+			return 911
+		case QRErrorQ:
+			return 707
+		case QRErrorH:
+			return 483
+		}
+		return 1167
+	}
+
+	// Model 2, version 40
+	switch errorLevel {
+	case QRErrorM:
+		return 2331
+	case QRErrorQ:
+		return 1663
+	case QRErrorH:
+		return 1273
+	}
+	return 2953
+}
+
+// QRCodeChecked prints a QR code like QRCodeAdvanced, but first validates the
+// content length against the QR capacity. Nothing is added to the buffer if
+// the content is too long; the returned error wraps ErrQRCapacity.
+func (p *Printer) QRCodeChecked(content string, size int, errorLevel int, model int) error {
+	if limit := QRCapacity(errorLevel, model); len(content) > limit {
+		return fmt.Errorf("%w: %d bytes, maximum is %d", ErrQRCapacity, len(content), limit)
+	}
+	p.QRCodeAdvanced(content, size, errorLevel, model)
+	return nil
+}
+
 // ============== QR CODE DATA TYPE HELPERS ==============
 // These format data correctly for different QR code types
 
@@ -317,6 +993,12 @@ func (p *Printer) Buffer() []byte {
 	return p.buffer
 }
 
+// BytesWritten returns the total number of bytes Flush has sent (or tried
+// to send) to the adapter.
+func (p *Printer) BytesWritten() int {
+	return p.written
+}
+
 // Flush sends all buffered commands to the printer and clears the buffer.
 func (p *Printer) Flush() error {
 	if len(p.buffer) == 0 {
@@ -327,15 +1009,27 @@ func (p *Printer) Flush() error {
 		if err := p.adapter.Open(); err != nil {
 			return fmt.Errorf("failed to open adapter: %w", err)
 		}
+		// The first open is lazy, only count the connection coming back
+		if p.opened {
+			metrics.AdapterReconnects.Inc()
+		}
 	}
+	p.opened = true
 
+	p.written += len(p.buffer)
 	err := p.adapter.Write(p.buffer)
 	p.buffer = p.buffer[:0]
+	if err != nil {
+		// Drop the connection so the next Flush reopens it (e.g. after the
+		// printer was unplugged and reconnected)
+		p.adapter.Close()
+	}
 	return err
 }
 
 // Close closes the adapter.
 func (p *Printer) Close() error {
+	p.opened = false
 	return p.adapter.Close()
 }
 
@@ -402,3 +1096,79 @@ func (p *Printer) RasterImage(mode int, widthBytes, heightDots int, data []byte)
 	p.buffer = append(p.buffer, data...)
 	return p
 }
+
+// GraphicsImage prints an image using the GS ( L graphics commands.
+// The image is stored in the printer's graphics buffer and then printed,
+// which Epson and compatible printers render at full resolution.
+// Use RasterImage for older hardware that only supports GS v 0.
+func (p *Printer) GraphicsImage(img image.Image) *Printer {
+	img = ScaleToFit(img, p.PaperDots())
+	data, widthBytes, height := ImageToRasterThreshold(img, p.imageThreshold)
+
+	p.buffer = append(p.buffer, GraphicsStoreCmd(widthBytes*8, height, len(data))...)
+	p.buffer = append(p.buffer, data...)
+	p.buffer = append(p.buffer, GRAPHICS_PRINT...)
+	return p
+}
+
+// NV logo indexes accepted by StoreNVLogo and PrintNVLogo. Index n is
+// stored under the key codes of its two digits, e.g. "07".
+const (
+	MinNVLogo = 1
+	MaxNVLogo = 99
+)
+
+// nvLogoMaxHeight is the tallest image GS ( L fn 67 can define.
+const nvLogoMaxHeight = 2304
+
+// nvLogoKey returns the GS ( L key codes for an NV logo index.
+func nvLogoKey(index int) (byte, byte, bool) {
+	if index < MinNVLogo || index > MaxNVLogo {
+		return 0, 0, false
+	}
+	return byte('0' + index/10), byte('0' + index%10), true
+}
+
+// StoreNVLogo stores img, scaled down to fit the paper, in the printer's NV
+// (non-volatile) memory under index, replacing any logo stored there.
+// Receipts can then print it with PrintNVLogo instead of sending the image
+// every time.
+//
+// NV memory is persistent printer-side state: logos survive Init and power
+// cycles and are shared with anything else using the printer. It is flash
+// memory that wears with each write (Epson suggests at most 10 writes a
+// day), so store a logo once rather than before every receipt.
+func (p *Printer) StoreNVLogo(index int, img image.Image) error {
+	kc1, kc2, ok := nvLogoKey(index)
+	if !ok {
+		return fmt.Errorf("NV logo index must be between %d and %d", MinNVLogo, MaxNVLogo)
+	}
+
+	img = ScaleToFit(img, p.PaperDots())
+	data, widthBytes, height := ImageToRasterThreshold(img, p.imageThreshold)
+	if widthBytes == 0 || height == 0 {
+		return fmt.Errorf("logo image is empty")
+	}
+	if height > nvLogoMaxHeight {
+		return fmt.Errorf("logo is %d dots tall after scaling, at most %d fit in NV memory", height, nvLogoMaxHeight)
+	}
+
+	p.buffer = append(p.buffer, NVGraphicsStoreCmd(kc1, kc2, widthBytes*8, height, len(data))...)
+	p.buffer = append(p.buffer, data...)
+	return nil
+}
+
+// PrintNVLogo prints the logo stored with StoreNVLogo under index.
+// mode is one of the RASTER_ modes (0=normal, 1=double-width,
+// 2=double-height, 3=quadruple). Invalid indexes are ignored.
+func (p *Printer) PrintNVLogo(index, mode int) *Printer {
+	kc1, kc2, ok := nvLogoKey(index)
+	if !ok {
+		return p
+	}
+	if mode < 0 || mode > 3 {
+		mode = 0
+	}
+	p.buffer = append(p.buffer, NVGraphicsPrintCmd(kc1, kc2, 1+mode&1, 1+mode>>1)...)
+	return p
+}
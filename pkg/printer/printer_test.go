@@ -0,0 +1,259 @@
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"printbridge/pkg/adapter"
+	"printbridge/pkg/metrics"
+)
+
+func TestNormalResetsLineWidth(t *testing.T) {
+	p := newTestPrinter()
+	want := p.LineWidth()
+
+	p.Font("b").Size(2, 2)
+	if got := p.LineWidth(); got == want {
+		t.Fatalf("LineWidth after Font B at double width = %d, want it to differ from %d", got, want)
+	}
+	if got := p.Normal().LineWidth(); got != want {
+		t.Errorf("LineWidth after Normal = %d, want %d", got, want)
+	}
+}
+
+// newTestPrinter returns a Printer for 80mm paper writing to a
+// MemoryAdapter, with an empty buffer.
+func newTestPrinter() *Printer {
+	return New(adapter.NewMemoryAdapter())
+}
+
+func TestDrawLineFitsFont(t *testing.T) {
+	tests := []struct {
+		name        string
+		paperChars  int
+		font        string
+		sizeW       int
+		wantColumns int
+	}{
+		{"80mm font A", 48, "a", 1, 48},
+		{"80mm font B", 48, "b", 1, 64},
+		{"58mm font B", 32, "b", 1, 42},
+		{"80mm font B double width", 48, "b", 2, 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPrinter()
+			p.SetWidth(tt.paperChars)
+			p.Font(tt.font).Size(tt.sizeW, 1)
+			p.buffer = p.buffer[:0]
+
+			p.DrawLine("-")
+			want := strings.Repeat("-", tt.wantColumns) + "\n"
+			if got := string(p.buffer); got != want {
+				t.Errorf("DrawLine emitted %d bytes, want %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name string
+		mode int
+		want []byte
+	}{
+		{"off", RotateOff, []byte{0x1b, 0x56, 0x00, 0x1b, 0x7b, 0x00}},
+		{"90", Rotate90, []byte{0x1b, 0x56, 0x01, 0x1b, 0x7b, 0x00}},
+		{"270", Rotate270, []byte{0x1b, 0x56, 0x01, 0x1b, 0x7b, 0x01}},
+		{"unknown", 3, nil},
+	}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		p.Rotate(tt.mode)
+		if !bytes.Equal(p.buffer, tt.want) {
+			t.Errorf("Rotate(%s) emitted % x, want % x", tt.name, p.buffer, tt.want)
+		}
+	}
+}
+
+func TestDoubleStrikeAndCharSpacing(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(p *Printer)
+		want []byte
+	}{
+		{"double-strike on", func(p *Printer) { p.DoubleStrike(true) }, []byte{0x1b, 0x47, 0x01}},
+		{"double-strike off", func(p *Printer) { p.DoubleStrike(false) }, []byte{0x1b, 0x47, 0x00}},
+		{"spacing 0", func(p *Printer) { p.CharSpacing(0) }, []byte{0x1b, 0x20, 0}},
+		{"spacing 255", func(p *Printer) { p.CharSpacing(255) }, []byte{0x1b, 0x20, 255}},
+		{"spacing clamped low", func(p *Printer) { p.CharSpacing(-1) }, []byte{0x1b, 0x20, 0}},
+		{"spacing clamped high", func(p *Printer) { p.CharSpacing(256) }, []byte{0x1b, 0x20, 255}},
+	}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		tt.fn(p)
+		if !bytes.Equal(p.buffer, tt.want) {
+			t.Errorf("%s: emitted % x, want % x", tt.name, p.buffer, tt.want)
+		}
+	}
+}
+
+func TestNormalResetsDoubleStrikeAndCharSpacing(t *testing.T) {
+	p := newTestPrinter()
+	p.DoubleStrike(true).CharSpacing(4)
+	p.buffer = p.buffer[:0]
+
+	p.Normal()
+	for _, want := range [][]byte{{0x1b, 0x47, 0x00}, {0x1b, 0x20, 0x00}} {
+		if !bytes.Contains(p.buffer, want) {
+			t.Errorf("Normal emitted % x, want it to contain % x", p.buffer, want)
+		}
+	}
+}
+
+func TestItalic(t *testing.T) {
+	p := newTestPrinter()
+	p.Italic(true)
+	if want := []byte{0x1b, 0x34}; !bytes.Equal(p.buffer, want) {
+		t.Errorf("Italic(true) emitted % x, want % x", p.buffer, want)
+	}
+
+	p = newTestPrinter()
+	p.Italic(false)
+	if want := []byte{0x1b, 0x35}; !bytes.Equal(p.buffer, want) {
+		t.Errorf("Italic(false) emitted % x, want % x", p.buffer, want)
+	}
+}
+
+func TestNormalResetsItalicOnlyWhenOn(t *testing.T) {
+	italicOff := []byte{0x1b, 0x35}
+
+	p := newTestPrinter()
+	p.Normal()
+	if bytes.Contains(p.buffer, italicOff) {
+		t.Errorf("Normal without italics emitted % x, want no ESC 5", p.buffer)
+	}
+
+	p = newTestPrinter()
+	p.Italic(true)
+	p.buffer = p.buffer[:0]
+	p.Normal()
+	if !bytes.Contains(p.buffer, italicOff) {
+		t.Errorf("Normal after Italic(true) emitted % x, want it to contain ESC 5", p.buffer)
+	}
+
+	// Italics are off now, so a second Normal has nothing to reset
+	p.buffer = p.buffer[:0]
+	p.Normal()
+	if bytes.Contains(p.buffer, italicOff) {
+		t.Errorf("second Normal emitted % x, want no ESC 5", p.buffer)
+	}
+}
+
+func TestSetTabs(t *testing.T) {
+	p := newTestPrinter()
+	if err := p.SetTabs(8, 16, 24); err != nil {
+		t.Fatalf("SetTabs(8, 16, 24): %v", err)
+	}
+	if want := []byte{0x1b, 0x44, 8, 16, 24, 0x00}; !bytes.Equal(p.buffer, want) {
+		t.Errorf("SetTabs(8, 16, 24) emitted % x, want % x", p.buffer, want)
+	}
+
+	// No positions clears the tab stops
+	p = newTestPrinter()
+	if err := p.SetTabs(); err != nil {
+		t.Fatalf("SetTabs(): %v", err)
+	}
+	if want := []byte{0x1b, 0x44, 0x00}; !bytes.Equal(p.buffer, want) {
+		t.Errorf("SetTabs() emitted % x, want % x", p.buffer, want)
+	}
+
+	p = newTestPrinter()
+	p.Tab()
+	if want := []byte{0x09}; !bytes.Equal(p.buffer, want) {
+		t.Errorf("Tab emitted % x, want % x", p.buffer, want)
+	}
+}
+
+func TestSetTabsRejectsInvalidPositions(t *testing.T) {
+	tooMany := make([]int, MaxTabs+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+
+	tests := []struct {
+		name      string
+		positions []int
+	}{
+		{"descending", []int{16, 8}},
+		{"repeated", []int{8, 8}},
+		{"zero", []int{0, 8}},
+		{"at line width", []int{8, 48}},
+		{"past line width", []int{8, 60}},
+		{"too many", tooMany},
+	}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		if err := p.SetTabs(tt.positions...); err == nil {
+			t.Errorf("%s: SetTabs(%v) succeeded, want an error", tt.name, tt.positions)
+		}
+		if len(p.buffer) != 0 {
+			t.Errorf("%s: SetTabs emitted % x on error, want nothing", tt.name, p.buffer)
+		}
+	}
+}
+
+func TestPageMode(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(p *Printer)
+		want []byte
+	}{
+		{"enter", func(p *Printer) { p.EnterPageMode() }, []byte{0x1b, 0x4c}},
+		{"print", func(p *Printer) { p.PrintPageMode() }, []byte{0x1b, 0x0c}},
+		{"exit", func(p *Printer) { p.ExitPageMode() }, []byte{0x1b, 0x53}},
+		{"print area", func(p *Printer) { p.SetPrintArea(0, 10, 512, 300) },
+			[]byte{0x1b, 0x57, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x02, 0x2c, 0x01}},
+		{"print area clamped", func(p *Printer) { p.SetPrintArea(-5, 70000, 0, -1) },
+			[]byte{0x1b, 0x57, 0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x01, 0x00}},
+		{"position", func(p *Printer) { p.PagePosition(300, 20) },
+			[]byte{0x1b, 0x24, 0x2c, 0x01, 0x1d, 0x24, 0x14, 0x00}},
+		{"position clamped", func(p *Printer) { p.PagePosition(-1, 70000) },
+			[]byte{0x1b, 0x24, 0x00, 0x00, 0x1d, 0x24, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		p := newTestPrinter()
+		tt.fn(p)
+		if !bytes.Equal(p.buffer, tt.want) {
+			t.Errorf("%s: emitted % x, want % x", tt.name, p.buffer, tt.want)
+		}
+	}
+}
+
+func TestFlushCountsReconnects(t *testing.T) {
+	mem := adapter.NewMemoryAdapter()
+	p := New(mem)
+	start := metrics.AdapterReconnects.Value()
+	flush := func(want uint64) {
+		t.Helper()
+		if err := p.Text("x").Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got := metrics.AdapterReconnects.Value() - start; got != want {
+			t.Errorf("reconnects = %d, want %d", got, want)
+		}
+	}
+
+	// Opening the adapter for the first print isn't a reconnect
+	flush(0)
+	flush(0)
+	mem.Close()
+	flush(1)
+
+	// Nor is opening it after Close, or on a Printer for another adapter
+	p.Close()
+	flush(1)
+	p = p.WithAdapter(adapter.NewMemoryAdapter())
+	flush(1)
+}
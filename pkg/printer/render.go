@@ -0,0 +1,247 @@
+package printer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Directives are emitted into the rendered text as markers and replayed as
+// Printer calls by RenderTemplate. The markers of each rendering contain a
+// random token, so text from the template data can't forge a directive.
+type directiveMarkers struct {
+	mark string // Delimits a directive
+	sep  string // Separates its name and arguments
+}
+
+func newDirectiveMarkers() (directiveMarkers, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return directiveMarkers{}, fmt.Errorf("failed to generate directive marker: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	return directiveMarkers{mark: "\x00" + token + "\x00", sep: "\x1f" + token + "\x1f"}, nil
+}
+
+func (m directiveMarkers) directive(name string, args ...string) string {
+	return m.mark + strings.Join(append([]string{name}, args...), m.sep) + m.mark
+}
+
+// TemplateFuncs are the functions available to receipt templates:
+//
+//	align "center"        left, center or right
+//	bold true             bold on/off
+//	underline 1           0 off, 1 thin, 2 thick
+//	reverse true          white on black on/off
+//	size 2 2              character width and height multipliers (1-8)
+//	line / line "="       separator across the paper
+//	row "Tea" "2.50"      left and right text on one line
+//	qr "https://..." 6    QR code with optional module size
+//	barcode "EAN13" "..." barcode of the given type
+//	feed 3                feed n lines
+//	cut / cut "partial"   feed and cut (full, partial or none)
+//	money 12.5            format a number with two decimals ("12.50")
+//
+// RenderTemplate replaces them with functions using its own markers.
+var TemplateFuncs = templateFuncs(directiveMarkers{mark: "\x00", sep: "\x1f"})
+
+func templateFuncs(m directiveMarkers) template.FuncMap {
+	return template.FuncMap{
+		"align":     func(a string) string { return m.directive("align", a) },
+		"bold":      func(on bool) string { return m.directive("bold", strconv.FormatBool(on)) },
+		"underline": func(mode int) string { return m.directive("underline", strconv.Itoa(mode)) },
+		"reverse":   func(on bool) string { return m.directive("reverse", strconv.FormatBool(on)) },
+		"size": func(w, h int) string {
+			return m.directive("size", strconv.Itoa(w), strconv.Itoa(h))
+		},
+		"line": func(char ...string) string { return m.directive("line", char...) },
+		"row":  func(left, right string) string { return m.directive("row", left, right) },
+		"qr": func(data string, size ...int) string {
+			args := []string{data}
+			if len(size) > 0 {
+				args = append(args, strconv.Itoa(size[0]))
+			}
+			return m.directive("qr", args...)
+		},
+		"barcode": func(typ, code string) string { return m.directive("barcode", typ, code) },
+		"feed":    func(n int) string { return m.directive("feed", strconv.Itoa(n)) },
+		"cut":     func(mode ...string) string { return m.directive("cut", mode...) },
+		"money":   money,
+	}
+}
+
+// money formats a number (as decoded from JSON, or a numeric string) with
+// two decimals.
+func money(v interface{}) (string, error) {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', 2, 64), nil
+	case int:
+		return strconv.FormatFloat(float64(n), 'f', 2, 64), nil
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'f', 2, 64), nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return "", fmt.Errorf("money: %q is not a number", n)
+		}
+		return strconv.FormatFloat(f, 'f', 2, 64), nil
+	case nil:
+		return "0.00", nil
+	}
+	return "", fmt.Errorf("money: unsupported type %T", v)
+}
+
+// ParseReceiptTemplate parses a text/template receipt layout with
+// TemplateFuncs available.
+func ParseReceiptTemplate(name, src string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Option("missingkey=zero").Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// LoadReceiptTemplate loads and parses templatesDir/<name>.tmpl.
+func LoadReceiptTemplate(templatesDir, name string) (*template.Template, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+
+	src, err := os.ReadFile(filepath.Join(templatesDir, name+".tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	return ParseReceiptTemplate(name, string(src))
+}
+
+// RenderTemplate executes tmpl with data and adds the result to the buffer,
+// turning directives into the matching Printer commands. Text is printed as
+// written, so use {{- and -}} to trim newlines around directive-only lines.
+func (p *Printer) RenderTemplate(tmpl *template.Template, data interface{}) error {
+	m, err := newDirectiveMarkers()
+	if err != nil {
+		return err
+	}
+	t, err := tmpl.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+	}
+	t.Funcs(templateFuncs(m))
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+	}
+
+	// Markers split the output so that odd parts are directives
+	for i, part := range strings.Split(out.String(), m.mark) {
+		if i%2 == 0 {
+			if part != "" {
+				p.Text(part)
+			}
+			continue
+		}
+		if err := p.applyDirective(strings.Split(part, m.sep)); err != nil {
+			return fmt.Errorf("template %s: %w", tmpl.Name(), err)
+		}
+	}
+	return nil
+}
+
+// applyDirective replays one template directive on the printer.
+func (p *Printer) applyDirective(d []string) error {
+	name, args := d[0], d[1:]
+
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	intArg := func(i, def int) (int, error) {
+		if arg(i) == "" {
+			return def, nil
+		}
+		n, err := strconv.Atoi(arg(i))
+		if err != nil {
+			return 0, fmt.Errorf("%s: invalid number %q", name, arg(i))
+		}
+		return n, nil
+	}
+
+	switch name {
+	case "align":
+		switch arg(0) {
+		case "left", "center", "right":
+			p.Align(arg(0))
+		default:
+			return fmt.Errorf("align: must be left, center or right")
+		}
+	case "bold":
+		p.Bold(arg(0) == "true")
+	case "reverse":
+		p.Reverse(arg(0) == "true")
+	case "underline":
+		mode, err := intArg(0, 1)
+		if err != nil {
+			return err
+		}
+		p.Underline(mode)
+	case "size":
+		w, err := intArg(0, 1)
+		if err != nil {
+			return err
+		}
+		h, err := intArg(1, 1)
+		if err != nil {
+			return err
+		}
+		p.Size(w, h)
+	case "line":
+		char := arg(0)
+		if char == "" {
+			char = "-"
+		}
+		p.DrawLine(char)
+	case "row":
+		p.Row(arg(0), arg(1))
+	case "qr":
+		size, err := intArg(1, 6)
+		if err != nil {
+			return err
+		}
+		if err := p.QRCodeChecked(arg(0), size, QRErrorL, QRModel2); err != nil {
+			return fmt.Errorf("qr: %w", err)
+		}
+	case "barcode":
+		if err := p.BarcodeChecked(arg(1), BarcodeOptions{Type: arg(0), Width: 3, Height: 80}); err != nil {
+			return fmt.Errorf("barcode: %w", err)
+		}
+	case "feed":
+		n, err := intArg(0, 1)
+		if err != nil {
+			return err
+		}
+		p.Feed(n)
+	case "cut":
+		if !ValidCutMode(arg(0)) {
+			return fmt.Errorf("cut: must be full, partial or none")
+		}
+		p.CutMode(arg(0))
+	default:
+		return fmt.Errorf("unknown directive %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplateDirectives(t *testing.T) {
+	tmpl, err := ParseReceiptTemplate("test", `{{.name}}{{cut "partial"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestPrinter()
+	if err := p.RenderTemplate(tmpl, map[string]interface{}{"name": "Tea"}); err != nil {
+		t.Fatalf("RenderTemplate: %v", err)
+	}
+	if !bytes.Contains(p.buffer, []byte("Tea")) {
+		t.Errorf("rendered % x, want it to contain the name", p.buffer)
+	}
+	if !bytes.HasSuffix(p.buffer, PAPER_PART_CUT) {
+		t.Errorf("rendered % x, want it to end with a partial cut", p.buffer)
+	}
+}
+
+// Template data must never be turned into directives.
+func TestRenderTemplateDataCannotForgeDirectives(t *testing.T) {
+	tmpl, err := ParseReceiptTemplate("test", `{{.name}} {{row .item "1.00"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"\x00cut\x00", "\x00feed\x1f9\x00", "\x00raw\x00", "\x00"} {
+		p := newTestPrinter()
+		data := map[string]interface{}{"name": name, "item": "Tea\x00cut\x00"}
+		if err := p.RenderTemplate(tmpl, data); err != nil {
+			t.Errorf("RenderTemplate(%q): %v", name, err)
+			continue
+		}
+		for _, cmd := range [][]byte{PAPER_FULL_CUT, PAPER_PART_CUT} {
+			if bytes.Contains(p.buffer, cmd) {
+				t.Errorf("name %q rendered % x, want no cut", name, p.buffer)
+			}
+		}
+		if bytes.Count(p.buffer, []byte{'\n'}) > 2 {
+			t.Errorf("name %q rendered % x, want no extra feed", name, p.buffer)
+		}
+	}
+}
+
+func TestRenderTemplateChecksCodes(t *testing.T) {
+	tests := []struct {
+		src  string
+		data map[string]interface{}
+		want error
+	}{
+		{`{{qr .url}}`, map[string]interface{}{"url": strings.Repeat("x", QRCapacity(QRErrorL, QRModel2)+1)}, ErrQRCapacity},
+		{`{{barcode "EAN13" .code}}`, map[string]interface{}{"code": "5901234123450"}, ErrInvalidBarcode},
+		{`{{barcode "CODE39" .code}}`, map[string]interface{}{"code": "lower"}, ErrInvalidBarcode},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseReceiptTemplate("test", tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := newTestPrinter()
+		if err := p.RenderTemplate(tmpl, tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: RenderTemplate error = %v, want %v", tt.src, err, tt.want)
+		}
+	}
+
+	tmpl, err := ParseReceiptTemplate("test", `{{qr .url}}{{barcode "EAN13" .code}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestPrinter()
+	if err := p.RenderTemplate(tmpl, map[string]interface{}{"url": "https://example.com", "code": "590123412345"}); err != nil {
+		t.Errorf("RenderTemplate with valid codes: %v", err)
+	}
+	if !bytes.Contains(p.buffer, []byte("5901234123457")) {
+		t.Errorf("rendered % x, want the EAN13 with its check digit", p.buffer)
+	}
+}
@@ -0,0 +1,120 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"printbridge/pkg/adapter"
+)
+
+// ErrStatusUnsupported is returned by QueryStatus when the adapter can't
+// read data back from the printer (e.g. Windows spooler, console).
+var ErrStatusUnsupported = errors.New("status readback not supported by adapter")
+
+// Status is the decoded real-time status of the printer.
+type Status struct {
+	Online       bool `json:"online"`
+	PaperPresent bool `json:"paper_present"`
+	PaperNearEnd bool `json:"paper_near_end"`
+	CoverOpen    bool `json:"cover_open"`
+	DrawerOpen   bool `json:"drawer_open"` // Drawer kick connector pin 3 high
+	Error        bool `json:"error"`
+}
+
+// QueryStatus sends DLE EOT 1-4 to the printer and decodes the responses.
+// The print buffer is not touched; commands are written to the adapter directly.
+// Adapters implementing adapter.StatusReporter are asked for the spooler's
+// view of the printer instead.
+func (p *Printer) QueryStatus() (Status, error) {
+	return p.QueryStatusContext(context.Background())
+}
+
+// QueryStatusContext is QueryStatus with a deadline for the whole query:
+// no further status request is sent once ctx is done.
+func (p *Printer) QueryStatusContext(ctx context.Context) (Status, error) {
+	var status Status
+
+	if r, ok := p.adapter.(adapter.StatusReporter); ok {
+		st, err := r.SpoolerStatus()
+		if err != nil {
+			return status, err
+		}
+		status.Online = st.Online
+		status.PaperPresent = !st.PaperOut
+		status.CoverOpen = st.CoverOpen
+		status.Error = st.Error
+		return status, nil
+	}
+
+	if b, ok := p.adapter.(adapter.Bidirectional); !ok || !b.CanRead() {
+		return status, ErrStatusUnsupported
+	}
+
+	if !p.adapter.IsOpen() {
+		if err := p.adapter.Open(); err != nil {
+			return status, fmt.Errorf("failed to open adapter: %w", err)
+		}
+	}
+
+	// Printer status: bit 2 = drawer pin 3, bit 3 = offline
+	printerStatus, err := p.queryStatusByte(ctx, STATUS_PRINTER)
+	if err != nil {
+		return status, err
+	}
+	status.Online = printerStatus&0x08 == 0
+	status.DrawerOpen = printerStatus&0x04 != 0
+
+	// Offline cause: bit 2 = cover open, bit 5 = paper end stop, bit 6 = error
+	offline, err := p.queryStatusByte(ctx, STATUS_OFFLINE)
+	if err != nil {
+		return status, err
+	}
+	status.CoverOpen = offline&0x04 != 0
+	status.Error = offline&0x40 != 0
+
+	// Error cause: bit 3 = autocutter, bit 5 = unrecoverable, bit 6 = auto-recoverable
+	errCause, err := p.queryStatusByte(ctx, STATUS_ERROR)
+	if err != nil {
+		return status, err
+	}
+	if errCause&0x68 != 0 {
+		status.Error = true
+	}
+
+	// Paper sensor: bits 2,3 = near end, bits 5,6 = paper end
+	paper, err := p.queryStatusByte(ctx, STATUS_PAPER)
+	if err != nil {
+		return status, err
+	}
+	status.PaperNearEnd = paper&0x0c != 0
+	status.PaperPresent = paper&0x60 == 0
+
+	return status, nil
+}
+
+// queryStatusByte sends a single DLE EOT request and returns the response
+// byte. A printer that doesn't answer in time is treated as one that can't
+// report status.
+func (p *Printer) queryStatusByte(ctx context.Context, n int) (byte, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("status request %d not sent: %w", n, err)
+	}
+	if err := p.adapter.Write(StatusRequest(n)); err != nil {
+		return 0, fmt.Errorf("failed to send status request %d: %w", n, err)
+	}
+
+	resp, err := p.adapter.Read()
+	if errors.Is(err, adapter.ErrReadTimeout) {
+		return 0, ErrStatusUnsupported
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read status %d: %w", n, err)
+	}
+	if len(resp) == 0 {
+		return 0, ErrStatusUnsupported
+	}
+
+	// The last byte is the most recent response if the printer sent extra data
+	return resp[len(resp)-1], nil
+}
@@ -1,16 +1,23 @@
 package printer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"image"
-	"strconv"
-	_ "golang.org/x/image/bmp"
-	_ "image/png"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
+
+	"golang.org/x/image/bmp"
+	xdraw "golang.org/x/image/draw"
 )
 
 // TemplateOrder represents an order from a food delivery platform
@@ -23,6 +30,15 @@ type TemplateOrder struct {
 	Totals   OrderTotals      `json:"totals"`
 	Payment  OrderPayment     `json:"payment"`
 	Notes    OrderNotes       `json:"notes"`
+	Cut      string           `json:"cut,omitempty"` // full (default), partial or none
+
+	// TrackingURL is printed as a QR code by the tracking section, e.g. an
+	// order tracking or reorder link.
+	TrackingURL string `json:"tracking_url,omitempty"`
+
+	// Fallback prints orders for unknown platforms with a generic header;
+	// without it the print endpoint rejects them.
+	Fallback bool `json:"fallback,omitempty"`
 }
 
 type OrderMerchant struct {
@@ -78,19 +94,49 @@ func toInt(v interface{}) int {
 }
 
 type OrderItem struct {
-	Name         string  `json:"name"`
-	Quantity     int     `json:"quantity"`
-	UnitPrice    float64 `json:"unit_price_try"`
-	TotalPrice   float64 `json:"total_price_try"`
+	Name         string       `json:"name"`
+	Quantity     float64      `json:"quantity"`       // Count, or amount in Unit for weighed items, e.g. 0.75
+	Unit         string       `json:"unit,omitempty"` // Unit of weighed items, e.g. "kg"; empty for counted items
+	UnitPrice    float64      `json:"unit_price_try"` // Price per item or per Unit
+	TotalPrice   float64      `json:"total_price_try"`
+	Options      []ItemOption `json:"options,omitempty"` // Modifiers such as "extra cheese"
+}
+
+// FormatQuantity returns the quantity as printed: whole counts without
+// decimals ("2"), fractional amounts with three ("0.750"), followed by the
+// unit, if any ("0.750 kg").
+func (i OrderItem) FormatQuantity() string {
+	q := strconv.FormatFloat(i.Quantity, 'f', 3, 64)
+	if i.Quantity == math.Trunc(i.Quantity) {
+		q = strconv.FormatFloat(i.Quantity, 'f', 0, 64)
+	}
+	if i.Unit != "" {
+		q += " " + i.Unit
+	}
+	return q
+}
+
+// ItemOption is a modifier or choice on an order item.
+type ItemOption struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price_try"` // Price delta, 0 if free
 }
 
 type OrderTotals struct {
 	Subtotal    float64  `json:"subtotal_try"`
 	DeliveryFee float64  `json:"delivery_fee_try"`
+	ServiceFee  float64  `json:"service_fee_try"`
+	Discount    float64  `json:"discount_try"` // Discounts and coupons; sign is ignored
+	Tip         float64  `json:"tip_try"`
 	VAT         OrderVAT `json:"vat"`
 	Total       float64  `json:"total_try"`
 }
 
+// Expected returns the total implied by the other fields.
+func (t OrderTotals) Expected() float64 {
+	return t.Subtotal + t.DeliveryFee + t.ServiceFee + t.Tip - math.Abs(t.Discount)
+}
+
 type OrderVAT struct {
 	Included bool `json:"included"`
 }
@@ -106,9 +152,16 @@ type OrderNotes struct {
 
 // Template represents a receipt template for a food delivery platform
 type Template struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	LogoPath string `json:"logo"`
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	LogoPath string            `json:"logo"`
+	Aliases  []string          `json:"aliases,omitempty"`  // Other platform names that select this template
+	Sections []TemplateSection `json:"sections,omitempty"` // Body layout; empty uses DefaultSections
+
+	// Printers sends parts of each order to other named printers, e.g.
+	// {"kitchen": ["order", "items", "notes"]}, in addition to the full
+	// receipt printed by the printer the order was sent to.
+	Printers map[string][]TemplateSection `json:"printers,omitempty"`
 }
 
 // PlatformTemplates maps platform names to their template configurations
@@ -135,43 +188,45 @@ var PlatformTemplates = map[string]Template{
 	},
 }
 
+// platformAliases maps common spellings of platform names, after
+// lowercasing and replacing spaces with underscores, to the keys of
+// PlatformTemplates.
+var platformAliases = map[string]string{
+	"getir":        "getir_yemek",
+	"getiryemek":   "getir_yemek",
+	"yemek_sepeti": "yemeksepeti",
+	"trendyol":     "trendyol_go",
+	"trendyolgo":   "trendyol_go",
+	"migros":       "migros_yemek",
+	"migrosyemek":  "migros_yemek",
+}
+
 // NormalizePlatform converts a platform name to its template key
 func NormalizePlatform(platform string) string {
 	// Convert to lowercase and replace spaces with underscores
 	normalized := strings.ToLower(strings.TrimSpace(platform))
 	normalized = strings.ReplaceAll(normalized, " ", "_")
 	
-	// Map common variations
-	variations := map[string]string{
-		"getir":         "getir_yemek",
-		"getiryemek":    "getir_yemek",
-		"getir yemek":   "getir_yemek",
-		"yemeksepeti":   "yemeksepeti",
-		"yemek sepeti":  "yemeksepeti",
-		"trendyol":      "trendyol_go",
-		"trendyolgo":    "trendyol_go",
-		"trendyol go":   "trendyol_go",
-		"trendyol_go":   "trendyol_go",
-		"migros":        "migros_yemek",
-		"migrosyemek":   "migros_yemek",
-		"migros yemek":  "migros_yemek",
-		"migros_yemek":  "migros_yemek",
-	}
-	
-	if key, ok := variations[normalized]; ok {
+	if key, ok := platformAliases[normalized]; ok {
 		return key
 	}
 	return normalized
 }
 
-// GetTemplate returns the template for a given platform
+// GetTemplate returns the template for a given platform, preferring
+// user-defined templates loaded by LoadTemplates over the built-in ones.
 func GetTemplate(platform string) (Template, bool) {
 	key := NormalizePlatform(platform)
+	if tmpl, ok := userTemplate(key); ok {
+		return tmpl, true
+	}
 	tmpl, ok := PlatformTemplates[key]
 	return tmpl, ok
 }
 
-// LoadLogo loads a logo image from the templates directory
+// LoadLogo loads a logo image from the templates directory.
+// PNG, JPEG, GIF and BMP are supported. The format is sniffed from the file
+// contents first, falling back to the file extension.
 func LoadLogo(templatesDir, logoPath string) (image.Image, error) {
 	fullPath := filepath.Join(templatesDir, logoPath)
 	
@@ -182,15 +237,83 @@ func LoadLogo(templatesDir, logoPath string) (image.Image, error) {
 	defer f.Close()
 	
 	img, _, err := image.Decode(f)
+	if err == nil {
+		return img, nil
+	}
+
+	// Content sniffing failed, try the decoder matching the extension
+	format := LogoFormatFromExt(logoPath)
+	if format == "" {
+		return nil, fmt.Errorf("failed to decode logo %s (unknown format): %w", logoPath, err)
+	}
+	if _, seekErr := f.Seek(0, io.SeekStart); seekErr != nil {
+		return nil, fmt.Errorf("failed to decode logo %s (format: %s): %w", logoPath, format, err)
+	}
+
+	img, err = decodeLogo(f, format)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode logo: %w", err)
+		return nil, fmt.Errorf("failed to decode logo %s (format: %s): %w", logoPath, format, err)
 	}
 	
 	return img, nil
 }
 
+// DecodeLogo decodes an uploaded PNG, JPEG, GIF or BMP image.
+func DecodeLogo(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode logo (use PNG, JPEG, GIF or BMP): %w", err)
+	}
+	return img, nil
+}
+
+// LogoFormatFromExt returns the image format implied by a file extension
+// ("png", "jpeg", "gif" or "bmp"), or "" if the extension is not recognized.
+func LogoFormatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return "png"
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".gif":
+		return "gif"
+	case ".bmp":
+		return "bmp"
+	}
+	return ""
+}
+
+// decodeLogo decodes r with the decoder for the given format.
+func decodeLogo(r io.Reader, format string) (image.Image, error) {
+	switch format {
+	case "png":
+		return png.Decode(r)
+	case "jpeg":
+		return jpeg.Decode(r)
+	case "gif":
+		return gif.Decode(r)
+	case "bmp":
+		return bmp.Decode(r)
+	}
+	return nil, fmt.Errorf("unsupported image format: %s", format)
+}
+
+// DefaultImageThreshold is the luminance cutoff (0-65535) used by ImageToRaster.
+const DefaultImageThreshold = 32768
+
 // ImageToRaster converts an image to ESC/POS raster format (1-bit per pixel)
 func ImageToRaster(img image.Image) ([]byte, int, int) {
+	return ImageToRasterThreshold(img, DefaultImageThreshold)
+}
+
+// ImageToRasterThreshold converts an image to ESC/POS raster format using a
+// custom luminance cutoff. Pixels darker than threshold (0-65535) are printed
+// black; values above 65535 are clamped.
+func ImageToRasterThreshold(img image.Image, threshold uint32) ([]byte, int, int) {
+	if threshold > 65535 {
+		threshold = 65535
+	}
+
 	bounds := img.Bounds()
 	width := bounds.Max.X - bounds.Min.X
 	height := bounds.Max.Y - bounds.Min.Y
@@ -208,7 +331,7 @@ func ImageToRaster(img image.Image) ([]byte, int, int) {
 			gray := (r*299 + g*587 + b*114) / 1000
 			
 			// Threshold: if dark enough, set the bit (inverted for thermal: black = 1)
-			if gray < 32768 { // 50% threshold
+			if gray < threshold {
 				byteIndex := y*widthBytes + x/8
 				bitIndex := 7 - (x % 8)
 				data[byteIndex] |= 1 << bitIndex
@@ -219,6 +342,40 @@ func ImageToRaster(img image.Image) ([]byte, int, int) {
 	return data, widthBytes, height
 }
 
+// ImageToRasterFit converts an image to ESC/POS raster format, first
+// downscaling it proportionally so it fits in maxDots by maxDots.
+func ImageToRasterFit(img image.Image, maxDots int) ([]byte, int, int) {
+	return ImageToRaster(ScaleToFit(img, maxDots))
+}
+
+// ScaleToFit downscales img proportionally (bilinear) so its wider side
+// does not exceed maxDots, rounded down to whole raster bytes (multiples of
+// 8 dots). Images that already fit are returned unchanged.
+func ScaleToFit(img image.Image, maxDots int) image.Image {
+	maxDots -= maxDots % 8
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+	if maxDots <= 0 || (width <= maxDots && height <= maxDots) {
+		return img
+	}
+
+	newWidth, newHeight := maxDots, height*maxDots/width
+	if height > width {
+		newWidth, newHeight = width*maxDots/height, maxDots
+	}
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, bounds, xdraw.Src, nil)
+	return dst
+}
+
 // PrintTemplateOrder prints an order using the appropriate template
 func (p *Printer) PrintTemplateOrder(order TemplateOrder, templatesDir string) error {
 	// Get template for the platform
@@ -232,9 +389,10 @@ func (p *Printer) PrintTemplateOrder(order TemplateOrder, templatesDir string) e
 	p.Init()
 	
 	// Try to load and print logo
-	if tmpl.LogoPath != "" {
-		if img, err := LoadLogo(templatesDir, tmpl.LogoPath); err == nil {
-			rasterData, widthBytes, height := ImageToRaster(img)
+	if logoPath := TemplateLogoPath(templatesDir, tmpl); logoPath != "" {
+		if img, err := LoadLogo(templatesDir, logoPath); err == nil {
+			img = ScaleToFit(img, p.PaperDots())
+			rasterData, widthBytes, height := ImageToRasterThreshold(img, p.imageThreshold)
 			p.Align("center").
 				RasterImage(0, widthBytes, height, rasterData).
 				NewLine()
@@ -248,12 +406,27 @@ func (p *Printer) PrintTemplateOrder(order TemplateOrder, templatesDir string) e
 		Println(tmpl.Name).
 		Size(1, 1).
 		Bold(false).
-		Println("Sipariş Fişi").
+		Println(p.labels().Receipt).
 		NewLine().
 		DrawLine("=")
 	
 	// Print the rest of the order
-	return p.printOrderBody(order)
+	return p.printOrderBody(order, tmpl.Sections)
+}
+
+// PrintOrderSections prints part of an order, such as a kitchen ticket:
+// title in large type followed by sections, then cuts and flushes.
+func (p *Printer) PrintOrderSections(order TemplateOrder, title string, sections []TemplateSection) error {
+	p.Init().
+		Align("center").
+		Bold(true).
+		Size(1, 2).
+		Println(title).
+		Size(1, 1).
+		Bold(false).
+		DrawLine("=")
+
+	return p.printOrderBody(order, sections)
 }
 
 // printOrderWithoutLogo prints an order using text-only header
@@ -265,124 +438,205 @@ func (p *Printer) printOrderWithoutLogo(order TemplateOrder, platformName string
 		Println(fmt.Sprintf(" %s ", strings.ToUpper(platformName))).
 		Reverse(false).
 		Size(1, 1).
-		Println("Sipariş Fişi").
+		Println(p.labels().Receipt).
 		NewLine().
 		DrawLine("=")
 	
-	return p.printOrderBody(order)
+	return p.printOrderBody(order, DefaultSections)
 }
 
-// printOrderBody prints the main content of the order
-func (p *Printer) printOrderBody(order TemplateOrder) error {
-	// Merchant info
-	p.Align("center").
-		Bold(true).
-		Println(order.Merchant.Name).
-		Bold(false).
-		Println(fmt.Sprintf("%s, %s", order.Merchant.Neighborhood, order.Merchant.District)).
-		NewLine()
-	
-	// Order time
-	p.Align("left").
-		DrawLine("-")
-	
-	orderTime := order.Order.OrderTime
-	if t, err := time.Parse(time.RFC3339, orderTime); err == nil {
-		orderTime = t.Format("02.01.2006 15:04")
-	} else if t, err := time.Parse("2006-01-02T15:04:05", orderTime); err == nil {
-		orderTime = t.Format("02.01.2006 15:04")
-	}
-	
-	p.Println(fmt.Sprintf("Sipariş Zamanı: %s", orderTime)).
-		Println(fmt.Sprintf("Sipariş Tipi: %s", order.Order.OrderType)).
-		DrawLine("-")
-	
-	// Customer info
-	p.Bold(true).
-		Println("MÜŞTERİ BİLGİLERİ").
-		Bold(false).
-		Println(fmt.Sprintf("Ad: %s", order.Customer.Name)).
-		Println(fmt.Sprintf("Tel: %s", order.Customer.Phone)).
-		NewLine().
-		Println("Adres:").
-		Println(order.Customer.Address.StreetAddress)
-	
-	if order.Customer.Address.GetFloor() > 0 || order.Customer.Address.GetApartment() > 0 {
-		p.Println(fmt.Sprintf("Kat: %d, Daire: %d", order.Customer.Address.GetFloor(), order.Customer.Address.GetApartment()))
+// printOrderBody prints the main content of the order using the given
+// section layout, then cuts and flushes.
+func (p *Printer) printOrderBody(order TemplateOrder, sections []TemplateSection) error {
+	if len(sections) == 0 {
+		sections = DefaultSections
 	}
-	
-	p.Println(fmt.Sprintf("%s, %s", order.Customer.Address.Neighborhood, order.Customer.Address.District)).
-		Println(order.Customer.Address.City)
-	
-	if order.Customer.Address.Description != "" {
-		p.Println(fmt.Sprintf("Not: %s", order.Customer.Address.Description))
+	for _, section := range sections {
+		p.printSection(order, section)
 	}
+
+	p.Feed(2).
+		CutMode(order.Cut)
 	
-	p.DrawLine("-")
-	
-	// Items
-	p.Bold(true).
-		Println("SİPARİŞ DETAYI").
-		Bold(false)
-	
-	for _, item := range order.Items {
-		name := item.Name
-		if len(name) > 24 {
-			name = name[:24]
+	return p.Flush()
+}
+
+// printSection prints one section of an order.
+func (p *Printer) printSection(order TemplateOrder, section TemplateSection) {
+	l := p.labels()
+
+	switch section.Type {
+	case SectionMerchant:
+		p.Align("center").
+			Bold(true).
+			Println(order.Merchant.Name).
+			Bold(false).
+			Println(fmt.Sprintf("%s, %s", order.Merchant.Neighborhood, order.Merchant.District)).
+			NewLine()
+
+	case SectionOrder:
+		p.Align("left").
+			DrawLine("-")
+		
+		p.Println(fmt.Sprintf("%s: %s", l.OrderTime, p.formatOrderTime(order.Order.OrderTime))).
+			Println(fmt.Sprintf("%s: %s", l.OrderType, order.Order.OrderType)).
+			DrawLine("-")
+
+	case SectionCustomer:
+		p.Align("left").
+			Bold(true).
+			Println(l.Customer).
+			Bold(false).
+			Println(fmt.Sprintf("%s: %s", l.Name, order.Customer.Name)).
+			Println(fmt.Sprintf("%s: %s", l.Phone, order.Customer.Phone)).
+			NewLine().
+			Println(l.Address + ":").
+			Println(order.Customer.Address.StreetAddress)
+		
+		if order.Customer.Address.GetFloor() > 0 || order.Customer.Address.GetApartment() > 0 {
+			p.Println(fmt.Sprintf(l.FloorApt, order.Customer.Address.GetFloor(), order.Customer.Address.GetApartment()))
 		}
-		p.Println(fmt.Sprintf("%-24s", name))
-		p.Println(fmt.Sprintf("  %d x %.2f TL = %.2f TL", item.Quantity, item.UnitPrice, item.TotalPrice))
-	}
-	
-	// Totals
-	p.DrawLine("-").
-		Align("right")
-	
-	p.Println(fmt.Sprintf("Ara Toplam: %.2f TL", order.Totals.Subtotal))
-	
-	if order.Totals.DeliveryFee > 0 {
-		p.Println(fmt.Sprintf("Paket Servis: %.2f TL", order.Totals.DeliveryFee))
+		
+		p.Println(fmt.Sprintf("%s, %s", order.Customer.Address.Neighborhood, order.Customer.Address.District)).
+			Println(order.Customer.Address.City)
+		
+		if order.Customer.Address.Description != "" {
+			p.PrintlnWrapped(fmt.Sprintf("%s: %s", l.AddressNote, order.Customer.Address.Description))
+		}
+		
+		p.DrawLine("-")
+
+	case SectionItems:
+		p.Align("left").
+			Bold(true).
+			Println(l.Items).
+			Bold(false)
+		
+		money := p.Money(DefaultOrderMoney)
+		for _, item := range order.Items {
+			p.Row(item.Name, money.Format(item.TotalPrice))
+			p.Println(fmt.Sprintf("  %s x %s", item.FormatQuantity(), money.Format(item.UnitPrice)))
+			for _, opt := range item.Options {
+				p.printItemOption(opt)
+			}
+		}
+
+	case SectionTotals:
+		money := p.Money(DefaultOrderMoney)
+		p.DrawLine("-").
+			Align("right")
+		
+		p.Println(fmt.Sprintf("%s: %s", l.Subtotal, money.Format(order.Totals.Subtotal)))
+		
+		if order.Totals.DeliveryFee > 0 {
+			p.Println(fmt.Sprintf("%s: %s", l.DeliveryFee, money.Format(order.Totals.DeliveryFee)))
+		}
+		
+		if order.Totals.ServiceFee > 0 {
+			p.Println(fmt.Sprintf("%s: %s", l.ServiceFee, money.Format(order.Totals.ServiceFee)))
+		}
+		
+		if order.Totals.Discount != 0 {
+			p.Println(fmt.Sprintf("%s: %s", l.Discount, money.Format(-math.Abs(order.Totals.Discount))))
+		}
+		
+		if order.Totals.Tip > 0 {
+			p.Println(fmt.Sprintf("%s: %s", l.Tip, money.Format(order.Totals.Tip)))
+		}
+		
+		if diff := order.Totals.Expected() - order.Totals.Total; math.Abs(diff) >= 0.01 {
+			log.Printf("[Template] Order totals don't add up: expected %.2f, got total %.2f", order.Totals.Expected(), order.Totals.Total)
+		}
+		
+		if order.Totals.VAT.Included {
+			p.Println(l.VATIncluded)
+		}
+		
+		p.NewLine().
+			Bold(true).
+			Size(1, 2).
+			Println(fmt.Sprintf("%s: %s", l.Total, money.Format(order.Totals.Total))).
+			Size(1, 1).
+			Bold(false)
+
+	case SectionPayment:
+		p.Align("left").
+			DrawLine("-").
+			Println(fmt.Sprintf("%s: %s", l.Payment, order.Payment.Method))
+		
+		if order.Payment.Note != "" {
+			p.Println(order.Payment.Note)
+		}
+
+	case SectionNotes:
+		if order.Notes.CustomerNote != nil && *order.Notes.CustomerNote != "" {
+			p.Align("left").
+				DrawLine("-").
+				Bold(true).
+				Println(l.CustomerNote + ":").
+				Bold(false).
+				PrintlnWrapped(*order.Notes.CustomerNote)
+		}
+
+	case SectionFooter:
+		p.DrawLine("=").
+			Align("center").
+			NewLine().
+			Println(l.Footer).
+			NewLine()
+
+	case SectionText:
+		align := section.Align
+		if align == "" {
+			align = "left"
+		}
+		p.Align(align).
+			Bold(section.Bold).
+			PrintlnWrapped(section.Text).
+			Bold(false)
+
+	case SectionTracking:
+		if order.TrackingURL == "" {
+			return
+		}
+		// validate rejects orders whose URL doesn't fit a QR code, so this
+		// only fails for orders that weren't parsed by ParseTemplateOrder
+		p.Align("center")
+		if err := p.QRCodeChecked(order.TrackingURL, section.Size, QRErrorM, QRModel2); err != nil {
+			return
+		}
+		p.NewLine()
+
+	case SectionLine:
+		char := section.Char
+		if char == "" {
+			char = "-"
+		}
+		p.DrawLine(char)
 	}
-	
-	if order.Totals.VAT.Included {
-		p.Println("(KDV Dahil)")
+}
+
+// printItemOption prints an item modifier indented under the item, with
+// its price delta (if any) right-aligned on the first line. Long names wrap.
+func (p *Printer) printItemOption(opt ItemOption) {
+	const indent = "  + "
+	price := ""
+	if opt.Price != 0 {
+		price = p.Money(DefaultOrderMoney).FormatSigned(opt.Price)
 	}
-	
-	p.NewLine().
-		Bold(true).
-		Size(1, 2).
-		Println(fmt.Sprintf("TOPLAM: %.2f TL", order.Totals.Total)).
-		Size(1, 1).
-		Bold(false)
-	
-	// Payment
-	p.Align("left").
-		DrawLine("-").
-		Println(fmt.Sprintf("Ödeme: %s", order.Payment.Method))
-	
-	if order.Payment.Note != "" {
-		p.Println(order.Payment.Note)
+
+	nameWidth := p.LineWidth() - len(indent)
+	if price != "" {
+		nameWidth -= DisplayWidth(price) + 1
 	}
-	
-	// Customer notes
-	if order.Notes.CustomerNote != nil && *order.Notes.CustomerNote != "" {
-		p.DrawLine("-").
-			Bold(true).
-			Println("MÜŞTERİ NOTU:").
-			Bold(false).
-			Println(*order.Notes.CustomerNote)
+
+	for i, line := range WrapText(opt.Name, nameWidth) {
+		if i == 0 {
+			p.Row(indent+line, price)
+		} else {
+			p.Println(strings.Repeat(" ", len(indent)) + line)
+		}
 	}
-	
-	// Footer
-	p.DrawLine("=").
-		Align("center").
-		NewLine().
-		Println("Afiyet olsun!").
-		NewLine().
-		Feed(2).
-		Cut(false)
-	
-	return p.Flush()
 }
 
 // ParseTemplateOrder parses JSON data into a TemplateOrder
@@ -391,5 +645,85 @@ func ParseTemplateOrder(data []byte) (*TemplateOrder, error) {
 	if err := json.Unmarshal(data, &order); err != nil {
 		return nil, fmt.Errorf("failed to parse order: %w", err)
 	}
+	if !ValidCutMode(order.Cut) {
+		return nil, fmt.Errorf("invalid cut mode %q: must be full, partial or none", order.Cut)
+	}
+
+	// 0 is a valid total, so check that one was sent at all
+	var present struct {
+		Totals struct {
+			Total *float64 `json:"total_try"`
+		} `json:"totals"`
+	}
+	json.Unmarshal(data, &present)
+	if err := order.validate(present.Totals.Total != nil); err != nil {
+		return nil, err
+	}
 	return &order, nil
 }
+
+// OrderError lists the problems that make an order unprintable.
+type OrderError struct {
+	Missing []string `json:"missing,omitempty"` // Required fields that are absent or empty, e.g. "merchant.name"
+	Invalid []string `json:"invalid,omitempty"` // Fields with out-of-range values
+}
+
+func (e *OrderError) Error() string {
+	var problems []string
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(e.Missing, ", "))
+	}
+	problems = append(problems, e.Invalid...)
+	return "invalid order: " + strings.Join(problems, "; ")
+}
+
+// validate checks that the order has what every receipt needs: a merchant
+// name, at least one named item, and a total, with no negative amounts,
+// and that the tracking URL, if any, fits in a QR code. Everything else,
+// such as the customer note and fees, is optional.
+func (o *TemplateOrder) validate(hasTotal bool) error {
+	e := &OrderError{}
+	if strings.TrimSpace(o.Merchant.Name) == "" {
+		e.Missing = append(e.Missing, "merchant.name")
+	}
+	if len(o.Items) == 0 {
+		e.Missing = append(e.Missing, "items")
+	}
+	for i, item := range o.Items {
+		if strings.TrimSpace(item.Name) == "" {
+			e.Missing = append(e.Missing, fmt.Sprintf("items[%d].name", i))
+		}
+		if item.Quantity <= 0 {
+			e.Invalid = append(e.Invalid, fmt.Sprintf("items[%d].quantity must be positive", i))
+		}
+	}
+	if !hasTotal {
+		e.Missing = append(e.Missing, "totals.total_try")
+	}
+
+	// Discounts may be sent with either sign
+	amounts := []struct {
+		field string
+		value float64
+	}{
+		{"subtotal_try", o.Totals.Subtotal},
+		{"delivery_fee_try", o.Totals.DeliveryFee},
+		{"service_fee_try", o.Totals.ServiceFee},
+		{"tip_try", o.Totals.Tip},
+		{"total_try", o.Totals.Total},
+	}
+	for _, a := range amounts {
+		if a.value < 0 {
+			e.Invalid = append(e.Invalid, fmt.Sprintf("totals.%s must not be negative", a.field))
+		}
+	}
+
+	if limit := QRCapacity(QRErrorM, QRModel2); len(o.TrackingURL) > limit {
+		e.Invalid = append(e.Invalid, fmt.Sprintf("tracking_url is %d bytes, at most %d fit in a QR code", len(o.TrackingURL), limit))
+	}
+
+	if len(e.Missing) > 0 || len(e.Invalid) > 0 {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package printer
+
+import (
+	"image"
+	"testing"
+)
+
+func TestImageToRasterFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxDots       int
+		wantW, wantH  int // Scaled size in dots
+	}{
+		{"wide logo on 80mm", 1000, 200, 576, 576, 115},
+		{"wide logo on 58mm", 1000, 200, 384, 384, 76},
+		{"tall logo", 200, 1000, 576, 115, 576},
+		{"fits already", 300, 100, 576, 300, 100},
+		{"max rounded down to bytes", 1000, 100, 580, 576, 57},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewGray(image.Rect(0, 0, tt.width, tt.height))
+
+			scaled := ScaleToFit(img, tt.maxDots).Bounds()
+			if scaled.Dx() != tt.wantW || scaled.Dy() != tt.wantH {
+				t.Errorf("ScaleToFit = %dx%d, want %dx%d", scaled.Dx(), scaled.Dy(), tt.wantW, tt.wantH)
+			}
+
+			data, widthBytes, height := ImageToRasterFit(img, tt.maxDots)
+			if widthBytes*8 > tt.maxDots {
+				t.Errorf("widthBytes*8 = %d, exceeds maxDots %d", widthBytes*8, tt.maxDots)
+			}
+			if height != tt.wantH {
+				t.Errorf("height = %d, want %d", height, tt.wantH)
+			}
+			if len(data) != widthBytes*height {
+				t.Errorf("len(data) = %d, want widthBytes*height = %d", len(data), widthBytes*height)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Truncate shortens s to at most max runes without splitting multi-byte
+// characters (e.g. Turkish ş/ğ/ı).
+func Truncate(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	n := 0
+	for i := range s {
+		if n == max {
+			return s[:i]
+		}
+		n++
+	}
+	return s
+}
+
+// TruncateWidth shortens s so that it occupies at most maxWidth printer
+// columns, counting double-width (CJK) glyphs as two columns.
+func TruncateWidth(s string, maxWidth int) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	w := 0
+	for i, r := range s {
+		rw := RuneWidth(r)
+		if w+rw > maxWidth {
+			return s[:i]
+		}
+		w += rw
+	}
+	return s
+}
+
+// DisplayWidth returns the number of printer columns s occupies.
+func DisplayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += RuneWidth(r)
+	}
+	return w
+}
+
+// RuneWidth returns the column width of r: 2 for East Asian wide and
+// full-width glyphs, 0 for control characters, 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case r >= 0x1100 && r <= 0x115f, // Hangul Jamo
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f, // CJK radicals .. Yi
+		r >= 0xac00 && r <= 0xd7a3,                // Hangul syllables
+		r >= 0xf900 && r <= 0xfaff,                // CJK compatibility ideographs
+		r >= 0xfe30 && r <= 0xfe4f,                // CJK compatibility forms
+		r >= 0xff00 && r <= 0xff60,                // Full-width forms
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x20000 && r <= 0x3fffd: // CJK extensions
+		return 2
+	}
+	return 1
+}
+
+// WrapText splits s into lines of at most width columns, breaking on word
+// boundaries. Words longer than a line are hard-broken. Existing newlines
+// are preserved.
+func WrapText(s string, width int) []string {
+	if width <= 0 {
+		return []string{s}
+	}
+
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		lineWidth := 0
+		for _, word := range words {
+			wordWidth := DisplayWidth(word)
+
+			// Hard-break words that can't fit on a line of their own
+			for wordWidth > width {
+				if lineWidth > 0 {
+					lines = append(lines, line)
+					line, lineWidth = "", 0
+				}
+				head := TruncateWidth(word, width)
+				if head == "" {
+					// Single glyph wider than the line, emit it as-is
+					head = string([]rune(word)[:1])
+				}
+				lines = append(lines, head)
+				word = word[len(head):]
+				wordWidth = DisplayWidth(word)
+			}
+			if wordWidth == 0 {
+				continue
+			}
+
+			switch {
+			case lineWidth == 0:
+				line, lineWidth = word, wordWidth
+			case lineWidth+1+wordWidth <= width:
+				line += " " + word
+				lineWidth += 1 + wordWidth
+			default:
+				lines = append(lines, line)
+				line, lineWidth = word, wordWidth
+			}
+		}
+		if lineWidth > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"Köfte Döner Porsiyon", 5, "Köfte"},
+		{"Köfte Döner Porsiyon", 8, "Köfte Dö"},
+		{"Köfte Döner Porsiyon", 20, "Köfte Döner Porsiyon"},
+		{"Köfte Döner Porsiyon", 0, ""},
+		{"şğıüçö", 3, "şğı"},
+		{"牛肉拉面套餐", 4, "牛肉拉面"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.max); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"Köfte Döner Porsiyon", 7, "Köfte D"},
+		{"Köfte Döner Porsiyon", 8, "Köfte Dö"},
+		{"牛肉拉面套餐", 4, "牛肉"},
+		{"牛肉拉面套餐", 5, "牛肉"}, // A wide glyph doesn't fit in the last column
+		{"牛肉 noodles", 6, "牛肉 n"},
+	}
+	for _, tt := range tests {
+		got := TruncateWidth(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("TruncateWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if w := DisplayWidth(got); w > tt.width {
+			t.Errorf("TruncateWidth(%q, %d) is %d columns wide", tt.s, tt.width, w)
+		}
+	}
+}
+
+// Truncating at every length must never split a character.
+func TestTruncateKeepsValidUTF8(t *testing.T) {
+	for _, s := range []string{"Köfte Döner Porsiyon", "İşkembe çorbası", "牛肉拉面套餐", "Ramen ラーメン 라면"} {
+		for n := 0; n <= utf8.RuneCountInString(s)*2; n++ {
+			for _, got := range []string{Truncate(s, n), TruncateWidth(s, n)} {
+				if !utf8.ValidString(got) {
+					t.Errorf("truncating %q to %d gave invalid UTF-8 % x", s, n, got)
+				}
+				if !strings.HasPrefix(s, got) {
+					t.Errorf("truncating %q to %d gave %q, not a prefix", s, n, got)
+				}
+			}
+		}
+	}
+
+	// Printed rows are truncated the same way
+	p := newTestPrinter()
+	p.SetWidth(10)
+	p.Row("Köfte Döner Porsiyon", "12,00")
+	if !utf8.Valid(p.buffer) {
+		t.Errorf("Row emitted invalid UTF-8: % x", p.buffer)
+	}
+}
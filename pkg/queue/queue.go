@@ -0,0 +1,413 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job states
+const (
+	StatusQueued   = "queued"
+	StatusPrinting = "printing"
+	StatusDone     = "done"
+	StatusFailed   = "failed"
+)
+
+// maxFinished is the number of completed/failed jobs kept for status lookups.
+const maxFinished = 200
+
+// Retry backoff: the delay doubles after each failed attempt up to the maximum.
+const (
+	DefaultMaxAttempts = 5
+	retryBaseDelay     = 2 * time.Second
+	retryMaxDelay      = 2 * time.Minute
+)
+
+// Job is a single print job.
+type Job struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"` // Last error, kept while retrying
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	NextAttempt *time.Time `json:"next_attempt,omitempty"` // Set while waiting to retry
+
+	Payload []byte `json:"-"` // Request body, interpreted by the Handler
+}
+
+// Handler prints a job. A returned error causes the job to be retried with
+// backoff; wrap it with Permanent to fail the job immediately.
+type Handler func(job *Job) error
+
+// Queue is an in-memory print job queue drained by a single background
+// worker. Pending jobs are persisted to disk so they survive restarts.
+type Queue struct {
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	pending  []string
+	finished []string
+	notify   chan struct{}
+	path     string
+	handler  Handler
+
+	maxAttempts int
+
+	started  bool
+	stop     chan struct{} // Closed by Stop
+	stopOnce sync.Once
+	done     chan struct{} // Closed when the worker exits
+}
+
+// New creates a queue that persists pending jobs to path and prints them
+// with handler. Call Start to begin processing.
+func New(path string, handler Handler) *Queue {
+	return &Queue{
+		jobs:    make(map[string]*Job),
+		notify:  make(chan struct{}, 1),
+		path:    path,
+		handler: handler,
+
+		maxAttempts: DefaultMaxAttempts,
+
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// SetMaxAttempts sets how many times a job is tried before it is marked
+// failed. Values below 1 mean a single attempt.
+func (q *Queue) SetMaxAttempts(n int) {
+	if n < 1 {
+		n = 1
+	}
+	q.mu.Lock()
+	q.maxAttempts = n
+	q.mu.Unlock()
+}
+
+// Start restores persisted jobs and starts the background worker.
+func (q *Queue) Start() {
+	if err := q.load(); err != nil {
+		log.Printf("[Queue] Failed to restore pending jobs: %v", err)
+	}
+	q.mu.Lock()
+	q.started = true
+	q.mu.Unlock()
+
+	go q.run()
+	q.wake()
+}
+
+// Stop finishes the job being printed and any others that are due, then
+// stops the worker. Jobs waiting for a retry stay persisted for the next
+// start. It returns ctx.Err() if ctx ends before the worker has stopped.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	started := q.started
+	q.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	q.stopOnce.Do(func() { close(q.stop) })
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue adds a job and returns a copy of it.
+func (q *Queue) Enqueue(kind string, payload []byte) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:        id,
+		Kind:      kind,
+		Status:    StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Payload:   payload,
+	}
+
+	q.mu.Lock()
+	q.jobs[id] = job
+	q.pending = append(q.pending, id)
+	err = q.saveLocked()
+	snapshot := *job
+	q.mu.Unlock()
+
+	if err != nil {
+		log.Printf("[Queue] Failed to persist jobs: %v", err)
+	}
+	q.wake()
+	return snapshot, nil
+}
+
+// Get returns a copy of the job with the given ID.
+func (q *Queue) Get(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Pending returns the number of jobs waiting to be printed.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// List returns copies of all known jobs, pending first.
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.pending)+len(q.finished)+1)
+	for _, job := range q.jobs {
+		if job.Status == StatusPrinting {
+			jobs = append(jobs, *job)
+		}
+	}
+	for _, id := range q.pending {
+		jobs = append(jobs, *q.jobs[id])
+	}
+	for i := len(q.finished) - 1; i >= 0; i-- {
+		jobs = append(jobs, *q.jobs[q.finished[i]])
+	}
+	return jobs
+}
+
+// wake signals the worker that there may be work to do.
+func (q *Queue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run is the worker loop. Jobs are printed in order; a job waiting for a
+// retry holds back the jobs behind it so receipts don't print out of order.
+func (q *Queue) run() {
+	defer close(q.done)
+
+	for {
+		job, wait := q.next()
+		if job == nil {
+			var retry <-chan time.Time
+			if wait > 0 {
+				retry = time.After(wait)
+			}
+			select {
+			case <-q.notify:
+			case <-retry:
+			case <-q.stop:
+				return
+			}
+			continue
+		}
+
+		err := q.handler(job)
+		q.finish(job, err)
+	}
+}
+
+// next marks the oldest pending job as printing and returns it. If that job
+// is waiting for its next retry, it returns nil and the time left to wait.
+func (q *Queue) next() (*Job, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil, 0
+	}
+	job := q.jobs[q.pending[0]]
+	if job.NextAttempt != nil {
+		if wait := time.Until(*job.NextAttempt); wait > 0 {
+			return nil, wait
+		}
+	}
+
+	q.pending = q.pending[1:]
+	job.Status = StatusPrinting
+	job.Attempts++
+	job.UpdatedAt = time.Now()
+	return job, 0
+}
+
+// finish records the result of a job, re-queueing it with exponential
+// backoff if it failed and has attempts left.
+func (q *Queue) finish(job *Job, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job.UpdatedAt = time.Now()
+
+	if err != nil {
+		job.Error = err.Error()
+
+		var perm *permanentError
+		if !errors.As(err, &perm) && job.Attempts < q.maxAttempts {
+			delay := retryBaseDelay << (job.Attempts - 1)
+			if delay > retryMaxDelay || delay <= 0 {
+				delay = retryMaxDelay
+			}
+			job.Status = StatusQueued
+			retryAt := job.UpdatedAt.Add(delay)
+			job.NextAttempt = &retryAt
+			q.pending = append([]string{job.ID}, q.pending...)
+			log.Printf("[Queue] Job %s failed (attempt %d/%d), retrying in %v: %v",
+				job.ID, job.Attempts, q.maxAttempts, delay, err)
+
+			if err := q.saveLocked(); err != nil {
+				log.Printf("[Queue] Failed to persist jobs: %v", err)
+			}
+			return
+		}
+
+		job.Status = StatusFailed
+		log.Printf("[Queue] Job %s failed after %d attempts: %v", job.ID, job.Attempts, err)
+	} else {
+		job.Status = StatusDone
+		job.Error = ""
+	}
+	job.NextAttempt = nil
+	job.Payload = nil
+
+	q.finished = append(q.finished, job.ID)
+	if len(q.finished) > maxFinished {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+
+	if err := q.saveLocked(); err != nil {
+		log.Printf("[Queue] Failed to persist jobs: %v", err)
+	}
+}
+
+// permanentError marks a job error that retrying won't fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the queue fails the job immediately instead of
+// retrying it (e.g. for malformed payloads).
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// persistedJob is the on-disk form of a pending job.
+type persistedJob struct {
+	*Job
+	Payload json.RawMessage `json:"payload"`
+}
+
+// saveLocked writes all unfinished jobs to disk. q.mu must be held.
+func (q *Queue) saveLocked() error {
+	if q.path == "" {
+		return nil
+	}
+
+	var jobs []persistedJob
+	for _, job := range q.jobs {
+		if job.Status == StatusQueued || job.Status == StatusPrinting {
+			jobs = append(jobs, persistedJob{Job: job, Payload: job.Payload})
+		}
+	}
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+// load restores unfinished jobs from disk. Jobs that were printing when the
+// service stopped are queued again.
+func (q *Queue) load() error {
+	if q.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var jobs []persistedJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", q.path, err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, pj := range jobs {
+		if pj.Job == nil || pj.ID == "" {
+			continue
+		}
+		job := pj.Job
+		job.Payload = pj.Payload
+		job.Status = StatusQueued
+		q.jobs[job.ID] = job
+		q.pending = append(q.pending, job.ID)
+	}
+	sort.Slice(q.pending, func(i, j int) bool {
+		return q.jobs[q.pending[i]].CreatedAt.Before(q.jobs[q.pending[j]].CreatedAt)
+	})
+
+	if len(jobs) > 0 {
+		log.Printf("[Queue] Restored %d pending jobs", len(q.pending))
+	}
+	return nil
+}
+
+// newID returns a random 16-character hex job ID.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,271 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errOffline = errors.New("printer offline")
+
+// flakyPrinter fails the first failures jobs it prints, like a printer
+// that is offline for a while.
+type flakyPrinter struct {
+	mu       sync.Mutex
+	failures int
+	printed  []string
+}
+
+func (f *flakyPrinter) handle(job *Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errOffline
+	}
+	f.printed = append(f.printed, string(job.Payload))
+	return nil
+}
+
+func (f *flakyPrinter) Printed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.printed...)
+}
+
+// attempt prints the next due job the way the worker does, and returns
+// it, or nil if no job is due.
+func attempt(t *testing.T, q *Queue) *Job {
+	t.Helper()
+	job, _ := q.next()
+	if job == nil {
+		return nil
+	}
+	q.finish(job, q.handler(job))
+	return job
+}
+
+// makeDue moves the retry of the job with the given ID to now.
+func makeDue(q *Queue, id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	q.jobs[id].NextAttempt = &now
+}
+
+// waitFor polls the job with the given ID until it has status.
+func waitFor(t *testing.T, q *Queue, id, status string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, ok := q.Get(id)
+		if ok && job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %+v, want %s", id, job, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	printer := &flakyPrinter{failures: 100}
+	q := New("", printer.handle)
+	q.SetMaxAttempts(10)
+	queued, err := q.Enqueue("print", []byte("receipt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The delay doubles from 2s, up to 2 minutes
+	delays := []time.Duration{2, 4, 8, 16, 32, 64, 120, 120, 120}
+	for i, want := range delays {
+		if attempt(t, q) == nil {
+			t.Fatalf("attempt %d: no job due", i+1)
+		}
+		job, _ := q.Get(queued.ID)
+		if job.Status != StatusQueued || job.Attempts != i+1 || job.Error != errOffline.Error() {
+			t.Fatalf("after attempt %d: job = %+v, want queued with the error", i+1, job)
+		}
+		if job.NextAttempt == nil {
+			t.Fatalf("after attempt %d: no retry scheduled", i+1)
+		}
+		if got := job.NextAttempt.Sub(job.UpdatedAt); got != want*time.Second {
+			t.Errorf("after attempt %d: retry in %v, want %v", i+1, got, want*time.Second)
+		}
+
+		// The job isn't tried again before its retry is due
+		if job, wait := q.next(); job != nil || wait <= 0 {
+			t.Fatalf("after attempt %d: next() = %v, %v, want to wait", i+1, job, wait)
+		}
+		makeDue(q, queued.ID)
+	}
+
+	// The last attempt fails the job for good
+	attempt(t, q)
+	job, _ := q.Get(queued.ID)
+	if job.Status != StatusFailed || job.Attempts != 10 || job.NextAttempt != nil {
+		t.Errorf("after the last attempt: job = %+v, want failed after 10 attempts", job)
+	}
+	if q.Pending() != 0 {
+		t.Errorf("Pending() = %d, want 0", q.Pending())
+	}
+}
+
+func TestRetryRecovers(t *testing.T) {
+	printer := &flakyPrinter{failures: 2}
+	q := New("", printer.handle)
+	queued, err := q.Enqueue("print", []byte("receipt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			makeDue(q, queued.ID)
+		}
+		if attempt(t, q) == nil {
+			t.Fatalf("attempt %d: no job due", i+1)
+		}
+	}
+
+	job, _ := q.Get(queued.ID)
+	if job.Status != StatusDone || job.Attempts != 3 || job.Error != "" || job.NextAttempt != nil {
+		t.Errorf("job = %+v, want done after 3 attempts", job)
+	}
+	if got := printer.Printed(); len(got) != 1 || got[0] != "receipt" {
+		t.Errorf("printed %q, want the receipt once", got)
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	tests := []struct{ set, want int }{{3, 3}, {1, 1}, {0, 1}, {-2, 1}}
+	for _, tt := range tests {
+		q := New("", (&flakyPrinter{failures: 100}).handle)
+		q.SetMaxAttempts(tt.set)
+		queued, err := q.Enqueue("print", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for attempt(t, q) != nil {
+			makeDue(q, queued.ID)
+		}
+		if job, _ := q.Get(queued.ID); job.Status != StatusFailed || job.Attempts != tt.want {
+			t.Errorf("SetMaxAttempts(%d): job = %+v, want failed after %d attempts", tt.set, job, tt.want)
+		}
+	}
+}
+
+func TestRetryHoldsBackLaterJobs(t *testing.T) {
+	printer := &flakyPrinter{failures: 1}
+	q := New("", printer.handle)
+	first, _ := q.Enqueue("print", []byte("first"))
+	second, _ := q.Enqueue("print", []byte("second"))
+
+	attempt(t, q)
+	if job := attempt(t, q); job != nil {
+		t.Fatalf("printed %s while the first job waits for a retry", job.ID)
+	}
+	makeDue(q, first.ID)
+	attempt(t, q)
+	attempt(t, q)
+
+	if got := printer.Printed(); len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("printed %q, want first then second", got)
+	}
+	if job, _ := q.Get(second.ID); job.Status != StatusDone {
+		t.Errorf("second job = %+v, want done", job)
+	}
+}
+
+func TestWorker(t *testing.T) {
+	q := New(filepath.Join(t.TempDir(), "queue.json"), func(job *Job) error {
+		if string(job.Payload) == `"bad"` {
+			return Permanent(errors.New("invalid payload"))
+		}
+		return nil
+	})
+	q.Start()
+	defer q.Stop(context.Background())
+
+	good, _ := q.Enqueue("print", []byte(`"good"`))
+	bad, _ := q.Enqueue("print", []byte(`"bad"`))
+
+	if job := waitFor(t, q, good.ID, StatusDone); job.Attempts != 1 || job.Payload != nil {
+		t.Errorf("good job = %+v, want done after one attempt", job)
+	}
+	// Permanent errors aren't retried
+	if job := waitFor(t, q, bad.ID, StatusFailed); job.Attempts != 1 || job.Error != "invalid payload" {
+		t.Errorf("bad job = %+v, want failed after one attempt", job)
+	}
+}
+
+func TestReloadFromDisk(t *testing.T) {
+	// Payloads are persisted as JSON, as the request bodies they come from
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q := New(path, (&flakyPrinter{failures: 1}).handle)
+	first, _ := q.Enqueue("print", []byte(`"first"`))
+	time.Sleep(time.Millisecond) // Distinct CreatedAt, which restores the order
+	second, _ := q.Enqueue("text", []byte(`"second"`))
+	done, _ := q.Enqueue("print", []byte(`"done"`))
+
+	// The first job fails once; the last is printed out of turn, so only
+	// it is finished
+	attempt(t, q)
+	q.mu.Lock()
+	q.pending = q.pending[:2]
+	q.mu.Unlock()
+	q.finish(q.jobs[done.ID], nil)
+
+	printer := &flakyPrinter{}
+	restored := New(path, printer.handle)
+	if err := restored.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := restored.Pending(); got != 2 {
+		t.Fatalf("restored %d pending jobs, want 2", got)
+	}
+	if _, ok := restored.Get(done.ID); ok {
+		t.Error("finished job was restored")
+	}
+
+	job, _ := restored.Get(first.ID)
+	if job.Status != StatusQueued || job.Attempts != 1 || job.NextAttempt == nil || string(job.Payload) != `"first"` {
+		t.Errorf("first job = %+v, want queued with its attempt, retry time and payload", job)
+	}
+	if job, _ := restored.Get(second.ID); job.Kind != "text" || string(job.Payload) != `"second"` {
+		t.Errorf("second job = %+v, want kind text with its payload", job)
+	}
+
+	// Restored jobs print in their original order
+	makeDue(restored, first.ID)
+	attempt(t, restored)
+	attempt(t, restored)
+	if got := printer.Printed(); len(got) != 2 || got[0] != `"first"` || got[1] != `"second"` {
+		t.Errorf("printed %q, want first then second", got)
+	}
+}
+
+func TestReloadRequeuesPrintingJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q := New(path, nil)
+	queued, _ := q.Enqueue("print", []byte(`"receipt"`))
+
+	// The service stops while the job is printing
+	q.next()
+	q.mu.Lock()
+	q.saveLocked()
+	q.mu.Unlock()
+
+	restored := New(path, nil)
+	if err := restored.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if job, ok := restored.Get(queued.ID); !ok || job.Status != StatusQueued {
+		t.Errorf("restored job = %+v, want queued again", job)
+	}
+}
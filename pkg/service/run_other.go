@@ -0,0 +1,14 @@
+//go:build !windows
+
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// Run is only available on Windows, where the installed service runs under
+// the service control manager.
+func Run(serve func(ctx context.Context)) error {
+	return errors.New("running under the service control manager is only supported on Windows")
+}
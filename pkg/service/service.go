@@ -0,0 +1,18 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"printbridge/pkg/config"
+)
+
+// configPath returns the absolute path of the config file this process
+// uses, which the installed service is pointed at with PRINTBRIDGE_CONFIG.
+func configPath() (string, error) {
+	path, err := filepath.Abs(config.GetConfigPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to get config path: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,165 @@
+//go:build darwin
+// +build darwin
+
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const launchAgentPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>Label</key>
+    <string>com.printbridge.service</string>
+    <key>ProgramArguments</key>
+    <array>
+        <string>%s</string>
+    </array>
+    <key>RunAtLoad</key>
+    <true/>
+    <key>KeepAlive</key>
+    <dict>
+        <key>SuccessfulExit</key>
+        <false/>
+    </dict>
+    <key>StandardOutPath</key>
+    <string>/tmp/printbridge.log</string>
+    <key>StandardErrorPath</key>
+    <string>/tmp/printbridge.error.log</string>
+    <key>WorkingDirectory</key>
+    <string>%s</string>
+    <key>EnvironmentVariables</key>
+    <dict>
+        <key>PRINTBRIDGE_CONFIG</key>
+        <string>%s</string>
+    </dict>
+</dict>
+</plist>`
+
+// Install installs the service as a macOS LaunchAgent.
+func Install() error {
+	// Get executable path
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	execPath, err = filepath.Abs(execPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	workDir := filepath.Dir(execPath)
+	cfgPath, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	// Create LaunchAgents directory if needed
+	plistPath, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+	}
+
+	// Write plist file
+	plistContent := fmt.Sprintf(launchAgentPlist, xmlEscape(execPath), xmlEscape(workDir), xmlEscape(cfgPath))
+
+	if err := os.WriteFile(plistPath, []byte(plistContent), 0644); err != nil {
+		return fmt.Errorf("failed to write plist: %w", err)
+	}
+
+	// Load the service
+	cmd := exec.Command("launchctl", "load", plistPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load service: %s: %w", string(output), err)
+	}
+
+	fmt.Printf("Service installed: %s\n", plistPath)
+	fmt.Printf("Config: %s\n", cfgPath)
+	fmt.Println("Service will start automatically on login")
+	return nil
+}
+
+// Uninstall removes the macOS LaunchAgent.
+func Uninstall() error {
+	plistPath, err := plistPath()
+	if err != nil {
+		return err
+	}
+
+	// Unload the service
+	cmd := exec.Command("launchctl", "unload", plistPath)
+	cmd.Run() // Ignore error if not loaded
+
+	// Remove the plist file
+	if err := os.Remove(plistPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove plist: %w", err)
+	}
+
+	fmt.Println("Service uninstalled")
+	return nil
+}
+
+// Installed reports whether the LaunchAgent is installed.
+func Installed() bool {
+	plistPath, err := plistPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(plistPath)
+	return err == nil
+}
+
+// ServiceStatus reports whether the LaunchAgent is installed and whether
+// launchd has it running.
+func ServiceStatus() (installed bool, running bool, err error) {
+	plistPath, err := plistPath()
+	if err != nil {
+		return false, false, err
+	}
+	if _, err := os.Stat(plistPath); err != nil {
+		if os.IsNotExist(err) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to check plist: %w", err)
+	}
+
+	// launchctl list fails for an agent that isn't loaded, and only shows a
+	// PID while it runs
+	output, err := exec.Command("launchctl", "list", "com.printbridge.service").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return true, false, nil
+	}
+	if err != nil {
+		return true, false, fmt.Errorf("failed to query launchd: %w", err)
+	}
+	return true, strings.Contains(string(output), `"PID" =`), nil
+}
+
+// plistPath returns the path of the LaunchAgent plist.
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", "com.printbridge.service.plist"), nil
+}
+
+// xmlEscape escapes s for use as plist string content.
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,156 @@
+//go:build linux
+// +build linux
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const systemdService = `[Unit]
+Description=PrintBridge Receipt Printer Service
+After=network.target
+
+[Service]
+Type=simple
+ExecStart=%s
+Environment=%s
+Restart=on-failure
+RestartSec=5
+WorkingDirectory=%s
+StandardOutput=journal
+StandardError=journal
+
+[Install]
+WantedBy=default.target
+`
+
+// Install installs the service as a systemd unit.
+func Install() error {
+	// Get executable path
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	execPath, err = filepath.Abs(execPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	workDir := filepath.Dir(execPath)
+	cfgPath, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	// Create systemd user directory if needed
+	servicePath, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(servicePath), 0755); err != nil {
+		return fmt.Errorf("failed to create systemd directory: %w", err)
+	}
+
+	// Write service file
+	serviceContent := fmt.Sprintf(systemdService,
+		systemdQuote(execPath),
+		systemdQuote("PRINTBRIDGE_CONFIG="+cfgPath),
+		strings.ReplaceAll(workDir, "%", "%%"))
+
+	if err := os.WriteFile(servicePath, []byte(serviceContent), 0644); err != nil {
+		return fmt.Errorf("failed to write service file: %w", err)
+	}
+
+	// Reload systemd
+	exec.Command("systemctl", "--user", "daemon-reload").Run()
+
+	// Enable and start the service
+	cmd := exec.Command("systemctl", "--user", "enable", "--now", "printbridge.service")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to enable service: %s: %w", string(output), err)
+	}
+
+	fmt.Printf("Service installed: %s\n", servicePath)
+	fmt.Printf("Config: %s\n", cfgPath)
+	fmt.Println("Service enabled and started")
+	return nil
+}
+
+// Uninstall removes the systemd unit.
+func Uninstall() error {
+	// Stop and disable the service
+	exec.Command("systemctl", "--user", "stop", "printbridge.service").Run()
+	exec.Command("systemctl", "--user", "disable", "printbridge.service").Run()
+
+	// Remove the service file
+	servicePath, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(servicePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove service file: %w", err)
+	}
+
+	// Reload systemd
+	exec.Command("systemctl", "--user", "daemon-reload").Run()
+
+	fmt.Println("Service uninstalled")
+	return nil
+}
+
+// Installed reports whether the systemd unit is installed.
+func Installed() bool {
+	servicePath, err := unitPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(servicePath)
+	return err == nil
+}
+
+// ServiceStatus reports whether the systemd unit is installed and whether
+// systemd has it running.
+func ServiceStatus() (installed bool, running bool, err error) {
+	servicePath, err := unitPath()
+	if err != nil {
+		return false, false, err
+	}
+	if _, err := os.Stat(servicePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to check service file: %w", err)
+	}
+
+	// is-active exits non-zero when the unit isn't running
+	err = exec.Command("systemctl", "--user", "is-active", "--quiet", "printbridge.service").Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return true, false, nil
+	}
+	if err != nil {
+		return true, false, fmt.Errorf("failed to query systemd: %w", err)
+	}
+	return true, true, nil
+}
+
+// unitPath returns the path of the systemd user unit.
+func unitPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "systemd", "user", "printbridge.service"), nil
+}
+
+// systemdQuote quotes s as a single word of a unit file setting, escaping
+// "%" so it isn't taken as a specifier.
+func systemdQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(s) + `"`
+}
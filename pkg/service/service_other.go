@@ -0,0 +1,29 @@
+//go:build !linux && !darwin && !windows
+
+package service
+
+import "errors"
+
+// errUnsupported is returned on platforms without an autostart mechanism.
+var errUnsupported = errors.New("installing the service is not supported on this platform")
+
+// Install is not supported on this platform.
+func Install() error {
+	return errUnsupported
+}
+
+// Uninstall is not supported on this platform.
+func Uninstall() error {
+	return errUnsupported
+}
+
+// ServiceStatus always reports the service as not installed on this
+// platform.
+func ServiceStatus() (installed bool, running bool, err error) {
+	return false, false, nil
+}
+
+// Installed always reports false on this platform.
+func Installed() bool {
+	return false
+}
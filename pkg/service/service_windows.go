@@ -0,0 +1,300 @@
+//go:build windows
+// +build windows
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceName is the name of the Windows service and the scheduled task.
+const serviceName = "PrintBridge"
+
+// stopTimeout bounds how long Uninstall waits for the service to stop.
+const stopTimeout = 30 * time.Second
+
+// Install installs the service as a Windows service that starts at boot
+// and is restarted if it crashes. Without administrator rights it falls
+// back to a scheduled task that runs at logon.
+func Install() error {
+	// Get executable path
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	execPath, err = filepath.Abs(execPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	cfgPath, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	err = installService(execPath, cfgPath)
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		fmt.Println("Not running as administrator, installing a scheduled task instead")
+		return installTask(execPath, cfgPath)
+	}
+	return err
+}
+
+// installService registers the Windows service with the service control
+// manager and starts it.
+func installService(execPath, cfgPath string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+
+	// The service runs as LocalSystem, whose config directory differs from
+	// the user's, so it is pointed at the user's config
+	s, err := m.CreateService(serviceName, execPath, mgr.Config{
+		DisplayName: "PrintBridge",
+		Description: "Receipt printer service",
+		StartType:   mgr.StartAutomatic,
+	}, "--service", "--config", cfgPath)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+
+	// Restart after a crash: twice quickly, then after a minute. The count
+	// resets after a day without failures.
+	err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+	}, uint32((24 * time.Hour).Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to set recovery actions: %w", err)
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+
+	fmt.Printf("Service installed: %s\n", serviceName)
+	fmt.Printf("Config: %s\n", cfgPath)
+	fmt.Println("Service started; it starts at boot and restarts after a crash")
+	return nil
+}
+
+// installTask creates a scheduled task that runs the service at logon.
+func installTask(execPath, cfgPath string) error {
+	cmd := exec.Command("schtasks", "/create",
+		"/tn", serviceName,
+		"/tr", fmt.Sprintf(`"%s" --config "%s"`, execPath, cfgPath),
+		"/sc", "onlogon",
+		"/rl", "highest",
+		"/f",
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create scheduled task: %s: %w", string(output), err)
+	}
+
+	fmt.Println("Service installed as Windows Scheduled Task")
+	fmt.Println("Task Name:", serviceName)
+	fmt.Println("Trigger: At logon")
+	fmt.Println("")
+	fmt.Println("Run --install as administrator for a Windows service that starts at boot.")
+	return nil
+}
+
+// Uninstall removes the Windows service, stopping it first, and the
+// scheduled task.
+func Uninstall() error {
+	removed, err := uninstallService()
+	if err != nil {
+		return err
+	}
+
+	if taskInstalled() {
+		cmd := exec.Command("schtasks", "/delete",
+			"/tn", serviceName,
+			"/f",
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete scheduled task: %s: %w", string(output), err)
+		}
+		removed = true
+	}
+
+	if !removed {
+		return errors.New("service is not installed")
+	}
+	fmt.Println("Service uninstalled")
+	return nil
+}
+
+// uninstallService stops and deletes the Windows service, and reports
+// whether there was one.
+func uninstallService() (bool, error) {
+	if !serviceInstalled() {
+		return false, nil
+	}
+
+	m, err := mgr.Connect()
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return false, fmt.Errorf("run as administrator to remove the %s service", serviceName)
+	}
+	if err != nil {
+		return false, err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return false, fmt.Errorf("failed to open service: %w", err)
+	}
+	defer s.Close()
+
+	// Stop it so the executable can be replaced or removed right away
+	if status, err := s.Control(svc.Stop); err == nil {
+		deadline := time.Now().Add(stopTimeout)
+		for status.State != svc.Stopped && time.Now().Before(deadline) {
+			time.Sleep(300 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+
+	if err := s.Delete(); err != nil {
+		return false, fmt.Errorf("failed to delete service: %w", err)
+	}
+	return true, nil
+}
+
+// Installed reports whether the Windows service or the scheduled task exists.
+func Installed() bool {
+	return serviceInstalled() || taskInstalled()
+}
+
+// ServiceStatus reports whether the Windows service or the scheduled task
+// is installed, and whether it is running.
+func ServiceStatus() (installed bool, running bool, err error) {
+	state, err := serviceState()
+	if err == nil {
+		return true, state == windows.SERVICE_RUNNING, nil
+	}
+	if !errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+		return false, false, fmt.Errorf("failed to query service: %w", err)
+	}
+
+	// Fall back to the scheduled task; /query fails if there is none
+	output, err := exec.Command("schtasks", "/query", "/tn", serviceName, "/fo", "csv", "/nh").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("failed to query scheduled task: %w", err)
+	}
+	return true, strings.Contains(string(output), `"Running"`), nil
+}
+
+// serviceState returns the current state of the Windows service, e.g.
+// windows.SERVICE_RUNNING. It only needs the access any user has, unlike
+// mgr.Connect.
+func serviceState() (uint32, error) {
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseServiceHandle(scm)
+
+	name, err := windows.UTF16PtrFromString(serviceName)
+	if err != nil {
+		return 0, err
+	}
+	h, err := windows.OpenService(scm, name, windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseServiceHandle(h)
+
+	var status windows.SERVICE_STATUS
+	if err := windows.QueryServiceStatus(h, &status); err != nil {
+		return 0, err
+	}
+	return status.CurrentState, nil
+}
+
+// serviceInstalled reports whether the Windows service exists.
+func serviceInstalled() bool {
+	_, err := serviceState()
+	return err == nil
+}
+
+// taskInstalled reports whether the scheduled task exists.
+func taskInstalled() bool {
+	return exec.Command("schtasks", "/query", "/tn", serviceName).Run() == nil
+}
+
+// Run runs serve under the Windows service control manager, as the
+// installed service does with --service. serve must return once its
+// context is cancelled; the service stops when it returns.
+func Run(serve func(ctx context.Context)) error {
+	return svc.Run(serviceName, &handler{serve: serve})
+}
+
+// handler implements svc.Handler.
+type handler struct {
+	serve func(ctx context.Context)
+}
+
+// Execute runs the service, cancelling serve's context on a stop or
+// shutdown request.
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.serve(ctx)
+	}()
+
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	changes <- svc.Status{State: svc.Running, Accepts: accepts}
+
+	for {
+		select {
+		case <-done:
+			// Stopped by itself, e.g. through /shutdown
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending, WaitHint: uint32(stopTimeout.Milliseconds())}
+				cancel()
+				<-done
+				return false, 0
+			default:
+				changes <- svc.Status{State: svc.Running, Accepts: accepts}
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxChecksumSize limits how much of a checksum file is read.
+const maxChecksumSize = 1 << 20
+
+// findChecksumAsset returns the "<asset>.sha256" asset for assetName, or
+// else a combined checksum list such as "checksums.txt" or "SHA256SUMS".
+func findChecksumAsset(assets []Asset, assetName string) Asset {
+	if assetName == "" {
+		return Asset{}
+	}
+	for _, asset := range assets {
+		if strings.EqualFold(asset.Name, assetName+".sha256") {
+			return asset
+		}
+	}
+	for _, asset := range assets {
+		name := strings.ToLower(asset.Name)
+		if strings.Contains(name, "checksums") || name == "sha256sums" || name == "sha256sums.txt" {
+			return asset
+		}
+	}
+	return Asset{}
+}
+
+// DownloadUpdate downloads the update asset with DownloadInstallerWithProgress
+// and, if the release publishes checksums, verifies its SHA-256 before
+// returning the path. A mismatching download is deleted.
+func DownloadUpdate(ctx context.Context, info *UpdateInfo, progressCh chan<- DownloadProgress) (string, error) {
+	path, err := DownloadInstallerWithProgress(ctx, info.DownloadURL, progressCh)
+	if err != nil {
+		return "", err
+	}
+
+	if info.ChecksumURL == "" {
+		log.Printf("[Update] No checksum published for %s, skipping verification", info.AssetName)
+		return path, nil
+	}
+	if err := VerifyChecksum(path, info.ChecksumURL, info.AssetName); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// VerifyChecksum checks the SHA-256 of the file at path against the entry
+// for assetName in the checksum file at checksumURL.
+func VerifyChecksum(path, checksumURL, assetName string) error {
+	expected, err := fetchChecksum(checksumURL, assetName)
+	if err != nil {
+		return err
+	}
+
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", assetName, expected, actual)
+	}
+	return nil
+}
+
+// fetchChecksum downloads a checksum file and returns the hex SHA-256 for
+// assetName. Lines are in sha256sum format ("<hash>  <name>" or
+// "<hash> *<name>"); a file holding a single bare hash applies to any name.
+func fetchChecksum(checksumURL, assetName string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(checksumURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checksum download returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxChecksumSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && len(lines) == 1 && isSHA256(fields[0]):
+			return fields[0], nil
+		case len(fields) >= 2 && isSHA256(fields[0]):
+			name := strings.TrimPrefix(fields[len(fields)-1], "*")
+			if strings.EqualFold(name, assetName) {
+				return fields[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no checksum found for %s", assetName)
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open download: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash download: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
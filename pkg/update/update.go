@@ -1,12 +1,15 @@
 package update
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"regexp"
+	"path"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +31,8 @@ type Release struct {
 	Body        string  `json:"body"`
 	PublishedAt string  `json:"published_at"`
 	HTMLURL     string  `json:"html_url"`
+	Draft       bool    `json:"draft"`
+	Prerelease  bool    `json:"prerelease"`
 	Assets      []Asset `json:"assets"`
 }
 
@@ -47,21 +52,120 @@ type UpdateInfo struct {
 	DownloadURL    string
 	ReleaseNotes   string
 	ReleaseURL     string
+	AssetName      string // Name of the matched release asset, if any
+	AssetType      string // AssetInstaller, AssetDiskImage or AssetArchive
+	ChecksumURL    string // checksums.txt or <asset>.sha256, if published
 }
 
-// CheckForUpdates checks GitHub for newer releases
+// Kinds of release assets, telling the caller how to install them.
+const (
+	AssetInstaller = "installer" // Windows setup.exe, run directly
+	AssetDiskImage = "dmg"       // macOS disk image
+	AssetArchive   = "archive"   // .tar.gz or .zip to unpack
+)
+
+// Update channels. Stable only considers full releases; beta also offers
+// pre-releases.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// CheckForUpdates checks GitHub for newer stable releases
 func CheckForUpdates(currentVersion string) (*UpdateInfo, error) {
 	return CheckForUpdatesRepo(currentVersion, DefaultOwner, DefaultRepo)
 }
 
+// CheckForUpdatesChannel checks GitHub for newer releases on channel.
+// An empty or unknown channel is treated as stable.
+func CheckForUpdatesChannel(currentVersion, channel string) (*UpdateInfo, error) {
+	return checkRepo(currentVersion, DefaultOwner, DefaultRepo, channel)
+}
+
 // CheckForUpdatesRepo checks a specific GitHub repo for newer releases
 func CheckForUpdatesRepo(currentVersion, owner, repo string) (*UpdateInfo, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", GitHubAPIURL, owner, repo)
+	return checkRepo(currentVersion, owner, repo, ChannelStable)
+}
+
+func checkRepo(currentVersion, owner, repo, channel string) (*UpdateInfo, error) {
+	var release *Release
+	var err error
+	if channel == ChannelBeta {
+		release, err = fetchNewestRelease(owner, repo)
+	} else {
+		release, err = fetchLatestRelease(owner, repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return &UpdateInfo{Available: false, CurrentVersion: currentVersion}, nil
+	}
+
+	// Extract version from tag (remove 'v' prefix if present)
+	latestVersion := strings.TrimPrefix(release.TagName, "v")
+	currentVersion = strings.TrimPrefix(currentVersion, "v")
+
+	// Find the asset for this platform
+	asset, assetType, _ := SelectAsset(release.Assets, latestVersion, runtime.GOOS, runtime.GOARCH)
+	checksum := findChecksumAsset(release.Assets, asset.Name)
+
+	// Check if update is available
+	updateAvailable := CompareVersions(currentVersion, latestVersion) < 0
+
+	return &UpdateInfo{
+		Available:      updateAvailable,
+		CurrentVersion: currentVersion,
+		LatestVersion:  latestVersion,
+		DownloadURL:    asset.BrowserDownloadURL,
+		ReleaseNotes:   release.Body,
+		ReleaseURL:     release.HTMLURL,
+		AssetName:      asset.Name,
+		AssetType:      assetType,
+		ChecksumURL:    checksum.BrowserDownloadURL,
+	}, nil
+}
+
+// fetchLatestRelease returns the latest stable release, or nil if the repo
+// has none.
+func fetchLatestRelease(owner, repo string) (*Release, error) {
+	var release Release
+	found, err := getJSON(fmt.Sprintf("%s/repos/%s/%s/releases/latest", GitHubAPIURL, owner, repo), &release)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// fetchNewestRelease returns the highest-versioned release including
+// pre-releases, or nil if the repo has none. Drafts are skipped.
+func fetchNewestRelease(owner, repo string) (*Release, error) {
+	var releases []Release
+	found, err := getJSON(fmt.Sprintf("%s/repos/%s/%s/releases?per_page=30", GitHubAPIURL, owner, repo), &releases)
+	if err != nil || !found {
+		return nil, err
+	}
 
+	var newest *Release
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft {
+			continue
+		}
+		if newest == nil || CompareVersions(r.TagName, newest.TagName) > 0 {
+			newest = r
+		}
+	}
+	return newest, nil
+}
+
+// getJSON fetches a GitHub API URL into v. It returns false without an
+// error if the resource doesn't exist.
+func getJSON(url string, v interface{}) (bool, error) {
 	client := &http.Client{Timeout: 10 * time.Second}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// GitHub API requires User-Agent header
@@ -70,71 +174,149 @@ func CheckForUpdatesRepo(currentVersion, owner, repo string) (*UpdateInfo, error
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+		return false, fmt.Errorf("failed to fetch releases: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return &UpdateInfo{Available: false, CurrentVersion: currentVersion}, nil
+		return false, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return false, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
-	var release Release
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to parse release: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse release: %w", err)
 	}
+	return true, nil
+}
 
-	// Extract version from tag (remove 'v' prefix if present)
-	latestVersion := strings.TrimPrefix(release.TagName, "v")
-	currentVersion = strings.TrimPrefix(currentVersion, "v")
+// Name fragments identifying the OS and architecture of a release asset.
+var (
+	osAliases = map[string][]string{
+		"windows": {"windows", "win64", "win32", "win"},
+		"darwin":  {"darwin", "macos", "mac", "osx"},
+		"linux":   {"linux"},
+	}
+	archAliases = map[string][]string{
+		"amd64": {"amd64", "x64"}, // x86_64 is normalized to amd64
+		"arm64": {"arm64", "aarch64"},
+		"386":   {"386", "i386", "x86"},
+	}
+)
 
-	// Find the Windows installer asset
-	var downloadURL string
-	for _, asset := range release.Assets {
-		if strings.HasSuffix(strings.ToLower(asset.Name), "-setup.exe") ||
-			strings.HasSuffix(strings.ToLower(asset.Name), "-setup-"+latestVersion+".exe") ||
-			strings.Contains(strings.ToLower(asset.Name), "setup") && strings.HasSuffix(strings.ToLower(asset.Name), ".exe") {
-			downloadURL = asset.BrowserDownloadURL
-			break
+// SelectAsset picks the release asset best suited to goos/goarch and
+// reports its type. On Windows a "setup.exe" installer is preferred, as
+// before. Other assets must name the OS and, if they name an architecture,
+// the right one; macOS "universal" builds match any architecture.
+func SelectAsset(assets []Asset, version, goos, goarch string) (Asset, string, bool) {
+	if goos == "windows" {
+		for _, asset := range assets {
+			name := strings.ToLower(asset.Name)
+			if strings.HasSuffix(name, "-setup.exe") ||
+				strings.HasSuffix(name, "-setup-"+version+".exe") ||
+				strings.Contains(name, "setup") && strings.HasSuffix(name, ".exe") {
+				return asset, AssetInstaller, true
+			}
 		}
 	}
 
-	// Check if update is available
-	updateAvailable := CompareVersions(currentVersion, latestVersion) < 0
+	var best Asset
+	var bestType string
+	bestScore := 0
+	for _, asset := range assets {
+		name := strings.ToLower(asset.Name)
 
-	return &UpdateInfo{
-		Available:      updateAvailable,
-		CurrentVersion: currentVersion,
-		LatestVersion:  latestVersion,
-		DownloadURL:    downloadURL,
-		ReleaseNotes:   release.Body,
-		ReleaseURL:     release.HTMLURL,
-	}, nil
+		typ, typeScore := assetType(name, goos)
+		if typ == "" || !hasToken(name, osAliases[goos]) {
+			continue
+		}
+
+		// Score: exact arch beats universal beats unspecified
+		score := typeScore
+		switch {
+		case hasToken(name, archAliases[goarch]):
+			score += 30
+		case goos == "darwin" && hasToken(name, []string{"universal"}):
+			score += 20
+		case mentionsArch(name):
+			continue // Built for another architecture
+		default:
+			score += 10
+		}
+
+		if score > bestScore {
+			best, bestType, bestScore = asset, typ, score
+		}
+	}
+	return best, bestType, bestScore > 0
 }
 
-// CompareVersions compares two semantic version strings
+// assetType classifies an asset by extension, with a preference score for
+// formats that suit goos better.
+func assetType(name, goos string) (string, int) {
+	switch {
+	case strings.HasSuffix(name, ".exe") && goos == "windows":
+		return AssetInstaller, 3
+	case strings.HasSuffix(name, ".dmg") && goos == "darwin":
+		return AssetDiskImage, 3
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		return AssetArchive, 2
+	case strings.HasSuffix(name, ".zip"):
+		return AssetArchive, 1
+	}
+	return "", 0
+}
+
+// hasToken reports whether name contains one of tokens as a separate word,
+// so that "win" doesn't match "darwin".
+func hasToken(name string, tokens []string) bool {
+	name = strings.ReplaceAll(name, "x86_64", "amd64")
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	for _, w := range words {
+		for _, t := range tokens {
+			if w == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mentionsArch reports whether name names any known architecture.
+func mentionsArch(name string) bool {
+	for _, aliases := range archAliases {
+		if hasToken(name, aliases) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareVersions compares two semantic version strings, ignoring a "v"
+// prefix and build metadata after "+". A pre-release ("1.0.0-rc1") sorts
+// before its release ("1.0.0").
 // Returns: -1 if v1 < v2, 0 if v1 == v2, 1 if v1 > v2
 func CompareVersions(v1, v2 string) int {
-	// Parse version parts
-	parts1 := parseVersion(v1)
-	parts2 := parseVersion(v2)
+	core1, pre1 := parseVersion(v1)
+	core2, pre2 := parseVersion(v2)
 
-	// Compare each part
-	maxLen := len(parts1)
-	if len(parts2) > maxLen {
-		maxLen = len(parts2)
+	// Compare each numeric part, treating missing parts as 0
+	maxLen := len(core1)
+	if len(core2) > maxLen {
+		maxLen = len(core2)
 	}
 
 	for i := 0; i < maxLen; i++ {
 		var p1, p2 int
-		if i < len(parts1) {
-			p1 = parts1[i]
+		if i < len(core1) {
+			p1 = core1[i]
 		}
-		if i < len(parts2) {
-			p2 = parts2[i]
+		if i < len(core2) {
+			p2 = core2[i]
 		}
 
 		if p1 < p2 {
@@ -145,25 +327,95 @@ func CompareVersions(v1, v2 string) int {
 		}
 	}
 
-	return 0
+	// A release has higher precedence than any of its pre-releases
+	switch {
+	case pre1 == "" && pre2 == "":
+		return 0
+	case pre1 == "":
+		return 1
+	case pre2 == "":
+		return -1
+	}
+	return comparePrerelease(pre1, pre2)
 }
 
-// parseVersion extracts numeric parts from a version string
-func parseVersion(v string) []int {
+// parseVersion splits a version into its numeric dot-separated parts and
+// pre-release suffix. Build metadata is dropped.
+func parseVersion(v string) ([]int, string) {
 	// Remove common prefixes
+	v = strings.TrimSpace(v)
 	v = strings.TrimPrefix(v, "v")
 	v = strings.TrimPrefix(v, "V")
 
-	// Find all numeric parts
-	re := regexp.MustCompile(`\d+`)
-	matches := re.FindAllString(v, -1)
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ := strings.Cut(v, "-")
 
-	parts := make([]int, len(matches))
-	for i, m := range matches {
-		parts[i], _ = strconv.Atoi(m)
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		parts = append(parts, leadingInt(s))
 	}
+	return parts, pre
+}
+
+// leadingInt parses the digits at the start of s, or returns 0.
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// comparePrerelease compares dot-separated pre-release identifiers as in
+// semver: numeric identifiers numerically and below alphanumeric ones,
+// others lexically, and a shorter list first when all else is equal.
+func comparePrerelease(a, b string) int {
+	ids1 := strings.Split(a, ".")
+	ids2 := strings.Split(b, ".")
+
+	for i := 0; i < len(ids1) && i < len(ids2); i++ {
+		n1, err1 := strconv.Atoi(ids1[i])
+		n2, err2 := strconv.Atoi(ids2[i])
+
+		switch {
+		case err1 == nil && err2 == nil:
+			if n1 != n2 {
+				if n1 < n2 {
+					return -1
+				}
+				return 1
+			}
+		case err1 == nil:
+			return -1
+		case err2 == nil:
+			return 1
+		default:
+			if c := strings.Compare(ids1[i], ids2[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(ids1) < len(ids2):
+		return -1
+	case len(ids1) > len(ids2):
+		return 1
+	}
+	return 0
+}
 
-	return parts
+// tempPattern names the download after the asset's extension, so that
+// dmg and archive assets aren't saved as .exe.
+func tempPattern(downloadURL string) string {
+	name := strings.ToLower(path.Base(downloadURL))
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip", ".dmg"} {
+		if strings.HasSuffix(name, ext) {
+			return "PrintBridge-Update-*" + ext
+		}
+	}
+	return "PrintBridge-Setup-*.exe"
 }
 
 // DownloadInstaller downloads the update installer to a temporary location
@@ -174,7 +426,7 @@ func DownloadInstaller(downloadURL string) (string, error) {
 
 	// Create temp file for installer
 	tempDir := os.TempDir()
-	tempFile, err := os.CreateTemp(tempDir, "PrintBridge-Setup-*.exe")
+	tempFile, err := os.CreateTemp(tempDir, tempPattern(downloadURL))
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -211,36 +463,66 @@ type DownloadProgress struct {
 	Percent         float64
 }
 
-// DownloadInstallerWithProgress downloads with progress reporting
-func DownloadInstallerWithProgress(downloadURL string, progressCh chan<- DownloadProgress) (string, error) {
+// DownloadInstallerWithProgress downloads with progress reporting. The
+// download can be aborted through ctx, which removes the partial file. If
+// an earlier attempt was interrupted by a network error, the download
+// resumes from where it stopped using an HTTP Range request. progressCh
+// may be nil and is never closed; it belongs to the caller.
+func DownloadInstallerWithProgress(ctx context.Context, downloadURL string, progressCh chan<- DownloadProgress) (string, error) {
 	if downloadURL == "" {
 		return "", fmt.Errorf("no download URL provided")
 	}
 
-	// Create temp file for installer
-	tempDir := os.TempDir()
-	tempFile, err := os.CreateTemp(tempDir, "PrintBridge-Setup-*.exe")
+	// Use a fixed name per asset so an interrupted download can resume
+	finalPath := filepath.Join(os.TempDir(), downloadName(downloadURL))
+	partPath := finalPath + ".part"
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
-	defer tempFile.Close()
 
-	// Download the file
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Get(downloadURL)
+	client := &http.Client{}
+	resp, err := client.Do(req)
 	if err != nil {
-		os.Remove(tempFile.Name())
-		return "", fmt.Errorf("failed to download: %w", err)
+		return "", downloadFailed(ctx, partPath, fmt.Errorf("failed to download: %w", err))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		os.Remove(tempFile.Name())
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// Server ignored the range, start over
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// Stale partial file, e.g. from a different release
+		os.Remove(partPath)
+		return "", fmt.Errorf("download returned status %d, please retry", resp.StatusCode)
+	default:
 		return "", fmt.Errorf("download returned status %d", resp.StatusCode)
 	}
 
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
 	totalSize := resp.ContentLength
-	var downloaded int64
+	if totalSize > 0 {
+		totalSize += offset
+	}
+	downloaded := offset
 
 	// Create a buffer for reading
 	buf := make([]byte, 32*1024) // 32KB buffer
@@ -248,19 +530,22 @@ func DownloadInstallerWithProgress(downloadURL string, progressCh chan<- Downloa
 	for {
 		n, err := resp.Body.Read(buf)
 		if n > 0 {
-			_, writeErr := tempFile.Write(buf[:n])
-			if writeErr != nil {
-				os.Remove(tempFile.Name())
+			if _, writeErr := file.Write(buf[:n]); writeErr != nil {
+				file.Close()
+				os.Remove(partPath)
 				return "", fmt.Errorf("failed to write: %w", writeErr)
 			}
 			downloaded += int64(n)
 
 			// Report progress
 			if progressCh != nil && totalSize > 0 {
-				progressCh <- DownloadProgress{
+				select {
+				case progressCh <- DownloadProgress{
 					TotalBytes:      totalSize,
 					DownloadedBytes: downloaded,
 					Percent:         float64(downloaded) / float64(totalSize) * 100,
+				}:
+				case <-ctx.Done():
 				}
 			}
 		}
@@ -268,14 +553,38 @@ func DownloadInstallerWithProgress(downloadURL string, progressCh chan<- Downloa
 			break
 		}
 		if err != nil {
-			os.Remove(tempFile.Name())
-			return "", fmt.Errorf("failed to read: %w", err)
+			file.Close()
+			return "", downloadFailed(ctx, partPath, fmt.Errorf("failed to read: %w", err))
 		}
 	}
 
-	if progressCh != nil {
-		close(progressCh)
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to save installer: %w", err)
+	}
+	if err := os.Rename(partPath, finalPath); err != nil {
+		return "", fmt.Errorf("failed to save installer: %w", err)
+	}
+	return finalPath, nil
+}
+
+// downloadFailed removes the partial file if the download was cancelled,
+// and keeps it for resuming otherwise.
+func downloadFailed(ctx context.Context, partPath string, err error) error {
+	if ctx.Err() != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("download cancelled: %w", ctx.Err())
 	}
+	return err
+}
 
-	return tempFile.Name(), nil
+// downloadName returns a local file name for the asset at downloadURL.
+func downloadName(downloadURL string) string {
+	name := path.Base(downloadURL)
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" || name == "." || name == "/" {
+		return strings.Replace(tempPattern(downloadURL), "*", "download", 1)
+	}
+	return "PrintBridge-Update-" + name
 }
@@ -0,0 +1,29 @@
+package update
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		{"1.0.9", "1.0.10", -1},
+		{"1.0.10", "1.0.9", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc1", 1},
+		{"v2.0", "1.9.9", 1},
+		{"1.0.8-beta", "1.0.8", -1},
+		{"1.0.0", "v1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.0.0+build.5", "1.0.0", 0},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
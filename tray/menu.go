@@ -0,0 +1,44 @@
+package tray
+
+import (
+	"sync"
+
+	"fyne.io/systray"
+)
+
+// ScanItems holds the submenu entries listed by a scan, such as found USB
+// devices. Each entry has exactly one click handler goroutine, which exits
+// when Clear removes the entry, so rescanning doesn't accumulate handlers.
+type ScanItems struct {
+	items    []*systray.MenuItem
+	handlers sync.WaitGroup
+}
+
+// Add appends an entry to parent that calls onClick each time it is clicked.
+func (s *ScanItems) Add(parent *systray.MenuItem, title, tooltip string, onClick func()) *systray.MenuItem {
+	item := parent.AddSubMenuItem(title, tooltip)
+	s.items = append(s.items, item)
+
+	s.handlers.Add(1)
+	go func() {
+		defer s.handlers.Done()
+		for range item.ClickedCh { // Closed by Remove
+			onClick()
+		}
+	}()
+	return item
+}
+
+// Clear removes the entries and waits for their click handlers to return.
+func (s *ScanItems) Clear() {
+	for _, item := range s.items {
+		item.Remove()
+	}
+	s.items = nil
+	s.handlers.Wait()
+}
+
+// Len returns the number of entries.
+func (s *ScanItems) Len() int {
+	return len(s.items)
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package tray
+
+import "errors"
+
+// ShowToast is only implemented on Windows; elsewhere use the platform's
+// notification command.
+func ShowToast(title, message string) error {
+	return errors.New("toast notifications are only supported on Windows")
+}
@@ -0,0 +1,48 @@
+package tray
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// toastAppID is the AppUserModelID toasts are shown under. Windows only
+// displays toasts for registered apps, so PowerShell's own ID is used.
+const toastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// toastScript shows a WinRT toast. Title and message are passed through
+// the environment so they need no quoting on the command line.
+const toastScript = `
+$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null
+$title = [Security.SecurityElement]::Escape($env:PRINTBRIDGE_TOAST_TITLE)
+$message = [Security.SecurityElement]::Escape($env:PRINTBRIDGE_TOAST_MESSAGE)
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml("<toast><visual><binding template='ToastGeneric'><text>$title</text><text>$message</text></binding></visual></toast>")
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:PRINTBRIDGE_TOAST_APPID).Show($toast)
+`
+
+// createNoWindow keeps PowerShell from flashing a console window.
+const createNoWindow = 0x08000000
+
+// ShowToast shows a Windows toast notification. It blocks until PowerShell
+// has handed the toast to Windows, so call it from a goroutine. An error
+// means toasts are unavailable (e.g. older Windows or PowerShell disabled)
+// and the caller should fall back to another kind of notification.
+func ShowToast(title, message string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", toastScript)
+	cmd.Env = append(os.Environ(),
+		"PRINTBRIDGE_TOAST_TITLE="+title,
+		"PRINTBRIDGE_TOAST_MESSAGE="+message,
+		"PRINTBRIDGE_TOAST_APPID="+toastAppID,
+	)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("toast failed: %v: %s", err, out)
+	}
+	return nil
+}
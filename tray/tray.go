@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"fyne.io/systray"
+	"printbridge/pkg/config"
 )
 
 // PrinterInfo contains USB printer details (matches adapter.PrinterInfo).
@@ -29,6 +30,7 @@ type App struct {
 	configPath     string
 	serviceURL     string
 	mStatus        *systray.MenuItem
+	deviceItems    ScanItems // Entries added by the last device scan
 	currentVID     uint16
 	currentPID     uint16
 }
@@ -36,7 +38,7 @@ type App struct {
 // New creates a new tray application.
 func New() *App {
 	return &App{
-		serviceURL: "http://localhost:9100",
+		serviceURL: config.ServiceURL(),
 	}
 }
 
@@ -60,7 +62,8 @@ func (a *App) SetConfigPath(path string) {
 	a.configPath = path
 }
 
-// SetServiceURL sets the base URL for the HTTP service.
+// SetServiceURL sets the base URL for the HTTP service. It defaults to the
+// host and port in the config.
 func (a *App) SetServiceURL(url string) {
 	a.serviceURL = url
 }
@@ -162,6 +165,9 @@ func (a *App) scanAndShowDevices(parent *systray.MenuItem) {
 		return
 	}
 
+	// Replace the entries from the previous scan
+	a.deviceItems.Clear()
+
 	if len(printers) == 0 {
 		showNotification("PrintBridge", "No USB printers found")
 		return
@@ -169,7 +175,14 @@ func (a *App) scanAndShowDevices(parent *systray.MenuItem) {
 
 	// Show notification with found devices
 	var msg string
-	for i, p := range printers {
+	seen := make(map[[2]uint16]bool)
+	for _, p := range printers {
+		id := [2]uint16{p.VendorID, p.ProductID}
+		if seen[id] {
+			continue // Devices are selected by VID/PID, so list each pair once
+		}
+		seen[id] = true
+
 		name := p.Product
 		if name == "" {
 			name = fmt.Sprintf("Device %04X:%04X", p.VendorID, p.ProductID)
@@ -183,18 +196,13 @@ func (a *App) scanAndShowDevices(parent *systray.MenuItem) {
 			name = "✓ " + name
 		}
 
-		msg += fmt.Sprintf("%d. %s\n", i+1, name)
+		msg += fmt.Sprintf("%d. %s\n", len(seen), name)
 
 		// Add submenu item for each printer
-		item := parent.AddSubMenuItem(name, fmt.Sprintf("Select %s", name))
-
-		// Capture values for closure
 		vid, pid := p.VendorID, p.ProductID
-		go func() {
-			for range item.ClickedCh {
-				a.selectDevice(vid, pid)
-			}
-		}()
+		a.deviceItems.Add(parent, name, fmt.Sprintf("Select %s", name), func() {
+			a.selectDevice(vid, pid)
+		})
 	}
 
 	showNotification("PrintBridge - USB Devices Found", msg)
@@ -254,7 +262,7 @@ func (a *App) refreshStatus() {
 
 // checkHealth calls the /health endpoint
 func (a *App) checkHealth() bool {
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := config.NewClient(2 * time.Second)
 	resp, err := client.Get(a.serviceURL + "/health")
 	if err != nil {
 		return false
@@ -272,7 +280,7 @@ type StatusResponse struct {
 
 // checkPrinterStatus calls the /status endpoint
 func (a *App) checkPrinterStatus() StatusResponse {
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := config.NewClient(2 * time.Second)
 	resp, err := client.Get(a.serviceURL + "/status")
 	if err != nil {
 		return StatusResponse{}
@@ -335,8 +343,12 @@ func showNotification(title, message string) {
 	case "linux":
 		exec.Command("notify-send", title, message).Run()
 	case "windows":
-		// Windows toast notifications require more setup, fallback to console
-		fmt.Printf("[%s] %s\n", title, message)
+		// Toasts take a moment to show, don't block the menu
+		go func() {
+			if err := ShowToast(title, message); err != nil {
+				fmt.Printf("[%s] %s\n", title, message)
+			}
+		}()
 	default:
 		fmt.Printf("[%s] %s\n", title, message)
 	}